@@ -0,0 +1,73 @@
+package binance
+
+type restData struct {
+	Symbol             string `json:"symbol"`
+	PriceChange        string `json:"priceChange"`
+	PriceChangePercent string `json:"priceChangePercent"`
+	WeightedAvgPrice   string `json:"weightedAvgPrice"`
+	PrevClosePrice     string `json:"prevClosePrice"`
+	LastPrice          string `json:"lastPrice"`
+	LastQty            string `json:"lastQty"`
+	BidPrice           string `json:"bidPrice"`
+	BidQty             string `json:"bidQty"`
+	AskPrice           string `json:"askPrice"`
+	AskQty             string `json:"askQty"`
+	OpenPrice          string `json:"openPrice"`
+	HighPrice          string `json:"highPrice"`
+	LowPrice           string `json:"lowPrice"`
+	Volume             string `json:"volume"`
+	QuoteVolume        string `json:"quoteVolume"`
+	OpenTime           int64  `json:"openTime"`
+	CloseTime          int64  `json:"closeTime"`
+	FirstId            int64  `json:"firstId"`
+	LastId             int64  `json:"lastId"`
+	Count              int64  `json:"count"`
+}
+
+type restError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+type wsMessage struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+	Id     int64    `json:"id"`
+}
+
+type wsResponse struct {
+	Result any   `json:"result"`
+	Id     int64 `json:"id"`
+	Error  *struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"error,omitempty"`
+}
+
+// wsTickerInfo is the 24hr ticker stream event, all one-letter keys are declared because the json decoder matches
+// keys case-insensitively and e.g. "P" would overwrite "p" otherwise
+type wsTickerInfo struct {
+	EventType          string `json:"e"`
+	EventTime          int64  `json:"E"`
+	Symbol             string `json:"s"`
+	PriceChange        string `json:"p"`
+	PriceChangePercent string `json:"P"`
+	WeightedAvgPrice   string `json:"w"`
+	FirstTradePrice    string `json:"x"`
+	LastPrice          string `json:"c"`
+	LastQty            string `json:"Q"`
+	BidPrice           string `json:"b"`
+	BidQty             string `json:"B"`
+	AskPrice           string `json:"a"`
+	AskQty             string `json:"A"`
+	OpenPrice          string `json:"o"`
+	HighPrice          string `json:"h"`
+	LowPrice           string `json:"l"`
+	Volume             string `json:"v"`
+	QuoteVolume        string `json:"q"`
+	OpenTime           int64  `json:"O"`
+	CloseTime          int64  `json:"C"`
+	FirstId            int64  `json:"F"`
+	LastId             int64  `json:"L"`
+	Count              int64  `json:"n"`
+}
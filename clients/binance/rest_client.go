@@ -0,0 +1,262 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/streamdp/ccd/clients"
+	"github.com/streamdp/ccd/config"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
+)
+
+const (
+	Name = "binance"
+
+	apiUrl = "https://api.binance.com"
+
+	// 24hr Ticker Price Change Statistics https://developers.binance.com/docs/binance-spot-api-docs/rest-api/market-data-endpoints#24hr-ticker-price-change-statistics
+	// 24 hour rolling window price change statistics.
+	// Request Parameters "symbol" (trading symbol in upper case, e.g. BTCUSDT. Refer to /api/v3/exchangeInfo)
+	tickerStatistics = "/api/v3/ticker/24hr"
+
+	rateLimit = 2 // make max two api calls per second
+)
+
+type rest struct {
+	apiUrl  string
+	client  *http.Client
+	limiter *ratelimit.Limiter
+}
+
+var (
+	errWrongStatusCode = errors.New("wrong response status code")
+	errEmptyData       = errors.New("empty data")
+	errRejected        = errors.New("request rejected")
+)
+
+func Init(cfg *config.App) (*rest, error) {
+	return &rest{
+		apiUrl:  apiUrl,
+		client:  clients.NewHttpClient(cfg.Http.ClientTimeout()),
+		limiter: ratelimit.New(cfg.Limits.Rate(Name, rateLimit), cfg.Limits.Budget(Name)),
+	}, nil
+}
+
+func (r *rest) Get(ctx context.Context, fSym string, tSym string) (*domain.Data, error) {
+	u, err := r.buildURL(fSym, tSym)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build url: %w", err)
+	}
+
+	rawData := &restData{}
+	if err = r.fetch(ctx, u, rawData); err != nil {
+		return nil, err
+	}
+
+	return convertRestDataToDomain(fSym, tSym, rawData)
+}
+
+// GetMany data for several currencies pairs with the single request, the "symbols" parameter takes the json array.
+// Binance rejects the whole request when any of the symbols is invalid, so the pairs are fetched one by one then
+func (r *rest) GetMany(ctx context.Context, pairs []domain.Pair) ([]*domain.Data, error) {
+	symbols := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		if s := buildSymbol(p.From, p.To); !slices.Contains(symbols, s) {
+			symbols = append(symbols, s)
+		}
+	}
+
+	u, err := r.buildSymbolsURL(symbols)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build url: %w", err)
+	}
+
+	var rawData []*restData
+	if err = r.fetch(ctx, u, &rawData); errors.Is(err, errRejected) {
+		return clients.GetEach(ctx, r, pairs)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := make(map[string]*restData, len(rawData))
+	for _, t := range rawData {
+		tickers[t.Symbol] = t
+	}
+
+	result := make([]*domain.Data, 0, len(pairs))
+
+	for _, p := range pairs {
+		data, errConvert := convertRestDataToDomain(p.From, p.To, tickers[buildSymbol(p.From, p.To)])
+		if errConvert != nil {
+			continue
+		}
+
+		result = append(result, data)
+	}
+
+	return result, nil
+}
+
+func (r *rest) fetch(ctx context.Context, u *url.URL, v any) error {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	var (
+		request  *http.Request
+		response *http.Response
+		body     []byte
+		err      error
+	)
+
+	if request, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil); err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	if response, err = r.client.Do(request); err != nil {
+		return fmt.Errorf("failed to fetch data: %w", err)
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+
+	if body, err = io.ReadAll(response.Body); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		statusErr := errWrongStatusCode
+		if response.StatusCode == http.StatusBadRequest {
+			statusErr = fmt.Errorf("%w: %w", errWrongStatusCode, errRejected)
+		}
+
+		restErr := &restError{}
+		if err = json.Unmarshal(body, restErr); err != nil || restErr.Msg == "" {
+			return statusErr
+		}
+
+		return fmt.Errorf("%w: server error: %s", statusErr, restErr.Msg)
+	}
+
+	if err = json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+func (r *rest) Close() error {
+	return nil
+}
+
+// Budget return the state of the monthly budget of the upstream calls
+func (r *rest) Budget() ratelimit.Budget {
+	return r.limiter.Budget()
+}
+
+// RestoreBudget used before the restart
+func (r *rest) RestoreBudget(used int64, resetAt time.Time) {
+	r.limiter.Restore(used, resetAt)
+}
+
+func (r *rest) buildURL(fSym string, tSym string) (*url.URL, error) {
+	u, err := url.Parse(r.apiUrl + tickerStatistics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url: %w", err)
+	}
+
+	query := u.Query()
+	query.Set("symbol", buildSymbol(fSym, tSym))
+	u.RawQuery = query.Encode()
+
+	return u, nil
+}
+
+// buildSymbolsURL of the 24hr ticker statistics for several trading pairs
+func (r *rest) buildSymbolsURL(symbols []string) (*url.URL, error) {
+	u, err := url.Parse(r.apiUrl + tickerStatistics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url: %w", err)
+	}
+
+	b, err := json.Marshal(symbols)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal symbols: %w", err)
+	}
+
+	query := u.Query()
+	query.Set("symbols", string(b))
+	u.RawQuery = query.Encode()
+
+	return u, nil
+}
+
+// buildSymbol of the binance trading pair, binance has no USD pairs, so USD is replaced with USDT and the USDT price is
+// reported for the requested USD pair
+func buildSymbol(fSym string, tSym string) string {
+	if strings.ToLower(tSym) == "usd" {
+		tSym = "usdt"
+	}
+
+	return strings.ToUpper(fSym + tSym)
+}
+
+func convertRestDataToDomain(from, to string, d *restData) (*domain.Data, error) {
+	if d == nil || d.LastPrice == "" {
+		return nil, errEmptyData
+	}
+
+	change24Hour, _ := strconv.ParseFloat(d.PriceChange, 64)
+	changePct24Hour, _ := strconv.ParseFloat(d.PriceChangePercent, 64)
+	open24Hour, _ := strconv.ParseFloat(d.OpenPrice, 64)
+	volume24Hour, _ := strconv.ParseFloat(d.Volume, 64)
+	volume24HourTo, _ := strconv.ParseFloat(d.QuoteVolume, 64)
+	low24Hour, _ := strconv.ParseFloat(d.LowPrice, 64)
+	high24Hour, _ := strconv.ParseFloat(d.HighPrice, 64)
+	price, _ := strconv.ParseFloat(d.LastPrice, 64)
+
+	b, err := json.Marshal(&domain.Raw{
+		FromSymbol:      from,
+		ToSymbol:        to,
+		Change24Hour:    change24Hour,
+		ChangePct24Hour: changePct24Hour,
+		Open24Hour:      open24Hour,
+		Volume24Hour:    volume24Hour,
+		Volume24HourTo:  volume24HourTo,
+		Low24Hour:       low24Hour,
+		High24Hour:      high24Hour,
+		Price:           price,
+		LastUpdate:      d.CloseTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal raw data: %w", err)
+	}
+
+	return &domain.Data{
+		FromSymbol:      from,
+		ToSymbol:        to,
+		Provider:        Name,
+		Change24Hour:    change24Hour,
+		ChangePct24Hour: changePct24Hour,
+		Open24Hour:      open24Hour,
+		Volume24Hour:    volume24Hour,
+		Low24Hour:       low24Hour,
+		High24Hour:      high24Hour,
+		Price:           price,
+		LastUpdate:      d.CloseTime,
+		DisplayDataRaw:  string(b),
+	}, nil
+}
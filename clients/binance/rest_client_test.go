@@ -0,0 +1,344 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
+)
+
+const tickerResponse = `{"symbol":"BTCUSDT","priceChange":"-1292.80000000","priceChangePercent":"-1.354",` +
+	`"weightedAvgPrice":"94904.60000000","prevClosePrice":"95476.10000000","lastPrice":"94183.30000000",` +
+	`"lastQty":"0.00100000","bidPrice":"94183.20000000","bidQty":"17.65613222","askPrice":"94183.30000000",` +
+	`"askQty":"0.03386559","openPrice":"95476.10000000","highPrice":"95761.30000000","lowPrice":"93570.10000000",` +
+	`"volume":"891.98979302","quoteVolume":"84653283.12000000","openTime":1746352819990,"closeTime":1746439219990,` +
+	`"firstId":4861729117,"lastId":4862854812,"count":1125696}`
+
+var wantTicker = &domain.Data{
+	FromSymbol:      "BTC",
+	ToSymbol:        "USDT",
+	Provider:        Name,
+	Change24Hour:    -1292.8,
+	ChangePct24Hour: -1.354,
+	Open24Hour:      95476.1,
+	Volume24Hour:    891.98979302,
+	Low24Hour:       93570.1,
+	High24Hour:      95761.3,
+	Price:           94183.3,
+	LastUpdate:      1746439219990,
+	DisplayDataRaw: "{\"from_symbol\":\"BTC\",\"to_symbol\":\"USDT\",\"change_24_hour\":-1292.8," +
+		"\"changepct_24_hour\":-1.354,\"open_24_hour\":95476.1,\"volume_24_hour\":891.98979302," +
+		"\"volume_24_hour_to\":84653283.12,\"low_24_hour\":93570.1,\"high_24_hour\":95761.3,\"price\":94183.3," +
+		"\"supply\":0,\"mkt_cap\":0,\"last_update\":1746439219990}",
+}
+
+func Test_convertRestDataToDomain(t *testing.T) {
+	type args struct {
+		from string
+		to   string
+		d    *restData
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want *domain.Data
+	}{
+		{
+			name: "nil",
+			args: args{
+				from: "BTC",
+				to:   "USDT",
+				d:    nil,
+			},
+			want: nil,
+		},
+		{
+			name: "empty",
+			args: args{
+				from: "BTC",
+				to:   "USDT",
+				d:    &restData{},
+			},
+			want: nil,
+		},
+		{
+			name: "regular conversion",
+			args: args{
+				from: "BTC",
+				to:   "USDT",
+				d: &restData{
+					Symbol:             "BTCUSDT",
+					PriceChange:        "-1292.80000000",
+					PriceChangePercent: "-1.354",
+					LastPrice:          "94183.30000000",
+					OpenPrice:          "95476.10000000",
+					HighPrice:          "95761.30000000",
+					LowPrice:           "93570.10000000",
+					Volume:             "891.98979302",
+					QuoteVolume:        "84653283.12000000",
+					CloseTime:          1746439219990,
+					Count:              1125696,
+				},
+			},
+			want: wantTicker,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := convertRestDataToDomain(tt.args.from, tt.args.to, tt.args.d)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convertRestDataToDomain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_rest_buildURL(t *testing.T) {
+	type args struct {
+		fSym string
+		tSym string
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		wantU   *url.URL
+		wantErr bool
+	}{
+		{
+			name: "build url",
+			args: args{
+				fSym: "btc",
+				tSym: "usdt",
+			},
+			wantU: func() *url.URL {
+				u, _ := url.Parse("https://api.binance.com/api/v3/ticker/24hr?symbol=BTCUSDT")
+
+				return u
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "usd case",
+			args: args{
+				fSym: "ETH",
+				tSym: "USD",
+			},
+			wantU: func() *url.URL {
+				u, _ := url.Parse("https://api.binance.com/api/v3/ticker/24hr?symbol=ETHUSDT")
+
+				return u
+			}(),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rest{apiUrl: apiUrl}
+
+			gotU, err := r.buildURL(tt.args.fSym, tt.args.tSym)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("buildURL() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+
+			if !reflect.DeepEqual(gotU, tt.wantU) {
+				t.Errorf("buildURL() gotU = %v, want %v", gotU, tt.wantU)
+			}
+		})
+	}
+}
+
+func Test_rest_Get(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != tickerStatistics {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		if r.URL.Query().Get("symbol") != "BTCUSDT" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(tickerResponse))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		want    *domain.Data
+		wantErr error
+	}{
+		{
+			name: "regular response",
+			from: "BTC",
+			to:   "USDT",
+			want: wantTicker,
+		},
+		{
+			name:    "invalid symbol",
+			from:    "BTC",
+			to:      "XYZ",
+			wantErr: errWrongStatusCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rest{
+				apiUrl:  srv.URL,
+				client:  srv.Client(),
+				limiter: ratelimit.New(0, 0),
+			}
+			defer func() { _ = r.Close() }()
+
+			got, err := r.Get(context.Background(), tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_rest_GetDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	r := &rest{
+		apiUrl:  srv.URL,
+		client:  srv.Client(),
+		limiter: ratelimit.New(0, 0),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := r.Get(ctx, "BTC", "USDT"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() error = %v, wantErr %v", err, context.DeadlineExceeded)
+	}
+}
+
+func Test_rest_GetMany(t *testing.T) {
+	tickers := map[string]string{
+		"BTCUSDT": tickerResponse,
+		"ETHUSDT": `{"symbol":"ETHUSDT","lastPrice":"1800.50000000","closeTime":1746439219990}`,
+	}
+
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		symbols := []string{r.URL.Query().Get("symbol")}
+		if s := r.URL.Query().Get("symbols"); s != "" {
+			_ = json.Unmarshal([]byte(s), &symbols)
+		}
+
+		res := make([]string, 0, len(symbols))
+		for _, s := range symbols {
+			ticker, ok := tickers[s]
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+
+				return
+			}
+
+			res = append(res, ticker)
+		}
+
+		if r.URL.Query().Has("symbols") {
+			_, _ = w.Write([]byte("[" + strings.Join(res, ",") + "]"))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(res[0]))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		pairs     []domain.Pair
+		want      []string
+		wantCalls int32
+		wantErr   error
+	}{
+		{
+			name:      "single request",
+			pairs:     []domain.Pair{{From: "BTC", To: "USDT"}, {From: "ETH", To: "USD"}},
+			want:      []string{"BTC/USDT", "ETH/USD"},
+			wantCalls: 1,
+		},
+		{
+			name:      "invalid symbol is fetched one by one",
+			pairs:     []domain.Pair{{From: "BTC", To: "USDT"}, {From: "BTC", To: "XYZ"}, {From: "ETH", To: "USDT"}},
+			want:      []string{"BTC/USDT", "ETH/USDT"},
+			wantCalls: 4,
+		},
+		{
+			name:      "all symbols are invalid",
+			pairs:     []domain.Pair{{From: "BTC", To: "XYZ"}, {From: "XYZ", To: "USDT"}},
+			wantCalls: 3,
+			wantErr:   errRejected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+
+			r := &rest{
+				apiUrl:  srv.URL,
+				client:  srv.Client(),
+				limiter: ratelimit.New(0, 0),
+			}
+			defer func() { _ = r.Close() }()
+
+			data, err := r.GetMany(context.Background(), tt.pairs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetMany() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+
+			var got []string
+			for _, d := range data {
+				got = append(got, d.FromSymbol+"/"+d.ToSymbol)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMany() got = %v, want %v", got, tt.want)
+			}
+
+			if n := calls.Load(); n != tt.wantCalls {
+				t.Errorf("GetMany() calls = %d, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
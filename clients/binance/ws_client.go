@@ -0,0 +1,165 @@
+package binance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/streamdp/ccd/clients"
+	"github.com/streamdp/ccd/config"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/wsclient"
+)
+
+const tickerStream = "@ticker"
+
+func InitWs(
+	ctx context.Context,
+	sessionRepo clients.SessionRepo,
+	l *log.Logger,
+	cfg *config.Http,
+	pipe ...chan *domain.Data,
+) *wsclient.Ws {
+	// binance sends ping frames every 20 seconds, the websocket library answers them with pong frames automatically
+	w := wsclient.New(ctx, Name, "wss://stream.binance.com:9443/ws", sessionRepo, l, cfg)
+
+	w.ChannelNameBuilder = buildChannelName
+
+	w.SubscribeMessageBuilder = func(ch string, id int64) ([]byte, error) {
+		return buildWsMessage("SUBSCRIBE", ch, id)
+	}
+
+	w.UnsubscribeMessageBuilder = func(ch string, id int64) ([]byte, error) {
+		return buildWsMessage("UNSUBSCRIBE", ch, id)
+	}
+
+	w.MessageHandler = func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				body, err := w.Read(ctx)
+				if err != nil {
+					if !errors.Is(err, context.Canceled) {
+						l.Println(err)
+					}
+
+					if errors.Is(err, context.Canceled) || errors.Is(err, wsclient.ErrClientReconnected) {
+						continue
+					}
+
+					if err = w.WsDown(); err != nil {
+						l.Println(err)
+					}
+
+					return
+				}
+
+				if bytes.Contains(body, []byte("\"id\"")) {
+					if msg := handleServerResponse(body); msg != "" {
+						l.Println(msg)
+					}
+
+					continue
+				}
+
+				if err = handleWsUpdate(w, body, pipe); err != nil {
+					l.Println(err)
+				}
+			}
+		}
+	}
+
+	return w
+}
+
+func buildChannelName(from, to string) string {
+	return strings.ToLower(buildSymbol(from, to)) + tickerStream
+}
+
+func buildWsMessage(method string, ch string, id int64) ([]byte, error) {
+	msg, err := json.Marshal(wsMessage{
+		Method: method,
+		Params: []string{ch},
+		Id:     id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	return msg, nil
+}
+
+func handleServerResponse(body []byte) string {
+	msg := &wsResponse{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return "failed to unmarshal server response: " + err.Error()
+	}
+
+	if msg.Error != nil {
+		return fmt.Sprintf("request %d failed: %s", msg.Id, msg.Error.Msg)
+	}
+
+	return ""
+}
+
+func handleWsUpdate(w *wsclient.Ws, body []byte, pipe []chan *domain.Data) error {
+	tick := &wsTickerInfo{}
+	if err := json.Unmarshal(body, tick); err != nil {
+		return fmt.Errorf("failed to unmarshal ws update message: %w", err)
+	}
+
+	if tick.EventType != "24hrTicker" {
+		return nil
+	}
+
+	from, to := w.PairFromChannelName(strings.ToLower(tick.Symbol) + tickerStream)
+	if from == "" || to == "" {
+		return nil
+	}
+
+	data, err := convertWsDataToDomain(from, to, tick)
+	if err != nil {
+		return fmt.Errorf("failed to convert ws update message: %w", err)
+	}
+
+	for i := range pipe {
+		pipe[i] <- data
+	}
+
+	return nil
+}
+
+func convertWsDataToDomain(from, to string, tick *wsTickerInfo) (*domain.Data, error) {
+	if tick == nil {
+		return nil, errEmptyData
+	}
+
+	return convertRestDataToDomain(from, to, &restData{
+		Symbol:             tick.Symbol,
+		PriceChange:        tick.PriceChange,
+		PriceChangePercent: tick.PriceChangePercent,
+		WeightedAvgPrice:   tick.WeightedAvgPrice,
+		LastPrice:          tick.LastPrice,
+		LastQty:            tick.LastQty,
+		BidPrice:           tick.BidPrice,
+		BidQty:             tick.BidQty,
+		AskPrice:           tick.AskPrice,
+		AskQty:             tick.AskQty,
+		OpenPrice:          tick.OpenPrice,
+		HighPrice:          tick.HighPrice,
+		LowPrice:           tick.LowPrice,
+		Volume:             tick.Volume,
+		QuoteVolume:        tick.QuoteVolume,
+		OpenTime:           tick.OpenTime,
+		CloseTime:          tick.EventTime,
+		FirstId:            tick.FirstId,
+		LastId:             tick.LastId,
+		Count:              tick.Count,
+	})
+}
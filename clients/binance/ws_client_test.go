@@ -0,0 +1,86 @@
+package binance
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+func Test_buildChannelName(t *testing.T) {
+	type args struct {
+		from string
+		to   string
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "build channel name",
+			args: args{
+				from: "ETH",
+				to:   "BTC",
+			},
+			want: "ethbtc@ticker",
+		},
+		{
+			name: "usd case",
+			args: args{
+				from: "btc",
+				to:   "usd",
+			},
+			want: "btcusdt@ticker",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildChannelName(tt.args.from, tt.args.to); got != tt.want {
+				t.Errorf("buildChannelName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_convertWsDataToDomain(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		message string
+		want    *domain.Data
+	}{
+		{
+			name: "regular conversion",
+			from: "BTC",
+			to:   "USDT",
+			message: `{"e":"24hrTicker","E":1746439219990,"s":"BTCUSDT","p":"-1292.80000000","P":"-1.354",` +
+				`"w":"94904.60000000","x":"95476.00000000","c":"94183.30000000","Q":"0.00100000",` +
+				`"b":"94183.20000000","B":"17.65613222","a":"94183.30000000","A":"0.03386559",` +
+				`"o":"95476.10000000","h":"95761.30000000","l":"93570.10000000","v":"891.98979302",` +
+				`"q":"84653283.12000000","O":1746352819990,"C":1746439219989,"F":4861729117,"L":4862854812,` +
+				`"n":1125696}`,
+			want: wantTicker,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tick := &wsTickerInfo{}
+			if err := json.Unmarshal([]byte(tt.message), tick); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := convertWsDataToDomain(tt.from, tt.to, tick)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convertWsDataToDomain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
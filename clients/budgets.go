@@ -0,0 +1,79 @@
+package clients
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+// BudgetSaveInterval the used budgets are saved to the store with
+const BudgetSaveInterval = time.Minute
+
+// BudgetStore keeps the used monthly budgets of the rest providers, so the restart doesn't grant the spent budget again
+type BudgetStore interface {
+	Budgets(ctx context.Context) (budgets []*domain.Budget, err error)
+	SaveBudget(ctx context.Context, b *domain.Budget) (result sql.Result, err error)
+}
+
+// RestoreBudgets used by the rest clients before the restart from the store, the budgets saved for the past months
+// are ignored
+func (p *Providers) RestoreBudgets(ctx context.Context, s BudgetStore) error {
+	budgets, err := s.Budgets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load budgets: %w", err)
+	}
+
+	for _, b := range budgets {
+		r, found := p.rest[b.Provider]
+		if !found {
+			continue
+		}
+
+		if reporter, ok := budgetReporter(r); ok {
+			reporter.RestoreBudget(b.Used, time.Unix(b.ResetAt, 0))
+		}
+	}
+
+	return nil
+}
+
+// SaveBudgets used by the rest clients limiting the upstream calls with the monthly budget to the store
+func (p *Providers) SaveBudgets(ctx context.Context, s BudgetStore) error {
+	var errs []error
+
+	for name, r := range p.rest {
+		b, ok := budget(r)
+		if !ok || b.Limit == 0 {
+			continue
+		}
+
+		if _, err := s.SaveBudget(ctx, &domain.Budget{Provider: name, Used: b.Used, ResetAt: b.ResetAt.Unix()}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// KeepBudgets save the used budgets to the store every interval until the context is done
+func (p *Providers) KeepBudgets(ctx context.Context, s BudgetStore, interval time.Duration) {
+	t := time.NewTimer(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			t.Reset(interval)
+
+			if err := p.SaveBudgets(ctx, s); err != nil && ctx.Err() == nil {
+				p.l.Printf("failed to save budgets: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package clients
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/streamdp/ccd/db/memory"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
+)
+
+type limitedRestClient struct {
+	mockRestClient
+	limiter *ratelimit.Limiter
+}
+
+func (l *limitedRestClient) Budget() ratelimit.Budget {
+	return l.limiter.Budget()
+}
+
+func (l *limitedRestClient) RestoreBudget(used int64, resetAt time.Time) {
+	l.limiter.Restore(used, resetAt)
+}
+
+func TestProviders_budgets(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := memory.Connect("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	newProviders := func() (*Providers, *limitedRestClient) {
+		p := NewProviders("kraken", log.New(io.Discard, "", 0), nil)
+		r := &limitedRestClient{limiter: ratelimit.New(0, 100)}
+		p.AddRest("kraken", r)
+		p.AddRest("huobi", &mockRestClient{})
+
+		return p, r
+	}
+
+	p, r := newProviders()
+	for range 3 {
+		if err = r.limiter.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = p.SaveBudgets(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+
+	budgets, err := store.Budgets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(budgets) != 1 || budgets[0].Provider != "kraken" || budgets[0].Used != 3 {
+		t.Errorf("SaveBudgets() saved = %v, want the kraken budget only", budgets)
+	}
+
+	restarted, restartedClient := newProviders()
+	if err = restarted.RestoreBudgets(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := restartedClient.Budget(); got.Used != 3 {
+		t.Errorf("RestoreBudgets() used = %d, want 3", got.Used)
+	}
+
+	// the budget of the past month is ignored
+	if _, err = store.SaveBudget(ctx, &domain.Budget{Provider: "kraken", Used: 50, ResetAt: 1738368000}); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, restartedClient = newProviders()
+	if err = restarted.RestoreBudgets(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := restartedClient.Budget(); got.Used != 0 {
+		t.Errorf("RestoreBudgets() of the past month used = %d, want 0", got.Used)
+	}
+}
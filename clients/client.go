@@ -2,12 +2,21 @@ package clients
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type RestClient interface {
-	Get(from string, to string) (*domain.Data, error)
+	Get(ctx context.Context, from string, to string) (*domain.Data, error)
+	// GetMany fetch data for several currencies pairs at once, pairs the provider has no data for are left out of
+	// the result
+	GetMany(ctx context.Context, pairs []domain.Pair) ([]*domain.Data, error)
 	Close() error
 }
 
@@ -16,6 +25,14 @@ type WsClient interface {
 	Unsubscribe(ctx context.Context, from string, to string) error
 	ListSubscriptions() domain.Subscriptions
 	RestoreLastSession(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+// BudgetReporter is implemented by the rest clients limiting upstream calls with the monthly budget, the used budget
+// is restored after the restart
+type BudgetReporter interface {
+	Budget() ratelimit.Budget
+	RestoreBudget(used int64, resetAt time.Time)
 }
 
 type SessionRepo interface {
@@ -26,3 +43,37 @@ type SessionRepo interface {
 
 	Close() error
 }
+
+// NewHttpClient return the http client of the data provider, the upstream calls are traced
+func NewHttpClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
+	}
+}
+
+// GetEach fetch data for the currencies pairs one by one, it is used by providers without the batch endpoint, pairs
+// failed to fetch are left out of the result, the error is returned when no pair was fetched
+func GetEach(ctx context.Context, r RestClient, pairs []domain.Pair) ([]*domain.Data, error) {
+	var (
+		result = make([]*domain.Data, 0, len(pairs))
+		errs   []error
+	)
+
+	for _, p := range pairs {
+		data, err := r.Get(ctx, p.From, p.To)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", p.From, p.To, err))
+
+			continue
+		}
+
+		result = append(result, data)
+	}
+
+	if len(result) == 0 && len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package coinbase
+
+import (
+	"time"
+)
+
+type restTicker struct {
+	Ask     string    `json:"ask"`
+	Bid     string    `json:"bid"`
+	Volume  string    `json:"volume"`
+	TradeId int64     `json:"trade_id"`
+	Price   string    `json:"price"`
+	Size    string    `json:"size"`
+	Time    time.Time `json:"time"`
+}
+
+type restStats struct {
+	Open        string `json:"open"`
+	High        string `json:"high"`
+	Low         string `json:"low"`
+	Last        string `json:"last"`
+	Volume      string `json:"volume"`
+	Volume30Day string `json:"volume_30day"`
+}
+
+type restError struct {
+	Message string `json:"message"`
+}
+
+type wsMessage struct {
+	Type       string   `json:"type"`
+	ProductIds []string `json:"product_ids"`
+	Channels   []string `json:"channels"`
+}
+
+type wsResponse struct {
+	Type     string `json:"type"`
+	Message  string `json:"message,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Channels []struct {
+		Name       string   `json:"name"`
+		ProductIds []string `json:"product_ids"`
+	} `json:"channels,omitempty"`
+}
+
+type wsTickerInfo struct {
+	Type        string    `json:"type"`
+	Sequence    int64     `json:"sequence"`
+	ProductId   string    `json:"product_id"`
+	Price       string    `json:"price"`
+	Open24h     string    `json:"open_24h"`
+	Volume24h   string    `json:"volume_24h"`
+	Low24h      string    `json:"low_24h"`
+	High24h     string    `json:"high_24h"`
+	Volume30d   string    `json:"volume_30d"`
+	BestBid     string    `json:"best_bid"`
+	BestBidSize string    `json:"best_bid_size"`
+	BestAsk     string    `json:"best_ask"`
+	BestAskSize string    `json:"best_ask_size"`
+	Side        string    `json:"side"`
+	Time        time.Time `json:"time"`
+	TradeId     int64     `json:"trade_id"`
+	LastSize    string    `json:"last_size"`
+}
@@ -0,0 +1,221 @@
+package coinbase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/streamdp/ccd/clients"
+	"github.com/streamdp/ccd/config"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
+)
+
+const (
+	Name = "coinbase"
+
+	apiUrl = "https://api.exchange.coinbase.com"
+
+	// Get product ticker https://docs.cdp.coinbase.com/exchange/reference/exchangerestapi_getproductticker
+	// Gets snapshot information about the last trade (tick), best bid/ask and 24h volume.
+	// Path Parameters "product_id" (e.g. BTC-USD. Refer to /products)
+	productTicker = "/products/%s/ticker"
+
+	// Get product stats https://docs.cdp.coinbase.com/exchange/reference/exchangerestapi_getproductstats
+	// Gets 30day and 24hour stats for a product.
+	productStats = "/products/%s/stats"
+
+	rateLimit = 4 // make max two api calls per second, every call makes two requests
+)
+
+type rest struct {
+	apiUrl  string
+	client  *http.Client
+	limiter *ratelimit.Limiter
+}
+
+var (
+	errWrongStatusCode = errors.New("wrong response status code")
+	errEmptyData       = errors.New("empty data")
+)
+
+func Init(cfg *config.App) (*rest, error) {
+	return &rest{
+		apiUrl:  apiUrl,
+		client:  clients.NewHttpClient(cfg.Http.ClientTimeout()),
+		limiter: ratelimit.New(cfg.Limits.Rate(Name, rateLimit), cfg.Limits.Budget(Name)),
+	}, nil
+}
+
+func (r *rest) Get(ctx context.Context, fSym string, tSym string) (*domain.Data, error) {
+	productId := buildProductId(fSym, tSym)
+
+	ticker := &restTicker{}
+	if err := r.fetch(ctx, productTicker, productId, ticker); err != nil {
+		return nil, fmt.Errorf("failed to get ticker: %w", err)
+	}
+
+	stats := &restStats{}
+	if err := r.fetch(ctx, productStats, productId, stats); err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	return convertRestDataToDomain(fSym, tSym, ticker, stats)
+}
+
+// GetMany data for several currencies pairs, coinbase has no batch endpoint, so pairs are fetched one by one
+func (r *rest) GetMany(ctx context.Context, pairs []domain.Pair) ([]*domain.Data, error) {
+	return clients.GetEach(ctx, r, pairs)
+}
+
+func (r *rest) Close() error {
+	return nil
+}
+
+// Budget return the state of the monthly budget of the upstream calls
+func (r *rest) Budget() ratelimit.Budget {
+	return r.limiter.Budget()
+}
+
+// RestoreBudget used before the restart
+func (r *rest) RestoreBudget(used int64, resetAt time.Time) {
+	r.limiter.Restore(used, resetAt)
+}
+
+func (r *rest) fetch(ctx context.Context, endpoint, productId string, v any) error {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	var (
+		request  *http.Request
+		response *http.Response
+		body     []byte
+	)
+
+	u, err := r.buildURL(endpoint, productId)
+	if err != nil {
+		return fmt.Errorf("failed to build url: %w", err)
+	}
+
+	if request, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil); err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	if response, err = r.client.Do(request); err != nil {
+		return fmt.Errorf("failed to fetch data: %w", err)
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+
+	if body, err = io.ReadAll(response.Body); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		restErr := &restError{}
+		if err = json.Unmarshal(body, restErr); err != nil || restErr.Message == "" {
+			return errWrongStatusCode
+		}
+
+		return fmt.Errorf("%w: server error: %s", errWrongStatusCode, restErr.Message)
+	}
+
+	if err = json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+func (r *rest) buildURL(endpoint, productId string) (*url.URL, error) {
+	u, err := url.Parse(r.apiUrl + fmt.Sprintf(endpoint, url.PathEscape(productId)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url: %w", err)
+	}
+
+	return u, nil
+}
+
+// buildProductId of the coinbase trading pair, e.g. BTC-USD
+func buildProductId(fSym string, tSym string) string {
+	return strings.ToUpper(fSym + "-" + tSym)
+}
+
+func convertRestDataToDomain(from, to string, t *restTicker, s *restStats) (*domain.Data, error) {
+	if t == nil || s == nil || t.Price == "" {
+		return nil, errEmptyData
+	}
+
+	return convertTickerToDomain(from, to, &wsTickerInfo{
+		Price:     t.Price,
+		Open24h:   s.Open,
+		Volume24h: s.Volume,
+		Low24h:    s.Low,
+		High24h:   s.High,
+		Time:      t.Time,
+	})
+}
+
+func convertTickerToDomain(from, to string, t *wsTickerInfo) (*domain.Data, error) {
+	if t == nil || t.Price == "" {
+		return nil, errEmptyData
+	}
+
+	open24Hour, _ := strconv.ParseFloat(t.Open24h, 64)
+	volume24Hour, _ := strconv.ParseFloat(t.Volume24h, 64)
+	low24Hour, _ := strconv.ParseFloat(t.Low24h, 64)
+	high24Hour, _ := strconv.ParseFloat(t.High24h, 64)
+	price, _ := strconv.ParseFloat(t.Price, 64)
+
+	var change24Hour, changePct24Hour float64
+	if open24Hour != 0 {
+		change24Hour = price - open24Hour
+		changePct24Hour = change24Hour / open24Hour * 100
+	}
+
+	lastUpdate := t.Time.UnixMilli()
+	if t.Time.IsZero() {
+		lastUpdate = time.Now().UTC().UnixMilli()
+	}
+
+	b, err := json.Marshal(&domain.Raw{
+		FromSymbol:      from,
+		ToSymbol:        to,
+		Change24Hour:    change24Hour,
+		ChangePct24Hour: changePct24Hour,
+		Open24Hour:      open24Hour,
+		Volume24Hour:    volume24Hour,
+		Low24Hour:       low24Hour,
+		High24Hour:      high24Hour,
+		Price:           price,
+		LastUpdate:      lastUpdate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal raw data: %w", err)
+	}
+
+	return &domain.Data{
+		FromSymbol:      from,
+		ToSymbol:        to,
+		Provider:        Name,
+		Change24Hour:    change24Hour,
+		ChangePct24Hour: changePct24Hour,
+		Open24Hour:      open24Hour,
+		Volume24Hour:    volume24Hour,
+		Low24Hour:       low24Hour,
+		High24Hour:      high24Hour,
+		Price:           price,
+		LastUpdate:      lastUpdate,
+		DisplayDataRaw:  string(b),
+	}, nil
+}
@@ -0,0 +1,279 @@
+package coinbase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
+)
+
+var wantTicker = &domain.Data{
+	FromSymbol:      "BTC",
+	ToSymbol:        "USD",
+	Provider:        Name,
+	Change24Hour:    -1500,
+	ChangePct24Hour: -1.5,
+	Open24Hour:      100000,
+	Volume24Hour:    8417.20917413,
+	Low24Hour:       97012.01,
+	High24Hour:      101320.5,
+	Price:           98500,
+	LastUpdate:      1746439219990,
+	DisplayDataRaw: "{\"from_symbol\":\"BTC\",\"to_symbol\":\"USD\",\"change_24_hour\":-1500," +
+		"\"changepct_24_hour\":-1.5,\"open_24_hour\":100000,\"volume_24_hour\":8417.20917413," +
+		"\"volume_24_hour_to\":0,\"low_24_hour\":97012.01,\"high_24_hour\":101320.5,\"price\":98500," +
+		"\"supply\":0,\"mkt_cap\":0,\"last_update\":1746439219990}",
+}
+
+func Test_convertRestDataToDomain(t *testing.T) {
+	type args struct {
+		from   string
+		to     string
+		ticker *restTicker
+		stats  *restStats
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want *domain.Data
+	}{
+		{
+			name: "nil",
+			args: args{
+				from: "BTC",
+				to:   "USD",
+			},
+			want: nil,
+		},
+		{
+			name: "empty ticker",
+			args: args{
+				from:   "BTC",
+				to:     "USD",
+				ticker: &restTicker{},
+				stats:  &restStats{},
+			},
+			want: nil,
+		},
+		{
+			name: "regular conversion",
+			args: args{
+				from: "BTC",
+				to:   "USD",
+				ticker: &restTicker{
+					Ask:     "98500.01",
+					Bid:     "98499.99",
+					Volume:  "8417.20917413",
+					TradeId: 812345678,
+					Price:   "98500.00",
+					Size:    "0.0012",
+					Time:    time.UnixMilli(1746439219990).UTC(),
+				},
+				stats: &restStats{
+					Open:   "100000.00",
+					High:   "101320.50",
+					Low:    "97012.01",
+					Last:   "98500.00",
+					Volume: "8417.20917413",
+				},
+			},
+			want: wantTicker,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := convertRestDataToDomain(tt.args.from, tt.args.to, tt.args.ticker, tt.args.stats)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convertRestDataToDomain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_rest_buildURL(t *testing.T) {
+	type args struct {
+		endpoint  string
+		productId string
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		wantU   *url.URL
+		wantErr bool
+	}{
+		{
+			name: "ticker url",
+			args: args{
+				endpoint:  productTicker,
+				productId: buildProductId("btc", "usd"),
+			},
+			wantU: func() *url.URL {
+				u, _ := url.Parse("https://api.exchange.coinbase.com/products/BTC-USD/ticker")
+
+				return u
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "stats url",
+			args: args{
+				endpoint:  productStats,
+				productId: buildProductId("ETH", "USDT"),
+			},
+			wantU: func() *url.URL {
+				u, _ := url.Parse("https://api.exchange.coinbase.com/products/ETH-USDT/stats")
+
+				return u
+			}(),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rest{apiUrl: apiUrl}
+
+			gotU, err := r.buildURL(tt.args.endpoint, tt.args.productId)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("buildURL() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+
+			if !reflect.DeepEqual(gotU, tt.wantU) {
+				t.Errorf("buildURL() gotU = %v, want %v", gotU, tt.wantU)
+			}
+		})
+	}
+}
+
+func Test_rest_Get(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/products/BTC-USD/ticker", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"ask":"98500.01","bid":"98499.99","volume":"8417.20917413","trade_id":812345678,` +
+			`"price":"98500.00","size":"0.0012","time":"2025-05-05T10:00:19.990Z"}`))
+	})
+	mux.HandleFunc("/products/BTC-USD/stats", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"open":"100000.00","high":"101320.50","low":"97012.01","last":"98500.00",` +
+			`"volume":"8417.20917413","volume_30day":"250000.1"}`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"NotFound"}`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		want    *domain.Data
+		wantErr error
+	}{
+		{
+			name: "regular response",
+			from: "BTC",
+			to:   "USD",
+			want: wantTicker,
+		},
+		{
+			name:    "unknown product",
+			from:    "BTC",
+			to:      "XYZ",
+			wantErr: errWrongStatusCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rest{
+				apiUrl:  srv.URL,
+				client:  srv.Client(),
+				limiter: ratelimit.New(0, 0),
+			}
+			defer func() { _ = r.Close() }()
+
+			got, err := r.Get(context.Background(), tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_rest_GetMany(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/products/BTC-USD/ticker", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"price":"98500.00","volume":"8417.20917413","time":"2025-05-05T10:00:19.990Z"}`))
+	})
+	mux.HandleFunc("/products/BTC-USD/stats", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"open":"100000.00","high":"101320.50","low":"97012.01","last":"98500.00"}`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"NotFound"}`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		pairs   []domain.Pair
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "unknown product is left out",
+			pairs: []domain.Pair{{From: "BTC", To: "USD"}, {From: "XYZ", To: "USD"}},
+			want:  []string{"BTC/USD"},
+		},
+		{
+			name:    "all products are unknown",
+			pairs:   []domain.Pair{{From: "XYZ", To: "USD"}},
+			wantErr: errWrongStatusCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rest{
+				apiUrl:  srv.URL,
+				client:  srv.Client(),
+				limiter: ratelimit.New(0, 0),
+			}
+			defer func() { _ = r.Close() }()
+
+			data, err := r.GetMany(context.Background(), tt.pairs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetMany() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+
+			var got []string
+			for _, d := range data {
+				got = append(got, d.FromSymbol+"/"+d.ToSymbol)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMany() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
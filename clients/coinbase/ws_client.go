@@ -0,0 +1,142 @@
+package coinbase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/streamdp/ccd/clients"
+	"github.com/streamdp/ccd/config"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/wsclient"
+)
+
+const wsUrl = "wss://ws-feed.exchange.coinbase.com"
+
+func InitWs(
+	ctx context.Context,
+	sessionRepo clients.SessionRepo,
+	l *log.Logger,
+	cfg *config.Http,
+	pipe ...chan *domain.Data,
+) *wsclient.Ws {
+	return initWs(ctx, wsUrl, sessionRepo, l, cfg, pipe...)
+}
+
+func initWs(
+	ctx context.Context,
+	wsUrl string,
+	sessionRepo clients.SessionRepo,
+	l *log.Logger,
+	cfg *config.Http,
+	pipe ...chan *domain.Data,
+) *wsclient.Ws {
+	w := wsclient.New(ctx, Name, wsUrl, sessionRepo, l, cfg)
+
+	w.ChannelNameBuilder = buildProductId
+
+	w.SubscribeMessageBuilder = func(ch string, _ int64) ([]byte, error) {
+		return buildWsMessage("subscribe", ch)
+	}
+
+	w.UnsubscribeMessageBuilder = func(ch string, _ int64) ([]byte, error) {
+		return buildWsMessage("unsubscribe", ch)
+	}
+
+	w.MessageHandler = func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				body, err := w.Read(ctx)
+				if err != nil {
+					if !errors.Is(err, context.Canceled) {
+						l.Println(err)
+					}
+
+					if errors.Is(err, context.Canceled) || errors.Is(err, wsclient.ErrClientReconnected) {
+						continue
+					}
+
+					if err = w.WsDown(); err != nil {
+						l.Println(err)
+					}
+
+					return
+				}
+
+				if msg, err := handleWsMessage(w, body, pipe); err != nil {
+					l.Println(err)
+				} else if msg != "" {
+					l.Println(msg)
+				}
+			}
+		}
+	}
+
+	return w
+}
+
+func buildWsMessage(t string, productId string) ([]byte, error) {
+	msg, err := json.Marshal(wsMessage{
+		Type:       t,
+		ProductIds: []string{productId},
+		Channels:   []string{"ticker"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	return msg, nil
+}
+
+// handleWsMessage send ticker updates to the pipes and return the text of the server response worth logging
+func handleWsMessage(w *wsclient.Ws, body []byte, pipe []chan *domain.Data) (string, error) {
+	msg := &wsResponse{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return "", fmt.Errorf("failed to unmarshal ws message: %w", err)
+	}
+
+	switch msg.Type {
+	case "ticker":
+		return "", handleWsUpdate(w, body, pipe)
+	case "error":
+		return fmt.Sprintf("server error: %s %s", msg.Message, msg.Reason), nil
+	case "subscriptions":
+		var products []string
+		for _, ch := range msg.Channels {
+			products = append(products, ch.ProductIds...)
+		}
+
+		return "ticker channel: active subscriptions " + strings.Join(products, ", "), nil
+	}
+
+	return "", nil
+}
+
+func handleWsUpdate(w *wsclient.Ws, body []byte, pipe []chan *domain.Data) error {
+	tick := &wsTickerInfo{}
+	if err := json.Unmarshal(body, tick); err != nil {
+		return fmt.Errorf("failed to unmarshal ws update message: %w", err)
+	}
+
+	from, to := w.PairFromChannelName(tick.ProductId)
+	if from == "" || to == "" {
+		return nil
+	}
+
+	data, err := convertTickerToDomain(from, to, tick)
+	if err != nil {
+		return fmt.Errorf("failed to convert ws update message: %w", err)
+	}
+
+	for i := range pipe {
+		pipe[i] <- data
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package coinbase
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/streamdp/ccd/config"
+	"github.com/streamdp/ccd/domain"
+)
+
+type mockSessionRepo struct{}
+
+func (m *mockSessionRepo) AddTask(_ context.Context, _ string, _ int64) error    { return nil }
+func (m *mockSessionRepo) UpdateTask(_ context.Context, _ string, _ int64) error { return nil }
+func (m *mockSessionRepo) RemoveTask(_ context.Context, _ string) error          { return nil }
+func (m *mockSessionRepo) Close() error                                          { return nil }
+
+func (m *mockSessionRepo) GetSession(_ context.Context) (map[string]int64, error) {
+	return map[string]int64{}, nil
+}
+
+func Test_buildWsMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		t         string
+		productId string
+		want      string
+	}{
+		{
+			name:      "subscribe",
+			t:         "subscribe",
+			productId: buildProductId("btc", "usd"),
+			want:      `{"type":"subscribe","product_ids":["BTC-USD"],"channels":["ticker"]}`,
+		},
+		{
+			name:      "unsubscribe",
+			t:         "unsubscribe",
+			productId: buildProductId("eth", "usdt"),
+			want:      `{"type":"unsubscribe","product_ids":["ETH-USDT"],"channels":["ticker"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildWsMessage(tt.t, tt.productId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("buildWsMessage() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitWs(t *testing.T) {
+	subscribed := make(chan []byte, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.CloseNow() }()
+
+		_, msg, err := conn.Read(r.Context())
+		if err != nil {
+			return
+		}
+
+		subscribed <- msg
+
+		for _, m := range []string{
+			`{"type":"subscriptions","channels":[{"name":"ticker","product_ids":["BTC-USD"]}]}`,
+			`{"type":"ticker","sequence":1,"product_id":"BTC-USD","price":"98500.00","open_24h":"100000.00",` +
+				`"volume_24h":"8417.20917413","low_24h":"97012.01","high_24h":"101320.50","best_bid":"98499.99",` +
+				`"best_ask":"98500.01","side":"buy","time":"2025-05-05T10:00:19.990Z","trade_id":812345678,` +
+				`"last_size":"0.0012"}`,
+		} {
+			if err = conn.Write(r.Context(), websocket.MessageText, []byte(m)); err != nil {
+				return
+			}
+		}
+
+		// keep the connection open until the client goes away
+		_, _, _ = conn.Read(r.Context())
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pipe := make(chan *domain.Data, 1)
+	w := initWs(
+		ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), &mockSessionRepo{}, log.New(io.Discard, "", 0),
+		&config.Http{}, pipe,
+	)
+
+	// the connection is served by the background goroutine, give it a chance to start
+	for err := w.Subscribe(ctx, "BTC", "USD"); err != nil; err = w.Subscribe(ctx, "BTC", "USD") {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("Subscribe() error = %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	select {
+	case msg := <-subscribed:
+		want, _ := buildWsMessage("subscribe", "BTC-USD")
+		if !json.Valid(msg) || string(msg) != string(want) {
+			t.Errorf("subscribe message = %s, want %s", msg, want)
+		}
+	case <-ctx.Done():
+		t.Fatal("subscribe message wasn't received")
+	}
+
+	select {
+	case got := <-pipe:
+		if !reflect.DeepEqual(got, wantTicker) {
+			t.Errorf("ws update = %v, want %v", got, wantTicker)
+		}
+	case <-ctx.Done():
+		t.Fatal("ws update wasn't received")
+	}
+}
@@ -0,0 +1,139 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+const (
+	// ConsolidatedName is the pseudo data provider name used by the puller tasks and stored rows of the consolidated
+	// price
+	ConsolidatedName = "consolidated"
+
+	defaultOutlierThreshold = 2.0
+)
+
+// Consolidated asks all rest clients in parallel and build the price from their answers, it implements RestClient
+// with the median price and the default outlier threshold, so it can be used by the puller
+type Consolidated struct {
+	rest map[string]RestClient
+}
+
+func NewConsolidated(rest map[string]RestClient) *Consolidated {
+	return &Consolidated{rest: rest}
+}
+
+// Get the median price for the selected currencies pair
+func (c *Consolidated) Get(ctx context.Context, from string, to string) (*domain.Data, error) {
+	p, err := c.Price(ctx, from, to, domain.MethodMedian, defaultOutlierThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Data(ConsolidatedName), nil
+}
+
+// GetMany median prices for several currencies pairs, every rest client is asked for all pairs at once, the answers
+// are matched to the pairs regardless of the symbols case
+func (c *Consolidated) GetMany(ctx context.Context, pairs []domain.Pair) ([]*domain.Data, error) {
+	names := slices.Sorted(maps.Keys(c.rest))
+	answers := make([]map[domain.Pair]*domain.Data, len(names))
+	errs := make([]error, len(names))
+
+	var wg sync.WaitGroup
+
+	for i, name := range names {
+		wg.Go(func() {
+			data, err := c.rest[name].GetMany(ctx, pairs)
+			if err != nil {
+				errs[i] = err
+
+				return
+			}
+
+			answers[i] = make(map[domain.Pair]*domain.Data, len(data))
+			for _, d := range data {
+				answers[i][upperPair(d.FromSymbol, d.ToSymbol)] = d
+			}
+		})
+	}
+
+	wg.Wait()
+
+	result := make([]*domain.Data, 0, len(pairs))
+
+	for _, pair := range pairs {
+		prices := make([]*domain.ProviderPrice, len(names))
+		for i, name := range names {
+			prices[i] = newProviderPrice(name, answers[i][upperPair(pair.From, pair.To)], errs[i])
+		}
+
+		p, err := domain.NewConsolidatedPrice(
+			pair.From, pair.To, domain.MethodMedian, defaultOutlierThreshold, prices,
+		)
+		if err != nil {
+			continue
+		}
+
+		result = append(result, p.Data(ConsolidatedName))
+	}
+
+	return result, nil
+}
+
+// Price consolidated from all rest clients with the selected method, the answers deviated from the median more than
+// threshold percent are flagged as outliers
+func (c *Consolidated) Price(ctx context.Context, from, to, method string, threshold float64) (*domain.ConsolidatedPrice, error) {
+	names := slices.Sorted(maps.Keys(c.rest))
+	prices := make([]*domain.ProviderPrice, len(names))
+
+	var wg sync.WaitGroup
+
+	for i, name := range names {
+		wg.Go(func() {
+			data, err := c.rest[name].Get(ctx, from, to)
+			prices[i] = newProviderPrice(name, data, err)
+		})
+	}
+
+	wg.Wait()
+
+	p, err := domain.NewConsolidatedPrice(from, to, method, threshold, prices)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consolidate %s/%s price: %w", from, to, err)
+	}
+
+	return p, nil
+}
+
+// Close do nothing, consolidated rest clients are closed by the Providers
+func (c *Consolidated) Close() error {
+	return nil
+}
+
+func upperPair(from, to string) domain.Pair {
+	return domain.Pair{From: strings.ToUpper(from), To: strings.ToUpper(to)}
+}
+
+func newProviderPrice(provider string, data *domain.Data, err error) *domain.ProviderPrice {
+	p := &domain.ProviderPrice{Provider: provider}
+
+	switch {
+	case err != nil:
+		p.Error = err.Error()
+	case data == nil:
+		p.Error = "no data"
+	default:
+		p.Price = data.Price
+		p.Volume24Hour = data.Volume24Hour
+		p.LastUpdate = data.LastUpdate
+	}
+
+	return p
+}
@@ -0,0 +1,65 @@
+package clients
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+func TestConsolidated_Get(t *testing.T) {
+	c := NewConsolidated(map[string]RestClient{
+		"cryptocompare": &mockRestClient{price: 100},
+		"huobi":         &mockRestClient{price: 102},
+		"kraken":        &mockRestClient{err: errUpstream},
+	})
+
+	got, err := c.Get(context.Background(), "BTC", "USDT")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if got.Provider != ConsolidatedName || got.Price != 101 {
+		t.Errorf("Get() got = %v, want consolidated median price 101", got)
+	}
+
+	p, err := c.Price(context.Background(), "BTC", "USDT", domain.MethodMedian, 2)
+	if err != nil {
+		t.Fatalf("Price() error = %v", err)
+	}
+
+	if len(p.Providers) != 3 || p.Providers[2].Provider != "kraken" || p.Providers[2].Error != errUpstream.Error() {
+		t.Errorf("Price() providers = %v, want breakdown with the kraken error", p.Providers)
+	}
+}
+
+// upperRestClient answers with the upper cased symbols like the most data providers do
+type upperRestClient struct {
+	*mockRestClient
+}
+
+func (u upperRestClient) GetMany(ctx context.Context, pairs []domain.Pair) ([]*domain.Data, error) {
+	data, err := u.mockRestClient.GetMany(ctx, pairs)
+	for _, d := range data {
+		d.FromSymbol, d.ToSymbol = strings.ToUpper(d.FromSymbol), strings.ToUpper(d.ToSymbol)
+	}
+
+	return data, err
+}
+
+func TestConsolidated_GetMany(t *testing.T) {
+	c := NewConsolidated(map[string]RestClient{
+		"cryptocompare": &mockRestClient{price: 100},
+		"huobi":         upperRestClient{&mockRestClient{price: 102}},
+	})
+
+	got, err := c.GetMany(context.Background(), []domain.Pair{{From: "btc", To: "usdt"}})
+	if err != nil {
+		t.Fatalf("GetMany() error = %v", err)
+	}
+
+	if len(got) != 1 || got[0].Provider != ConsolidatedName || got[0].Price != 101 {
+		t.Errorf("GetMany() got = %v, want consolidated median price 101 of both providers", got)
+	}
+}
@@ -1,18 +1,26 @@
 package cryptocompare
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/streamdp/ccd/clients"
 	"github.com/streamdp/ccd/config"
 	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
 )
 
 const (
+	Name = "cryptocompare"
+
 	apiUrl = "https://min-api.cryptocompare.com"
 
 	// Multiple Symbols Full Data - Get all the current trading info (price, vol, open, high, low etc) of any list of
@@ -20,11 +28,15 @@ const (
 	// requested, BTC will be used for conversion. This API also returns Display values for all the fields. If the
 	// opposite pair trades we invert it (eg.: BTC-XMR)
 	multipleSymbolsFullData = "/data/pricemultifull"
+
+	rateLimit = 10 // make max ten api calls per second
 )
 
 type rest struct {
-	apiKey string
-	client *http.Client
+	apiUrl  string
+	apiKey  string
+	client  *http.Client
+	limiter *ratelimit.Limiter
 }
 
 var (
@@ -40,26 +52,78 @@ func Init(cfg *config.App) (*rest, error) {
 	}
 
 	return &rest{
-		apiKey: cfg.ApiKey,
-		client: &http.Client{
-			Timeout: cfg.Http.ClientTimeout(),
-		},
+		apiUrl:  apiUrl,
+		apiKey:  cfg.ApiKey,
+		client:  clients.NewHttpClient(cfg.Http.ClientTimeout()),
+		limiter: ratelimit.New(cfg.Limits.Rate(Name, rateLimit), cfg.Limits.Budget(Name)),
 	}, nil
 }
 
 // Get filled CryptoCompareData structure for the selected pair currencies over http/https
-func (r *rest) Get(fSym string, tSym string) (*domain.Data, error) {
+func (r *rest) Get(ctx context.Context, fSym string, tSym string) (*domain.Data, error) {
+	rawData, err := r.fetch(ctx, fSym, tSym)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertToDomain(fSym, tSym, rawData)
+}
+
+// GetMany data for several currencies pairs with the single request, all requested symbols are sent at once and the
+// requested pairs are picked from the response
+func (r *rest) GetMany(ctx context.Context, pairs []domain.Pair) ([]*domain.Data, error) {
+	var fSyms, tSyms []string
+
+	for _, p := range pairs {
+		if !slices.Contains(fSyms, p.From) {
+			fSyms = append(fSyms, p.From)
+		}
+
+		if !slices.Contains(tSyms, p.To) {
+			tSyms = append(tSyms, p.To)
+		}
+	}
+
+	rawData, err := r.fetch(ctx, strings.Join(fSyms, ","), strings.Join(tSyms, ","))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.Data, 0, len(pairs))
+
+	for _, p := range pairs {
+		data, errConvert := convertToDomain(p.From, p.To, rawData)
+		if errConvert != nil {
+			continue
+		}
+
+		result = append(result, data)
+	}
+
+	return result, nil
+}
+
+func (r *rest) fetch(ctx context.Context, fSyms string, tSyms string) (*restData, error) {
 	var (
+		request  *http.Request
 		response *http.Response
 		body     []byte
 	)
 
-	u, err := r.buildURL(fSym, tSym)
+	u, err := r.buildURL(fSyms, tSyms)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build url: %w", err)
 	}
 
-	response, err = r.client.Get(u.String())
+	if err = r.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if request, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	response, err = r.client.Do(request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch data: %w", err)
 	}
@@ -83,15 +147,25 @@ func (r *rest) Get(fSym string, tSym string) (*domain.Data, error) {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	return convertToDomain(fSym, tSym, rawData)
+	return rawData, nil
 }
 
 func (r *rest) Close() error {
 	return nil
 }
 
+// Budget return the state of the monthly budget of the upstream calls
+func (r *rest) Budget() ratelimit.Budget {
+	return r.limiter.Budget()
+}
+
+// RestoreBudget used before the restart
+func (r *rest) RestoreBudget(used int64, resetAt time.Time) {
+	r.limiter.Restore(used, resetAt)
+}
+
 func (r *rest) buildURL(fSym string, tSym string) (*url.URL, error) {
-	u, err := url.Parse(apiUrl + multipleSymbolsFullData)
+	u, err := url.Parse(r.apiUrl + multipleSymbolsFullData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build url: %w", err)
 	}
@@ -131,6 +205,7 @@ func convertToDomain(from, to string, d *restData) (*domain.Data, error) {
 	return &domain.Data{
 		FromSymbol:      from,
 		ToSymbol:        to,
+		Provider:        Name,
 		Change24Hour:    r.Change24Hour,
 		ChangePct24Hour: r.Changepct24Hour,
 		Open24Hour:      r.Open24Hour,
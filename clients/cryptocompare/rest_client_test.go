@@ -1,12 +1,18 @@
 package cryptocompare
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
 )
 
 func Test_convertToDomain(t *testing.T) {
@@ -49,6 +55,7 @@ func Test_convertToDomain(t *testing.T) {
 			want: &domain.Data{
 				FromSymbol:      "BTC",
 				ToSymbol:        "USDT",
+				Provider:        Name,
 				Change24Hour:    12345,
 				ChangePct24Hour: 54321,
 				Open24Hour:      60867.47,
@@ -90,6 +97,7 @@ func Test_convertToDomain(t *testing.T) {
 			want: &domain.Data{
 				FromSymbol: "BTC",
 				ToSymbol:   "USDT",
+				Provider:   Name,
 				DisplayDataRaw: "{\"from_symbol\":\"BTC\",\"to_symbol\":\"USDT\",\"change_24_hour\":0," +
 					"\"changepct_24_hour\":0,\"open_24_hour\":0,\"volume_24_hour\":0,\"volume_24_hour_to\":0," +
 					"\"low_24_hour\":0,\"high_24_hour\":0,\"price\":0,\"supply\":0,\"mkt_cap\":0,\"last_update\":0}",
@@ -148,6 +156,7 @@ func Test_cryptoCompareRest_buildURL(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cc := &rest{
+				apiUrl: apiUrl,
 				apiKey: tt.fields.apiKey,
 				client: tt.fields.client,
 			}
@@ -166,3 +175,87 @@ func Test_cryptoCompareRest_buildURL(t *testing.T) {
 		})
 	}
 }
+
+func Test_rest_GetMany(t *testing.T) {
+	prices := map[string]map[string]float64{
+		"BTC": {"USD": 94183.3, "EUR": 83120.5},
+		"ETH": {"USD": 1800.5},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		raw := make(map[string]map[string]*Response)
+
+		for _, f := range strings.Split(r.URL.Query().Get("fsyms"), ",") {
+			for _, to := range strings.Split(r.URL.Query().Get("tsyms"), ",") {
+				price, ok := prices[f][to]
+				if !ok {
+					continue
+				}
+
+				if raw[f] == nil {
+					raw[f] = make(map[string]*Response)
+				}
+
+				raw[f][to] = &Response{Price: price, LastUpdate: 1746439219}
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode(&restData{Raw: raw})
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		apiKey  string
+		pairs   []domain.Pair
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "pairs are picked from the response",
+			apiKey: "key",
+			pairs: []domain.Pair{
+				{From: "BTC", To: "USD"}, {From: "XYZ", To: "USD"}, {From: "ETH", To: "EUR"}, {From: "ETH", To: "USD"},
+			},
+			want: []string{"BTC/USD", "ETH/USD"},
+		},
+		{
+			name:    "wrong status code",
+			pairs:   []domain.Pair{{From: "BTC", To: "USD"}},
+			wantErr: errWrongStatusCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rest{
+				apiUrl:  srv.URL,
+				apiKey:  tt.apiKey,
+				client:  srv.Client(),
+				limiter: ratelimit.New(0, 0),
+			}
+			defer func() { _ = r.Close() }()
+
+			data, err := r.GetMany(context.Background(), tt.pairs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetMany() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+
+			var got []string
+			for _, d := range data {
+				got = append(got, d.FromSymbol+"/"+d.ToSymbol)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMany() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
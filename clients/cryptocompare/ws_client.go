@@ -33,7 +33,7 @@ func InitWs(
 		return nil, fmt.Errorf("failed to build url: %w", err)
 	}
 
-	w := wsclient.New(ctx, wssUrl.String(), sessionRepo, l, cfg)
+	w := wsclient.New(ctx, Name, wssUrl.String(), sessionRepo, l, cfg)
 
 	w.ChannelNameBuilder = buildChannelName
 
@@ -190,6 +190,7 @@ func convertWsDataToDomain(d *wsData) (*domain.Data, error) {
 	return &domain.Data{
 		FromSymbol:     d.FromSymbol,
 		ToSymbol:       d.ToSymbol,
+		Provider:       Name,
 		Open24Hour:     d.Open24Hour,
 		Volume24Hour:   d.Volume24Hour,
 		Low24Hour:      d.Low24Hour,
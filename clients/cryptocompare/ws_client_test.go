@@ -106,6 +106,7 @@ func Test_convertWsDataToDomain(t *testing.T) {
 			want: &domain.Data{
 				FromSymbol:   "BTC",
 				ToSymbol:     "ETH",
+				Provider:     Name,
 				Open24Hour:   60867.47,
 				Volume24Hour: 666.1991214442407,
 				Low24Hour:    60867.47,
@@ -124,6 +125,7 @@ func Test_convertWsDataToDomain(t *testing.T) {
 			name: "empty",
 			d:    &wsData{},
 			want: &domain.Data{
+				Provider: Name,
 				DisplayDataRaw: "{\"from_symbol\":\"\",\"to_symbol\":\"\",\"change_24_hour\":0," +
 					"\"changepct_24_hour\":0,\"open_24_hour\":0,\"volume_24_hour\":0,\"volume_24_hour_to\":0," +
 					"\"low_24_hour\":0,\"high_24_hour\":0,\"price\":0,\"supply\":0,\"mkt_cap\":0,\"last_update\":0}",
@@ -0,0 +1,189 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+var (
+	ErrEmptyFailoverChain     = errors.New("failover chain cannot be empty")
+	ErrAllProvidersCoolingOff = errors.New("all rest providers are on the cool-down")
+)
+
+// ProviderHealth of the rest provider in the failover chain
+type ProviderHealth struct {
+	Provider          string    `json:"provider"`
+	Healthy           bool      `json:"healthy"`
+	ConsecutiveErrors int       `json:"consecutive_errors"`
+	LastError         string    `json:"last_error,omitempty"`
+	LastSuccess       time.Time `json:"last_success"`
+	CoolDownUntil     time.Time `json:"cool_down_until"`
+}
+
+type failoverNode struct {
+	name   string
+	client RestClient
+	health ProviderHealth
+}
+
+// Failover is the RestClient which asks the rest providers one by one in the configured order, the provider is put on
+// the cool-down after the number of consecutive errors and skipped until the cool-down ends
+type Failover struct {
+	nodes    []*failoverNode
+	errors   int
+	coolDown time.Duration
+	now      func() time.Time
+	mu       sync.Mutex
+}
+
+func NewFailover(p *Providers, chain []string, maxErrors int, coolDown time.Duration) (*Failover, error) {
+	if len(chain) == 0 {
+		return nil, ErrEmptyFailoverChain
+	}
+
+	f := &Failover{
+		nodes:    make([]*failoverNode, 0, len(chain)),
+		errors:   maxErrors,
+		coolDown: coolDown,
+		now:      time.Now,
+	}
+
+	for _, name := range chain {
+		r, err := p.Rest(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build failover chain: %w", err)
+		}
+
+		f.nodes = append(f.nodes, &failoverNode{
+			name:   name,
+			client: r,
+			health: ProviderHealth{Provider: name, Healthy: true},
+		})
+	}
+
+	return f, nil
+}
+
+// Get data from the first available rest provider in the chain, the answered provider is stored in the
+// domain.Data.Provider field
+func (f *Failover) Get(ctx context.Context, from string, to string) (*domain.Data, error) {
+	result, err := f.do(ctx, func(r RestClient) ([]*domain.Data, error) {
+		data, err := r.Get(ctx, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*domain.Data{data}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result[0], nil
+}
+
+// GetMany data for several currencies pairs from the first available rest provider in the chain
+func (f *Failover) GetMany(ctx context.Context, pairs []domain.Pair) ([]*domain.Data, error) {
+	return f.do(ctx, func(r RestClient) ([]*domain.Data, error) {
+		return r.GetMany(ctx, pairs)
+	})
+}
+
+// do ask rest providers in the chain order until one of them answers, the canceled call stops the chain and is
+// not counted as the provider failure
+func (f *Failover) do(ctx context.Context, get func(r RestClient) ([]*domain.Data, error)) ([]*domain.Data, error) {
+	var errs []error
+
+	for _, n := range f.nodes {
+		if !f.available(n) {
+			continue
+		}
+
+		result, err := get(n.client)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		if err != nil {
+			f.fail(n, err)
+			errs = append(errs, fmt.Errorf("%s: %w", n.name, err))
+
+			continue
+		}
+
+		f.succeed(n)
+
+		for _, data := range result {
+			if data.Provider == "" {
+				data.Provider = n.name
+			}
+		}
+
+		return result, nil
+	}
+
+	if len(errs) == 0 {
+		return nil, ErrAllProvidersCoolingOff
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// Health return the state of the rest providers in the chain order
+func (f *Failover) Health() []ProviderHealth {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	h := make([]ProviderHealth, 0, len(f.nodes))
+	for _, n := range f.nodes {
+		h = append(h, n.health)
+	}
+
+	return h
+}
+
+// Close do nothing, rest clients of the chain are closed by the Providers
+func (f *Failover) Close() error {
+	return nil
+}
+
+func (f *Failover) available(n *failoverNode) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if n.health.Healthy {
+		return true
+	}
+
+	// the cool-down is over, give the provider another chance
+	return !f.now().Before(n.health.CoolDownUntil)
+}
+
+func (f *Failover) fail(n *failoverNode, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n.health.ConsecutiveErrors++
+	n.health.LastError = err.Error()
+
+	if n.health.ConsecutiveErrors >= f.errors {
+		n.health.Healthy = false
+		n.health.CoolDownUntil = f.now().Add(f.coolDown)
+	}
+}
+
+func (f *Failover) succeed(n *failoverNode) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n.health.Healthy = true
+	n.health.ConsecutiveErrors = 0
+	n.health.LastError = ""
+	n.health.LastSuccess = f.now()
+	n.health.CoolDownUntil = time.Time{}
+}
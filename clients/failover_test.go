@@ -0,0 +1,158 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+var errUpstream = errors.New("upstream is down")
+
+type mockRestClient struct {
+	price     float64
+	err       error
+	calls     int
+	manyCalls int
+}
+
+func (m *mockRestClient) Get(_ context.Context, from string, to string) (*domain.Data, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &domain.Data{FromSymbol: from, ToSymbol: to, Price: m.price}, nil
+}
+
+func (m *mockRestClient) GetMany(_ context.Context, pairs []domain.Pair) ([]*domain.Data, error) {
+	m.manyCalls++
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	result := make([]*domain.Data, 0, len(pairs))
+	for _, p := range pairs {
+		result = append(result, &domain.Data{FromSymbol: p.From, ToSymbol: p.To, Price: m.price})
+	}
+
+	return result, nil
+}
+
+func (m *mockRestClient) Close() error {
+	return nil
+}
+
+func newTestFailover(t *testing.T, now *time.Time, rest map[string]RestClient, chain ...string) *Failover {
+	t.Helper()
+
+	p := NewProviders(chain[0], nil, nil)
+	for name, r := range rest {
+		p.AddRest(name, r)
+	}
+
+	f, err := NewFailover(p, chain, 2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.now = func() time.Time { return *now }
+
+	return f
+}
+
+func TestFailover_Get(t *testing.T) {
+	now := time.Unix(1747644163, 0)
+	kraken := &mockRestClient{price: 1, err: errUpstream}
+	huobi := &mockRestClient{price: 2}
+
+	f := newTestFailover(t, &now, map[string]RestClient{"kraken": kraken, "huobi": huobi}, "kraken", "huobi")
+
+	for range 2 {
+		got, err := f.Get(context.Background(), "BTC", "USDT")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		if got.Provider != "huobi" || got.Price != 2 {
+			t.Errorf("Get() got = %v, want price from huobi", got)
+		}
+	}
+
+	if h := f.Health()[0]; h.Healthy || h.ConsecutiveErrors != 2 || !h.CoolDownUntil.Equal(now.Add(time.Minute)) {
+		t.Errorf("Health() got = %+v, want kraken on the cool-down", h)
+	}
+
+	if _, err := f.Get(context.Background(), "BTC", "USDT"); err != nil || kraken.calls != 2 {
+		t.Errorf("Get() error = %v, kraken calls = %d, want kraken skipped", err, kraken.calls)
+	}
+
+	now = now.Add(time.Minute)
+	kraken.err = nil
+
+	got, err := f.Get(context.Background(), "BTC", "USDT")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if got.Provider != "kraken" {
+		t.Errorf("Get() provider = %v, want kraken after the cool-down", got.Provider)
+	}
+
+	if h := f.Health()[0]; !h.Healthy || h.ConsecutiveErrors != 0 || !h.LastSuccess.Equal(now) {
+		t.Errorf("Health() got = %+v, want healthy kraken", h)
+	}
+}
+
+func TestFailover_GetAllFailed(t *testing.T) {
+	now := time.Unix(1747644163, 0)
+	kraken := &mockRestClient{err: errUpstream}
+
+	f := newTestFailover(t, &now, map[string]RestClient{"kraken": kraken}, "kraken")
+
+	for range 2 {
+		if _, err := f.Get(context.Background(), "BTC", "USDT"); !errors.Is(err, errUpstream) {
+			t.Errorf("Get() error = %v, wantErr %v", err, errUpstream)
+		}
+	}
+
+	if _, err := f.Get(context.Background(), "BTC", "USDT"); !errors.Is(err, ErrAllProvidersCoolingOff) {
+		t.Errorf("Get() error = %v, wantErr %v", err, ErrAllProvidersCoolingOff)
+	}
+}
+
+func TestNewFailover(t *testing.T) {
+	p := NewProviders("kraken", nil, nil)
+	p.AddRest("kraken", &mockRestClient{})
+
+	if _, err := NewFailover(p, nil, 1, time.Second); !errors.Is(err, ErrEmptyFailoverChain) {
+		t.Errorf("NewFailover() error = %v, wantErr %v", err, ErrEmptyFailoverChain)
+	}
+
+	if _, err := NewFailover(p, []string{"kraken", "huobi"}, 1, time.Second); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("NewFailover() error = %v, wantErr %v", err, ErrUnknownProvider)
+	}
+}
+
+func TestFailover_GetCanceled(t *testing.T) {
+	now := time.Unix(1747644163, 0)
+	kraken := &mockRestClient{err: context.Canceled}
+	huobi := &mockRestClient{price: 2}
+
+	f := newTestFailover(t, &now, map[string]RestClient{"kraken": kraken, "huobi": huobi}, "kraken", "huobi")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for range 2 {
+		if _, err := f.Get(ctx, "BTC", "USDT"); !errors.Is(err, context.Canceled) {
+			t.Errorf("Get() error = %v, wantErr %v", err, context.Canceled)
+		}
+	}
+
+	if h := f.Health()[0]; !h.Healthy || h.ConsecutiveErrors != 0 || huobi.calls != 0 {
+		t.Errorf("Health() got = %+v, huobi calls = %d, want the canceled call not counted", h, huobi.calls)
+	}
+}
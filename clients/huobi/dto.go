@@ -27,6 +27,48 @@ type restTick struct {
 	Ask     []float64 `json:"ask"`
 }
 
+type restTickers struct {
+	Status  string            `json:"status"`
+	ErrCode string            `json:"err-code"`
+	ErrMsg  string            `json:"err-msg"`
+	Ts      int64             `json:"ts"`
+	Data    []restTickersItem `json:"data"`
+}
+
+type restTickersItem struct {
+	Symbol  string  `json:"symbol"`
+	Open    float64 `json:"open"`
+	High    float64 `json:"high"`
+	Low     float64 `json:"low"`
+	Close   float64 `json:"close"`
+	Amount  float64 `json:"amount"`
+	Vol     float64 `json:"vol"`
+	Count   int     `json:"count"`
+	Bid     float64 `json:"bid"`
+	BidSize float64 `json:"bidSize"`
+	Ask     float64 `json:"ask"`
+	AskSize float64 `json:"askSize"`
+}
+
+// restData convert the item of all tickers to the aggregated ticker form
+func (t *restTickersItem) restData(ts int64) *restData {
+	return &restData{
+		Status: "ok",
+		Ts:     ts,
+		Tick: restTick{
+			Open:   t.Open,
+			Close:  t.Close,
+			Low:    t.Low,
+			High:   t.High,
+			Amount: t.Amount,
+			Vol:    t.Vol,
+			Count:  t.Count,
+			Bid:    []float64{t.Bid, t.BidSize},
+			Ask:    []float64{t.Ask, t.AskSize},
+		},
+	}
+}
+
 type wsMessage struct {
 	Id       string `json:"id"`
 	Status   string `json:"status"`
@@ -1,6 +1,7 @@
 package huobi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,11 +11,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/streamdp/ccd/clients"
 	"github.com/streamdp/ccd/config"
 	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
 )
 
 const (
+	Name = "huobi"
+
 	apiUrl = "https://api.huobi.pro"
 
 	// Get Latest Aggregated Ticker https://huobiapi.github.io/docs/spot/v1/en/#get-latest-aggregated-ticker
@@ -22,12 +27,17 @@ const (
 	// Request Parameters "symbol" (all supported trading symbol, e.g. btcusdt, bccbtc. Refer to /v1/common/symbols)
 	latestAggregatedTicker = "/market/detail/merged"
 
-	rateLimit = 500 * time.Millisecond // make max two api calls per second
+	// Get Latest Tickers for All Pairs https://huobiapi.github.io/docs/spot/v1/en/#get-latest-tickers-for-all-pairs
+	// This endpoint retrieves the latest tickers for all supported pairs.
+	allTickers = "/market/tickers"
+
+	rateLimit = 2 // make max two api calls per second
 )
 
 type rest struct {
+	apiUrl  string
 	client  *http.Client
-	limiter *time.Timer
+	limiter *ratelimit.Limiter
 }
 
 var (
@@ -37,28 +47,88 @@ var (
 
 func Init(cfg *config.App) (*rest, error) {
 	return &rest{
-		client: &http.Client{
-			Timeout: cfg.Http.ClientTimeout(),
-		},
-		limiter: time.NewTimer(rateLimit),
+		apiUrl:  apiUrl,
+		client:  clients.NewHttpClient(cfg.Http.ClientTimeout()),
+		limiter: ratelimit.New(cfg.Limits.Rate(Name, rateLimit), cfg.Limits.Budget(Name)),
 	}, nil
 }
 
-func (r *rest) Get(fSym string, tSym string) (*domain.Data, error) {
-	r.limitRate()
+func (r *rest) Get(ctx context.Context, fSym string, tSym string) (*domain.Data, error) {
+	u, err := r.buildURL(fSym, tSym)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build url: %w", err)
+	}
+
+	rawData := &restData{}
+	if err = r.fetch(ctx, u, rawData); err != nil {
+		return nil, err
+	}
+
+	if rawData.Status == "error" {
+		return nil, fmt.Errorf("server error: %v", rawData.ErrMsg)
+	}
+
+	return convertRestDataToDomain(fSym, tSym, rawData)
+}
+
+// GetMany data for several currencies pairs with the single request of the latest tickers for all pairs
+func (r *rest) GetMany(ctx context.Context, pairs []domain.Pair) ([]*domain.Data, error) {
+	u, err := url.Parse(r.apiUrl + allTickers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url: %w", err)
+	}
+
+	rawData := &restTickers{}
+	if err = r.fetch(ctx, u, rawData); err != nil {
+		return nil, err
+	}
+
+	if rawData.Status == "error" {
+		return nil, fmt.Errorf("server error: %v", rawData.ErrMsg)
+	}
+
+	tickers := make(map[string]*restTickersItem, len(rawData.Data))
+	for i := range rawData.Data {
+		tickers[rawData.Data[i].Symbol] = &rawData.Data[i]
+	}
+
+	result := make([]*domain.Data, 0, len(pairs))
+
+	for _, p := range pairs {
+		tick, ok := tickers[buildSymbol(p.From, p.To)]
+		if !ok {
+			continue
+		}
+
+		data, errConvert := convertRestDataToDomain(p.From, p.To, tick.restData(rawData.Ts))
+		if errConvert != nil {
+			continue
+		}
+
+		result = append(result, data)
+	}
+
+	return result, nil
+}
+
+func (r *rest) fetch(ctx context.Context, u *url.URL, v any) error {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return err
+	}
 
 	var (
+		request  *http.Request
 		response *http.Response
 		body     []byte
+		err      error
 	)
 
-	u, err := r.buildURL(fSym, tSym)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build url: %w", err)
+	if request, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil); err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
 	}
 
-	if response, err = r.client.Get(u.String()); err != nil {
-		return nil, fmt.Errorf("failed to fetch data: %w", err)
+	if response, err = r.client.Do(request); err != nil {
+		return fmt.Errorf("failed to fetch data: %w", err)
 	}
 
 	defer func(Body io.ReadCloser) {
@@ -66,55 +136,56 @@ func (r *rest) Get(fSym string, tSym string) (*domain.Data, error) {
 	}(response.Body)
 
 	if response.StatusCode != http.StatusOK {
-		return nil, errWrongStatusCode
+		return errWrongStatusCode
 	}
 
 	if body, err = io.ReadAll(response.Body); err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return fmt.Errorf("failed to read response: %w", err)
 	}
 
-	rawData := &restData{}
-	if err = json.Unmarshal(body, rawData); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	if err = json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	if rawData.Status == "error" {
-		return nil, fmt.Errorf("server error: %v", rawData.ErrMsg)
-	}
-
-	return convertRestDataToDomain(fSym, tSym, rawData)
+	return nil
 }
 
 func (r *rest) Close() error {
-	if r.limiter != nil {
-		r.limiter.Stop()
-	}
-
 	return nil
 }
 
-func (r *rest) limitRate() {
-	<-r.limiter.C
-	r.limiter.Reset(rateLimit)
+// Budget return the state of the monthly budget of the upstream calls
+func (r *rest) Budget() ratelimit.Budget {
+	return r.limiter.Budget()
+}
+
+// RestoreBudget used before the restart
+func (r *rest) RestoreBudget(used int64, resetAt time.Time) {
+	r.limiter.Restore(used, resetAt)
 }
 
 func (r *rest) buildURL(fSym string, tSym string) (*url.URL, error) {
-	u, err := url.Parse(apiUrl + latestAggregatedTicker)
+	u, err := url.Parse(r.apiUrl + latestAggregatedTicker)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse url: %w", err)
 	}
 
-	if strings.ToLower(tSym) == "usd" {
-		tSym = "usdt"
-	}
-
 	query := u.Query()
-	query.Set("symbol", strings.ToLower(fSym+tSym))
+	query.Set("symbol", buildSymbol(fSym, tSym))
 	u.RawQuery = query.Encode()
 
 	return u, nil
 }
 
+// buildSymbol of the huobi trading pair, huobi has no USD pairs, so USD is replaced with USDT
+func buildSymbol(fSym string, tSym string) string {
+	if strings.ToLower(tSym) == "usd" {
+		tSym = "usdt"
+	}
+
+	return strings.ToLower(fSym + tSym)
+}
+
 func convertRestDataToDomain(from, to string, d *restData) (*domain.Data, error) {
 	if d == nil {
 		return nil, errEmptyData
@@ -144,6 +215,7 @@ func convertRestDataToDomain(from, to string, d *restData) (*domain.Data, error)
 	return &domain.Data{
 		FromSymbol:     from,
 		ToSymbol:       to,
+		Provider:       Name,
 		Open24Hour:     d.Tick.Open,
 		Volume24Hour:   d.Tick.Amount,
 		Low24Hour:      d.Tick.Low,
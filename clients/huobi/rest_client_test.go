@@ -1,11 +1,15 @@
 package huobi
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"testing"
 
 	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
 )
 
 func Test_convertRestDataToDomain(t *testing.T) {
@@ -32,6 +36,7 @@ func Test_convertRestDataToDomain(t *testing.T) {
 			want: &domain.Data{
 				FromSymbol: "BTC",
 				ToSymbol:   "USDT",
+				Provider:   Name,
 				DisplayDataRaw: "{\"from_symbol\":\"BTC\",\"to_symbol\":\"USDT\",\"change_24_hour\":0," +
 					"\"changepct_24_hour\":0,\"open_24_hour\":0,\"volume_24_hour\":0,\"volume_24_hour_to\":0," +
 					"\"low_24_hour\":0,\"high_24_hour\":0,\"price\":0,\"supply\":0,\"mkt_cap\":0,\"last_update\":0}",
@@ -67,6 +72,7 @@ func Test_convertRestDataToDomain(t *testing.T) {
 			want: &domain.Data{
 				FromSymbol:   "BTC",
 				ToSymbol:     "USDT",
+				Provider:     Name,
 				Open24Hour:   60867.47,
 				Volume24Hour: 666.1991214442407,
 				Low24Hour:    60867.47,
@@ -132,7 +138,7 @@ func Test_rest_buildURL(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			h := &rest{}
+			h := &rest{apiUrl: apiUrl}
 
 			gotU, err := h.buildURL(tt.args.fSym, tt.args.tSym)
 			if (err != nil) != tt.wantErr {
@@ -147,3 +153,65 @@ func Test_rest_buildURL(t *testing.T) {
 		})
 	}
 }
+
+func Test_rest_GetMany(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		pairs    []domain.Pair
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "pairs are picked from all tickers",
+			response: `{"status":"ok","ts":1746439219990,"data":[` +
+				`{"symbol":"btcusdt","open":95476.1,"high":95761.3,"low":93570.1,"close":94183.3,"bid":94183.2},` +
+				`{"symbol":"ethusdt","open":1795.1,"high":1810.3,"low":1770.1,"close":1800.5,"bid":1800.4}]}`,
+			pairs: []domain.Pair{{From: "BTC", To: "USDT"}, {From: "XYZ", To: "USDT"}, {From: "ETH", To: "USD"}},
+			want:  []string{"BTC/USDT", "ETH/USD"},
+		},
+		{
+			name:     "server error",
+			response: `{"status":"error","err-code":"bad-request","err-msg":"invalid request"}`,
+			pairs:    []domain.Pair{{From: "BTC", To: "USDT"}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != allTickers {
+					w.WriteHeader(http.StatusNotFound)
+
+					return
+				}
+
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			r := &rest{
+				apiUrl:  srv.URL,
+				client:  srv.Client(),
+				limiter: ratelimit.New(0, 0),
+			}
+			defer func() { _ = r.Close() }()
+
+			data, err := r.GetMany(context.Background(), tt.pairs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetMany() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+
+			var got []string
+			for _, d := range data {
+				got = append(got, d.FromSymbol+"/"+d.ToSymbol)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMany() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -24,7 +24,7 @@ func InitWs(
 	cfg *config.Http,
 	pipe ...chan *domain.Data,
 ) *wsclient.Ws {
-	w := wsclient.New(ctx, "wss://api.huobi.pro/ws", sessionRepo, l, cfg)
+	w := wsclient.New(ctx, Name, "wss://api.huobi.pro/ws", sessionRepo, l, cfg)
 
 	w.ChannelNameBuilder = buildChannelName
 
@@ -195,6 +195,7 @@ func convertWsDataToDomain(from, to string, d *wsData) (*domain.Data, error) {
 	return &domain.Data{
 		FromSymbol:     from,
 		ToSymbol:       to,
+		Provider:       Name,
 		Open24Hour:     d.Tick.Open,
 		Volume24Hour:   d.Tick.Amount,
 		Low24Hour:      d.Tick.Low,
@@ -0,0 +1,74 @@
+package clients
+
+import (
+	"context"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/metrics"
+	"github.com/streamdp/ccd/pkg/ratelimit"
+	"github.com/streamdp/ccd/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// instrumented rest client records the latency and errors of the upstream calls and traces them
+type instrumented struct {
+	RestClient
+
+	provider string
+}
+
+func instrument(provider string, r RestClient) *instrumented {
+	return &instrumented{RestClient: r, provider: provider}
+}
+
+func (i *instrumented) Get(ctx context.Context, from string, to string) (data *domain.Data, err error) {
+	ctx, span := tracing.Start(ctx, "provider.Get",
+		attribute.String("provider", i.provider),
+		attribute.String("pair", from+"/"+to),
+	)
+	defer func() { tracing.End(span, err) }()
+	defer i.observe("get", time.Now(), &err)
+
+	return i.RestClient.Get(ctx, from, to)
+}
+
+func (i *instrumented) GetMany(ctx context.Context, pairs []domain.Pair) (data []*domain.Data, err error) {
+	ctx, span := tracing.Start(ctx, "provider.GetMany",
+		attribute.String("provider", i.provider),
+		attribute.Int("pairs", len(pairs)),
+	)
+	defer func() { tracing.End(span, err) }()
+	defer i.observe("get_many", time.Now(), &err)
+
+	return i.RestClient.GetMany(ctx, pairs)
+}
+
+func (i *instrumented) observe(method string, start time.Time, err *error) {
+	metrics.ProviderRequestDuration.WithLabelValues(i.provider, method).Observe(time.Since(start).Seconds())
+
+	if *err != nil {
+		metrics.ProviderRequestErrors.WithLabelValues(i.provider, method).Inc()
+	}
+}
+
+// budget of the rest client upstream calls, when the client limits them
+func budget(r RestClient) (ratelimit.Budget, bool) {
+	b, ok := budgetReporter(r)
+	if !ok {
+		return ratelimit.Budget{}, false
+	}
+
+	return b.Budget(), true
+}
+
+// budgetReporter of the rest client, when the client limits the upstream calls
+func budgetReporter(r RestClient) (BudgetReporter, bool) {
+	if i, ok := r.(*instrumented); ok {
+		r = i.RestClient
+	}
+
+	b, ok := r.(BudgetReporter)
+
+	return b, ok
+}
@@ -0,0 +1,52 @@
+package clients
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/metrics"
+	"github.com/streamdp/ccd/pkg/ratelimit"
+)
+
+func Test_instrumented(t *testing.T) {
+	r := &mockRestClient{price: 1}
+	i := instrument("test-instrumented", r)
+
+	getErrors := metrics.ProviderRequestErrors.WithLabelValues("test-instrumented", "get")
+
+	if _, err := i.Get(context.Background(), "BTC", "USDT"); err != nil {
+		t.Fatal(err)
+	}
+
+	r.err = errUpstream
+
+	if _, err := i.Get(context.Background(), "BTC", "USDT"); err == nil {
+		t.Fatal("Get() error = nil, want error")
+	}
+
+	if _, err := i.GetMany(context.Background(), []domain.Pair{{From: "BTC", To: "USDT"}}); err == nil {
+		t.Fatal("GetMany() error = nil, want error")
+	}
+
+	if got := testutil.ToFloat64(getErrors); got != 1 {
+		t.Errorf("get errors = %v, want 1", got)
+	}
+
+	if got := testutil.CollectAndCount(metrics.ProviderRequestDuration, "ccd_provider_request_duration_seconds"); got < 2 {
+		t.Errorf("request duration series = %v, want get and get_many", got)
+	}
+}
+
+func Test_budget(t *testing.T) {
+	want := ratelimit.Budget{Limit: 10, Remaining: 10, SlowDown: 1}
+
+	if got, ok := budget(instrument("kraken", &mockBudgetClient{budget: want})); !ok || got != want {
+		t.Errorf("budget() = %v, %v, want %v", got, ok, want)
+	}
+
+	if _, ok := budget(instrument("kraken", &mockRestClient{})); ok {
+		t.Error("budget() ok = true, want false for the client without the budget")
+	}
+}
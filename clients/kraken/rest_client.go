@@ -1,6 +1,7 @@
 package kraken
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,11 +12,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/streamdp/ccd/clients"
 	"github.com/streamdp/ccd/config"
 	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
 )
 
 const (
+	Name = "kraken"
+
 	apiUrl = "https://api.kraken.com"
 
 	// Get Ticker Information https://docs.kraken.com/api/docs/rest-api/get-ticker-information
@@ -26,42 +31,105 @@ const (
 	// XBTUSD, WBTCUSD. Refer to /0/public/Assets)
 	tickerInformation = "/0/public/Ticker"
 
-	rateLimit = 500 * time.Millisecond // make max two api calls per second
+	rateLimit = 2 // make max two api calls per second
 )
 
 type rest struct {
+	apiUrl  string
 	client  *http.Client
-	limiter *time.Timer
+	limiter *ratelimit.Limiter
 }
 
 var (
 	errWrongStatusCode = errors.New("wrong response status code")
 	errEmptyData       = errors.New("empty data")
+	errRejected        = errors.New("request rejected")
 )
 
 func Init(cfg *config.App) (*rest, error) {
 	return &rest{
-		client: &http.Client{
-			Timeout: cfg.Http.ClientTimeout(),
-		},
-		limiter: time.NewTimer(rateLimit),
+		apiUrl:  apiUrl,
+		client:  clients.NewHttpClient(cfg.Http.ClientTimeout()),
+		limiter: ratelimit.New(cfg.Limits.Rate(Name, rateLimit), cfg.Limits.Budget(Name)),
 	}, nil
 }
 
-func (r *rest) Get(fSym string, tSym string) (*domain.Data, error) {
-	r.limitRate()
+func (r *rest) Get(ctx context.Context, fSym string, tSym string) (*domain.Data, error) {
+	u, err := r.buildURL(fSym, tSym)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build url: %w", err)
+	}
+
+	rawData, err := r.fetch(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertRestDataToDomain(fSym, tSym, rawData, time.Now().UTC().UnixMilli())
+}
+
+// GetMany data for several currencies pairs with the single request, the response is keyed by the kraken pair names,
+// so the requested pairs are matched with them by the asset codes. Kraken rejects the whole request when any of the
+// pairs is unknown, so the pairs are fetched one by one then
+func (r *rest) GetMany(ctx context.Context, pairs []domain.Pair) ([]*domain.Data, error) {
+	names := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		names = append(names, strings.ToLower(p.From+p.To))
+	}
+
+	u, err := r.buildPairsURL(strings.Join(names, ","))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build url: %w", err)
+	}
+
+	rawData, err := r.fetch(ctx, u)
+	if errors.Is(err, errRejected) {
+		return clients.GetEach(ctx, r, pairs)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result     = make([]*domain.Data, 0, len(pairs))
+		lastUpdate = time.Now().UTC().UnixMilli()
+	)
+
+	for _, p := range pairs {
+		tick, ok := pickTicker(p.From, p.To, rawData.Result)
+		if !ok {
+			continue
+		}
+
+		data, errConvert := convertTickerToDomain(p.From, p.To, &tick, lastUpdate)
+		if errConvert != nil {
+			continue
+		}
+
+		result = append(result, data)
+	}
+
+	return result, nil
+}
+
+func (r *rest) fetch(ctx context.Context, u *url.URL) (*restData, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
 
 	var (
+		request  *http.Request
 		response *http.Response
 		body     []byte
+		err      error
 	)
 
-	u, err := r.buildURL(fSym, tSym)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build url: %w", err)
+	if request, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
 	}
 
-	if response, err = r.client.Get(u.String()); err != nil {
+	if response, err = r.client.Do(request); err != nil {
 		return nil, fmt.Errorf("failed to fetch data: %w", err)
 	}
 
@@ -85,38 +153,59 @@ func (r *rest) Get(fSym string, tSym string) (*domain.Data, error) {
 	}
 
 	if len(rawData.Error) != 0 {
+		if queryError(rawData.Error) {
+			return nil, fmt.Errorf("%w: server error: %v", errRejected, rawData.Error)
+		}
+
 		return nil, fmt.Errorf("server error: %v", rawData.Error)
 	}
 
-	return convertRestDataToDomain(fSym, tSym, rawData, time.Now().UTC().UnixMilli())
+	return rawData, nil
 }
 
 func (r *rest) Close() error {
-	if r.limiter != nil {
-		r.limiter.Stop()
-	}
-
 	return nil
 }
 
-func (r *rest) limitRate() {
-	<-r.limiter.C
-	r.limiter.Reset(rateLimit)
+// Budget return the state of the monthly budget of the upstream calls
+func (r *rest) Budget() ratelimit.Budget {
+	return r.limiter.Budget()
+}
+
+// RestoreBudget used before the restart
+func (r *rest) RestoreBudget(used int64, resetAt time.Time) {
+	r.limiter.Restore(used, resetAt)
 }
 
 func (r *rest) buildURL(fSym string, tSym string) (*url.URL, error) {
-	u, err := url.Parse(apiUrl + tickerInformation)
+	return r.buildPairsURL(strings.ToLower(fSym + tSym))
+}
+
+// buildPairsURL of the ticker information for the comma separated list of pairs
+func (r *rest) buildPairsURL(pair string) (*url.URL, error) {
+	u, err := url.Parse(r.apiUrl + tickerInformation)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse url: %w", err)
 	}
 
 	query := u.Query()
-	query.Set("pair", strings.ToLower(fSym+tSym))
+	query.Set("pair", pair)
 	u.RawQuery = query.Encode()
 
 	return u, nil
 }
 
+// queryError reports whether kraken refused the request parameters, like the unknown asset pair, rather than failed
+func queryError(errs []any) bool {
+	for _, e := range errs {
+		if s, ok := e.(string); ok && strings.HasPrefix(s, "EQuery:") {
+			return true
+		}
+	}
+
+	return false
+}
+
 func convertRestDataToDomain(from, to string, d *restData, lastUpdate int64) (*domain.Data, error) {
 	if d == nil || len(d.Result) == 0 {
 		return nil, errEmptyData
@@ -127,6 +216,40 @@ func convertRestDataToDomain(from, to string, d *restData, lastUpdate int64) (*d
 		tick = v
 	}
 
+	return convertTickerToDomain(from, to, &tick, lastUpdate)
+}
+
+// pickTicker of the requested pair from the response keyed by kraken pair names like "XXBTZUSD" or "XBTUSDT"
+func pickTicker(from, to string, result map[string]restTickerInfo) (restTickerInfo, bool) {
+	f, t := krakenAsset(from), krakenAsset(to)
+
+	for _, name := range []string{f + t, "X" + f + "Z" + t, "X" + f + "X" + t, "X" + f + t, f + "Z" + t} {
+		if tick, ok := result[name]; ok {
+			return tick, true
+		}
+	}
+
+	return restTickerInfo{}, false
+}
+
+// krakenAsset return the kraken code of the asset, kraken names bitcoin and dogecoin in its own way
+func krakenAsset(symbol string) string {
+	switch symbol = strings.ToUpper(symbol); symbol {
+	case "BTC":
+		return "XBT"
+	case "DOGE":
+		return "XDG"
+	}
+
+	return symbol
+}
+
+func convertTickerToDomain(from, to string, tick *restTickerInfo, lastUpdate int64) (*domain.Data, error) {
+	if tick == nil || len(tick.P) == 0 || len(tick.V) < 2 || len(tick.L) < 2 || len(tick.H) < 2 ||
+		len(tick.T) < 2 {
+		return nil, errEmptyData
+	}
+
 	open24Hour, _ := strconv.ParseFloat(tick.O, 64)
 	volume24Hour, _ := strconv.ParseFloat(tick.V[1], 64)
 	low24Hour, _ := strconv.ParseFloat(tick.L[1], 64)
@@ -151,6 +274,7 @@ func convertRestDataToDomain(from, to string, d *restData, lastUpdate int64) (*d
 	return &domain.Data{
 		FromSymbol:     from,
 		ToSymbol:       to,
+		Provider:       Name,
 		Open24Hour:     open24Hour,
 		Volume24Hour:   volume24Hour,
 		Low24Hour:      low24Hour,
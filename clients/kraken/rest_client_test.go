@@ -1,11 +1,18 @@
 package kraken
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
 )
 
 func Test_convertRestDataToDomain(t *testing.T) {
@@ -69,6 +76,7 @@ func Test_convertRestDataToDomain(t *testing.T) {
 			want: &domain.Data{
 				FromSymbol:   "XRP",
 				ToSymbol:     "USDT",
+				Provider:     Name,
 				Open24Hour:   2.15556,
 				Volume24Hour: 314445.90752978,
 				Low24Hour:    2.13406,
@@ -134,7 +142,7 @@ func Test_rest_buildURL(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			h := &rest{}
+			h := &rest{apiUrl: apiUrl}
 
 			gotU, err := h.buildURL(tt.args.fSym, tt.args.tSym)
 
@@ -150,3 +158,126 @@ func Test_rest_buildURL(t *testing.T) {
 		})
 	}
 }
+
+func Test_pickTicker(t *testing.T) {
+	result := map[string]restTickerInfo{
+		"XXBTZUSD": {O: "1"},
+		"XBTUSDT":  {O: "2"},
+		"XETHZEUR": {O: "3"},
+		"SOLUSD":   {O: "4"},
+	}
+
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		want   restTickerInfo
+		wantOk bool
+	}{
+		{name: "legacy pair name", from: "BTC", to: "USD", want: restTickerInfo{O: "1"}, wantOk: true},
+		{name: "plain pair name", from: "btc", to: "usdt", want: restTickerInfo{O: "2"}, wantOk: true},
+		{name: "fiat quote", from: "ETH", to: "EUR", want: restTickerInfo{O: "3"}, wantOk: true},
+		{name: "new asset", from: "SOL", to: "USD", want: restTickerInfo{O: "4"}, wantOk: true},
+		{name: "missing pair", from: "DOGE", to: "USD", want: restTickerInfo{}, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pickTicker(tt.from, tt.to, result)
+			if ok != tt.wantOk || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pickTicker() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_rest_GetMany(t *testing.T) {
+	const ticker = `{"a":["2.17935000","459","459.000"],"b":["2.17896000","76","76.000"],` +
+		`"c":["2.18000000","13.57465600"],"v":["139560.82655307","314445.90752978"],` +
+		`"p":["2.16724243","2.17213922"],"t":[769,1599],"l":["2.13406000","2.13406000"],` +
+		`"h":["2.19821000","2.20396000"],"o":"2.15556000"}`
+
+	names := map[string]string{
+		"xrpusdt": "XRPUSDT",
+		"btcusd":  "XXBTZUSD",
+	}
+
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		result := make([]string, 0)
+		for _, p := range strings.Split(r.URL.Query().Get("pair"), ",") {
+			name, ok := names[p]
+			if !ok {
+				_, _ = w.Write([]byte(`{"error":["EQuery:Unknown asset pair"]}`))
+
+				return
+			}
+
+			result = append(result, `"`+name+`":`+ticker)
+		}
+
+		_, _ = w.Write([]byte(`{"error":[],"result":{` + strings.Join(result, ",") + `}}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		pairs     []domain.Pair
+		want      []string
+		wantCalls int32
+		wantErr   error
+	}{
+		{
+			name:      "single request",
+			pairs:     []domain.Pair{{From: "XRP", To: "USDT"}, {From: "BTC", To: "USD"}},
+			want:      []string{"XRP/USDT", "BTC/USD"},
+			wantCalls: 1,
+		},
+		{
+			name:      "unknown pair is fetched one by one",
+			pairs:     []domain.Pair{{From: "XRP", To: "USDT"}, {From: "XYZ", To: "USD"}, {From: "BTC", To: "USD"}},
+			want:      []string{"XRP/USDT", "BTC/USD"},
+			wantCalls: 4,
+		},
+		{
+			name:      "all pairs are unknown",
+			pairs:     []domain.Pair{{From: "XYZ", To: "USD"}, {From: "BTC", To: "XYZ"}},
+			wantCalls: 3,
+			wantErr:   errRejected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+
+			r := &rest{
+				apiUrl:  srv.URL,
+				client:  srv.Client(),
+				limiter: ratelimit.New(0, 0),
+			}
+			defer func() { _ = r.Close() }()
+
+			data, err := r.GetMany(context.Background(), tt.pairs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetMany() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+
+			var got []string
+			for _, d := range data {
+				got = append(got, d.FromSymbol+"/"+d.ToSymbol)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMany() got = %v, want %v", got, tt.want)
+			}
+
+			if n := calls.Load(); n != tt.wantCalls {
+				t.Errorf("GetMany() calls = %d, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
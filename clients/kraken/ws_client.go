@@ -25,7 +25,7 @@ func InitWs(
 	cfg *config.Http,
 	pipe ...chan *domain.Data,
 ) *wsclient.Ws {
-	w := wsclient.New(ctx, "wss://ws.kraken.com/v2", sessionRepo, l, cfg)
+	w := wsclient.New(ctx, Name, "wss://ws.kraken.com/v2", sessionRepo, l, cfg)
 
 	w.ChannelNameBuilder = buildChannelName
 
@@ -203,6 +203,7 @@ func convertWsDataToDomain(from, to string, tick *wsTickerInfo, lastUpdate int64
 	return &domain.Data{
 		FromSymbol:      from,
 		ToSymbol:        to,
+		Provider:        Name,
 		Change24Hour:    tick.Change,
 		ChangePct24Hour: tick.ChangePct,
 		Volume24Hour:    tick.Volume,
@@ -82,6 +82,7 @@ func Test_convertWsDataToDomain(t *testing.T) {
 				Id:              0,
 				FromSymbol:      "btc",
 				ToSymbol:        "usdt",
+				Provider:        Name,
 				Change24Hour:    -1292.8,
 				ChangePct24Hour: -1.35,
 				Open24Hour:      0,
@@ -103,7 +104,7 @@ func Test_convertWsDataToDomain(t *testing.T) {
 			args: args{
 				ticker: &wsTickerInfo{},
 			},
-			want: &domain.Data{DisplayDataRaw: "{\"from_symbol\":\"\",\"to_symbol\":\"\",\"change_24_hour\":0," +
+			want: &domain.Data{Provider: Name, DisplayDataRaw: "{\"from_symbol\":\"\",\"to_symbol\":\"\",\"change_24_hour\":0," +
 				"\"changepct_24_hour\":0,\"open_24_hour\":0,\"volume_24_hour\":0,\"volume_24_hour_to\":0," +
 				"\"low_24_hour\":0,\"high_24_hour\":0,\"price\":0,\"supply\":0,\"mkt_cap\":0,\"last_update\":0}"},
 		},
@@ -0,0 +1,224 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+// WsSessionPrefix distinguishes ws subscriptions from the rest puller tasks in the session store
+const WsSessionPrefix = "WS"
+
+var ErrUnknownProvider = errors.New("unknown data provider")
+
+// Providers keeps rest and ws clients of all enabled data providers, requests without the provider name are routed
+// to the default one
+type Providers struct {
+	defaultProvider string
+
+	rest map[string]RestClient
+	ws   map[string]WsClient
+
+	l           *log.Logger
+	sessionRepo SessionRepo
+}
+
+func NewProviders(defaultProvider string, l *log.Logger, sessionRepo SessionRepo) *Providers {
+	return &Providers{
+		defaultProvider: strings.ToLower(defaultProvider),
+		rest:            make(map[string]RestClient),
+		ws:              make(map[string]WsClient),
+		l:               l,
+		sessionRepo:     sessionRepo,
+	}
+}
+
+// AddRest client of the data provider
+func (p *Providers) AddRest(provider string, r RestClient) {
+	provider = strings.ToLower(provider)
+	p.rest[provider] = instrument(provider, r)
+}
+
+// AddWs client of the data provider
+func (p *Providers) AddWs(provider string, w WsClient) {
+	p.ws[strings.ToLower(provider)] = w
+}
+
+// Default return the name of the default data provider
+func (p *Providers) Default() string {
+	return p.defaultProvider
+}
+
+// Names return sorted names of all enabled data providers
+func (p *Providers) Names() []string {
+	return slices.Sorted(maps.Keys(p.rest))
+}
+
+// IsPresent check the data provider is enabled, the empty name stands for the default provider
+func (p *Providers) IsPresent(provider string) bool {
+	_, ok := p.rest[p.name(provider)]
+
+	return ok
+}
+
+// IsWsPresent check the data provider streams the updates over ws, the consolidated price is pulled over rest only
+func (p *Providers) IsWsPresent(provider string) bool {
+	_, ok := p.ws[p.name(provider)]
+
+	return ok
+}
+
+// Rest return the rest client of the selected data provider
+func (p *Providers) Rest(provider string) (RestClient, error) {
+	r, ok := p.rest[p.name(provider)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, provider)
+	}
+
+	return r, nil
+}
+
+// RestClients return rest clients of all enabled data providers
+func (p *Providers) RestClients() map[string]RestClient {
+	return maps.Clone(p.rest)
+}
+
+// Ws return the ws client of the selected data provider
+func (p *Providers) Ws(provider string) (WsClient, error) {
+	w, ok := p.ws[p.name(provider)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, provider)
+	}
+
+	return w, nil
+}
+
+// Subscribe to the currencies pair updates of the selected data provider
+func (p *Providers) Subscribe(ctx context.Context, provider, from, to string) error {
+	w, err := p.Ws(provider)
+	if err != nil {
+		return err
+	}
+
+	if err = w.Subscribe(ctx, from, to); err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	return nil
+}
+
+// Unsubscribe from the currencies pair updates of the selected data provider
+func (p *Providers) Unsubscribe(ctx context.Context, provider, from, to string) error {
+	w, err := p.Ws(provider)
+	if err != nil {
+		return err
+	}
+
+	if err = w.Unsubscribe(ctx, from, to); err != nil {
+		return fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+
+	return nil
+}
+
+// ListSubscriptions return ws subscriptions grouped by the data provider
+func (p *Providers) ListSubscriptions() map[string]domain.Subscriptions {
+	s := make(map[string]domain.Subscriptions, len(p.ws))
+	for name, w := range p.ws {
+		s[name] = w.ListSubscriptions()
+	}
+
+	return s
+}
+
+// RestoreLastSession get the last session from the session store and restore ws subscriptions of every data
+// provider, legacy subscriptions saved without the provider name are restored by the default one
+func (p *Providers) RestoreLastSession(ctx context.Context) error {
+	if p.sessionRepo == nil {
+		return nil
+	}
+
+	ses, err := p.sessionRepo.GetSession(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+
+	for k := range ses {
+		provider, from, to, ok := ParseWsSessionName(k)
+		if !ok {
+			continue
+		}
+
+		if err = p.Subscribe(ctx, provider, from, to); err != nil {
+			p.l.Printf("failed to restore %s subscription: %v", k, err)
+
+			continue
+		}
+
+		if provider == "" {
+			if err = p.sessionRepo.RemoveTask(ctx, k); err != nil {
+				p.l.Println(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Close rest clients of all data providers
+func (p *Providers) Close() error {
+	var errs []error
+
+	for name, r := range p.rest {
+		if err := r.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s rest client: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// CloseWs clients of all data providers, the subscriptions are kept in the session store
+func (p *Providers) CloseWs(ctx context.Context) error {
+	var errs []error
+
+	for name, w := range p.ws {
+		if err := w.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s ws client: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *Providers) name(provider string) string {
+	if provider == "" {
+		return p.defaultProvider
+	}
+
+	return strings.ToLower(provider)
+}
+
+// ParseWsSessionName split the ws session name "WS:PROVIDER:FROM:TO" to the provider and currencies pair, the
+// provider is empty for the legacy names "WS:FROM:TO" saved before several data providers were supported
+func ParseWsSessionName(session string) (string, string, string, bool) {
+	parts := strings.Split(session, ":")
+	if len(parts) < 3 || parts[0] != WsSessionPrefix {
+		return "", "", "", false
+	}
+
+	switch len(parts) {
+	case 3:
+		return "", parts[1], parts[2], true
+	case 4:
+		return strings.ToLower(parts[1]), parts[2], parts[3], true
+	}
+
+	return "", "", "", false
+}
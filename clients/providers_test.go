@@ -0,0 +1,51 @@
+package clients
+
+import (
+	"testing"
+)
+
+func TestParseWsSessionName(t *testing.T) {
+	tests := []struct {
+		name         string
+		session      string
+		wantProvider string
+		wantFrom     string
+		wantTo       string
+		wantOk       bool
+	}{
+		{
+			name:         "session name with provider",
+			session:      "WS:KRAKEN:BTC:USDT",
+			wantProvider: "kraken",
+			wantFrom:     "BTC",
+			wantTo:       "USDT",
+			wantOk:       true,
+		},
+		{
+			name:     "legacy session name",
+			session:  "WS:BTC:USDT",
+			wantFrom: "BTC",
+			wantTo:   "USDT",
+			wantOk:   true,
+		},
+		{
+			name:    "rest task name",
+			session: "HUOBI:BTC:USDT",
+			wantOk:  false,
+		},
+		{
+			name:    "legacy rest task name",
+			session: "BTC:USDT",
+			wantOk:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotProvider, gotFrom, gotTo, gotOk := ParseWsSessionName(tt.session)
+			if gotProvider != tt.wantProvider || gotFrom != tt.wantFrom || gotTo != tt.wantTo || gotOk != tt.wantOk {
+				t.Errorf("ParseWsSessionName() = %v, %v, %v, %v, want %v, %v, %v, %v",
+					gotProvider, gotFrom, gotTo, gotOk, tt.wantProvider, tt.wantFrom, tt.wantTo, tt.wantOk)
+			}
+		})
+	}
+}
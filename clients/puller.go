@@ -5,9 +5,9 @@ import (
 	"fmt"
 	"log"
 	"maps"
+	"slices"
 	"strings"
 	"sync"
-	"sync/atomic"
 
 	"github.com/streamdp/ccd/config"
 	"github.com/streamdp/ccd/domain"
@@ -15,78 +15,105 @@ import (
 
 type restPuller struct {
 	tasks       Tasks
+	groups      map[string]*taskGroup
 	l           *log.Logger
 	sessionRepo SessionRepo
 	dataPipe    []chan *domain.Data
-	client      RestClient
+	providers   *Providers
+	retry       RetryPolicy
 	pullerMu    sync.RWMutex
 }
 
-func NewPuller(r RestClient, l *log.Logger, sessionRepo SessionRepo, dataPipe ...chan *domain.Data) *restPuller {
+func NewPuller(
+	providers *Providers,
+	l *log.Logger,
+	sessionRepo SessionRepo,
+	retry RetryPolicy,
+	dataPipe ...chan *domain.Data,
+) *restPuller {
 	return &restPuller{
 		tasks:       Tasks{},
+		groups:      make(map[string]*taskGroup),
 		l:           l,
 		sessionRepo: sessionRepo,
 		dataPipe:    dataPipe,
-		client:      r,
+		providers:   providers,
+		retry:       retry,
 	}
 }
 
 // ListTasks return all tasks
 func (p *restPuller) ListTasks() Tasks {
-	var t = make(Tasks, len(p.tasks))
-
 	p.pullerMu.RLock()
+	t := make(Tasks, len(p.tasks))
 	maps.Copy(t, p.tasks)
 	p.pullerMu.RUnlock()
 
 	return t
 }
 
-// Task return task with selected currencies pair, if possible
-func (p *restPuller) Task(from, to string) *Task {
-	return p.task(buildTaskName(from, to))
+// Task return task with selected data provider and currencies pair, if possible
+func (p *restPuller) Task(provider, from, to string) *Task {
+	return p.task(buildTaskName(p.provider(provider), from, to))
 }
 
-// AddTask to collect data for the selected currency pair to the puller
-func (p *restPuller) AddTask(ctx context.Context, from string, to string, interval int64) *Task {
-	name := buildTaskName(from, to)
+// AddTask to collect data for the selected data provider and currency pair to the puller
+func (p *restPuller) AddTask(ctx context.Context, provider, from, to string, interval int64) (*Task, error) {
+	provider = p.provider(provider)
+
+	r, err := p.providers.Rest(provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add task: %w", err)
+	}
+
+	name := buildTaskName(provider, from, to)
+	t := p.newTask(r, provider, from, to, interval)
 
-	t := p.newTask(from, to, interval)
-	t.run(p.client, p.l, p.dataPipe)
+	var left *taskGroup
 
 	p.pullerMu.Lock()
+	if old, ok := p.tasks[name]; ok {
+		left = p.leaveGroup(name, old)
+	}
+
 	p.tasks[name] = t
+	p.joinGroup(r, name, t)
 	p.pullerMu.Unlock()
 
-	if err := p.sessionRepo.AddTask(ctx, name, interval); err != nil {
+	left.wait()
+
+	if err = p.sessionRepo.AddTask(ctx, name, interval); err != nil {
 		p.l.Println(err)
 	}
 
-	return t
+	return t, nil
 }
 
-// RemoveTask from the puller by the selected currency pair
-func (p *restPuller) RemoveTask(ctx context.Context, from string, to string) {
-	name := buildTaskName(from, to)
+// RemoveTask from the puller by the selected data provider and currency pair
+func (p *restPuller) RemoveTask(ctx context.Context, provider, from, to string) {
+	name := buildTaskName(p.provider(provider), from, to)
+
+	p.pullerMu.Lock()
+	t, ok := p.tasks[name]
+	if !ok {
+		p.pullerMu.Unlock()
 
-	t := p.task(name)
-	if t == nil {
 		return
 	}
 
-	t.close()
-
-	p.pullerMu.Lock()
+	left := p.leaveGroup(name, t)
 	delete(p.tasks, name)
 	p.pullerMu.Unlock()
 
+	left.wait()
+
 	if err := p.sessionRepo.RemoveTask(ctx, name); err != nil {
 		p.l.Print(err)
 	}
 }
 
-// RestoreLastSession get the last session from the session store and restore it
+// RestoreLastSession get the last session from the session store and restore it, legacy tasks saved without the
+// provider name are restored by the default data provider
 func (p *restPuller) RestoreLastSession(ctx context.Context) error {
 	if p.sessionRepo == nil {
 		return nil
@@ -98,42 +125,143 @@ func (p *restPuller) RestoreLastSession(ctx context.Context) error {
 	}
 
 	for k, v := range ses {
-		if pair := strings.Split(k, ":"); len(pair) == 2 {
-			from, to := pair[0], pair[1]
-			p.AddTask(ctx, from, to, v)
+		provider, from, to, ok := parseTaskName(k)
+		if !ok {
+			continue
+		}
+
+		if _, err = p.AddTask(ctx, provider, from, to, v); err != nil {
+			p.l.Printf("failed to restore %s task: %v", k, err)
+
+			continue
+		}
+
+		if provider == "" {
+			if err = p.sessionRepo.RemoveTask(ctx, k); err != nil {
+				p.l.Println(err)
+			}
 		}
 	}
 
 	return nil
 }
 
+// UpdateTask interval, the task is moved to the group of tasks pulled with the new interval
 func (p *restPuller) UpdateTask(ctx context.Context, t *Task, interval int64) *Task {
-	atomic.StoreInt64(&t.Interval, interval)
+	name := buildTaskName(t.Provider, t.From, t.To)
+
+	r, err := p.providers.Rest(t.Provider)
+	if err != nil {
+		p.l.Println(err)
 
-	if err := p.sessionRepo.UpdateTask(ctx, buildTaskName(t.From, t.To), interval); err != nil {
+		return t
+	}
+
+	p.pullerMu.Lock()
+	left := p.leaveGroup(name, t)
+	t.setInterval(interval)
+	p.joinGroup(r, name, t)
+	p.pullerMu.Unlock()
+
+	left.wait()
+
+	if err = p.sessionRepo.UpdateTask(ctx, name, interval); err != nil {
 		p.l.Println(err)
 	}
 
 	return t
 }
 
-func buildTaskName(from, to string) string {
-	return strings.ToUpper(fmt.Sprintf("%s:%s", from, to))
+// joinGroup of tasks with the same data provider and interval, the group starts pulling when the first task joins,
+// pullerMu must be held by the caller
+func (p *restPuller) joinGroup(r RestClient, name string, t *Task) {
+	interval := t.interval()
+	groupName := buildTaskGroupName(t.Provider, interval)
+
+	g, ok := p.groups[groupName]
+	if !ok {
+		g = newTaskGroup(t.Provider, interval, p.retry)
+		g.run(r, p.l, p.dataPipe)
+		p.groups[groupName] = g
+	}
+
+	g.add(name, t)
+}
+
+// leaveGroup of tasks, the group is cancelled when the last task leaves, pullerMu must be held by the caller. The
+// cancelled group is returned, so the caller waits for its pull in flight after pullerMu is released
+func (p *restPuller) leaveGroup(name string, t *Task) *taskGroup {
+	groupName := buildTaskGroupName(t.Provider, t.interval())
+
+	g, ok := p.groups[groupName]
+	if !ok || !g.remove(name) {
+		return nil
+	}
+
+	g.cancel()
+	delete(p.groups, groupName)
+
+	return g
 }
 
-func (p *restPuller) newTask(from string, to string, interval int64) *Task {
+// Close stop pulling of all tasks, the pulls in flight are aborted and waited for, tasks are kept in the session store
+func (p *restPuller) Close() error {
+	p.pullerMu.Lock()
+	groups := slices.Collect(maps.Values(p.groups))
+	for _, g := range groups {
+		g.cancel()
+	}
+	clear(p.groups)
+	p.pullerMu.Unlock()
+
+	for _, g := range groups {
+		g.wait()
+	}
+
+	return nil
+}
+
+func buildTaskName(provider, from, to string) string {
+	return strings.ToUpper(fmt.Sprintf("%s:%s:%s", provider, from, to))
+}
+
+// parseTaskName split the task name "PROVIDER:FROM:TO" to the provider and currencies pair, the provider is empty for
+// the legacy names "FROM:TO" saved before several data providers were supported
+func parseTaskName(name string) (string, string, string, bool) {
+	parts := strings.Split(name, ":")
+
+	switch {
+	case len(parts) == 2:
+		return "", parts[0], parts[1], true
+	case len(parts) == 3 && parts[0] != WsSessionPrefix:
+		return strings.ToLower(parts[0]), parts[1], parts[2], true
+	}
+
+	return "", "", "", false
+}
+
+func (p *restPuller) newTask(r RestClient, provider, from, to string, interval int64) *Task {
 	if interval <= 0 {
 		interval = config.DefaultPullingInterval
 	}
 
 	return &Task{
-		done:     make(chan struct{}, 1),
+		Provider: provider,
 		From:     from,
 		To:       to,
 		Interval: interval,
+		rest:     r,
 	}
 }
 
+func (p *restPuller) provider(provider string) string {
+	if provider == "" {
+		return p.providers.Default()
+	}
+
+	return strings.ToLower(provider)
+}
+
 func (p *restPuller) task(name string) *Task {
 	p.pullerMu.RLock()
 	defer p.pullerMu.RUnlock()
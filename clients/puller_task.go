@@ -1,55 +1,354 @@
 package clients
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"maps"
 	"math/rand"
+	"slices"
+	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
+	"github.com/streamdp/ccd/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const defaultRunTaskGap = 30
 
+var (
+	errNoData      = errors.New("no data for the pair")
+	errBreakerOpen = errors.New("circuit breaker is open")
+)
+
 // Task does all the data mining run
 type Task struct {
-	From      string `json:"from"`
-	To        string `json:"to"`
-	Interval  int64  `json:"interval"`
-	done      chan struct{}
-	closeOnce sync.Once
+	Provider string    `json:"provider"`
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Interval int64     `json:"interval"`
+	State    TaskState `json:"state"`
+
+	rest    RestClient
+	stateMu sync.RWMutex
 }
 type Tasks map[string]*Task
 
-func (t *Task) run(r RestClient, l *log.Logger, dataPipe []chan *domain.Data) {
+// TaskState of the task pulls, the task is skipped until BreakerOpenUntil while its circuit breaker is open
+type TaskState struct {
+	LastSuccess         time.Time `json:"last_success,omitzero"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastErrorAt         time.Time `json:"last_error_at,omitzero"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	BreakerOpenUntil    time.Time `json:"breaker_open_until,omitzero"`
+}
+
+// RetryPolicy of the puller tasks, the failed pull is retried Attempts times within the tick with the jittered
+// exponential backoff, the task circuit breaker opens after BreakerFailures consecutive failed ticks, zero
+// BreakerFailures disables the breaker
+type RetryPolicy struct {
+	Attempts        int
+	Backoff         time.Duration
+	BreakerFailures int
+	BreakerCoolDown time.Duration
+}
+
+// MarshalJSON the task with the consistent snapshot of its state and the monthly budget of the upstream calls of its
+// data provider, when the budget is limited
+func (t *Task) MarshalJSON() ([]byte, error) {
+	type task Task
+
+	var limited *ratelimit.Budget
+	if b, ok := budget(t.rest); ok && b.Limit > 0 {
+		limited = &b
+	}
+
+	t.stateMu.RLock()
+	defer t.stateMu.RUnlock()
+
+	return json.Marshal(struct {
+		*task
+		Budget *ratelimit.Budget `json:"budget,omitempty"`
+	}{(*task)(t), limited})
+}
+
+// interval of the task pulls in seconds, it is changed by the puller while the task is marshaled
+func (t *Task) interval() int64 {
+	t.stateMu.RLock()
+	defer t.stateMu.RUnlock()
+
+	return t.Interval
+}
+
+func (t *Task) setInterval(interval int64) {
+	t.stateMu.Lock()
+	defer t.stateMu.Unlock()
+
+	t.Interval = interval
+}
+
+// available return whether the task should be pulled, the task with the open circuit breaker is tried again when
+// the cool-down is over
+func (t *Task) available(now time.Time) bool {
+	t.stateMu.RLock()
+	defer t.stateMu.RUnlock()
+
+	return !now.Before(t.State.BreakerOpenUntil)
+}
+
+func (t *Task) succeed(now time.Time) {
+	t.stateMu.Lock()
+	defer t.stateMu.Unlock()
+
+	t.State.LastSuccess = now
+	t.State.ConsecutiveFailures = 0
+	t.State.BreakerOpenUntil = time.Time{}
+}
+
+// fail record the error and report whether the circuit breaker has opened
+func (t *Task) fail(now time.Time, err error, retry RetryPolicy) bool {
+	t.stateMu.Lock()
+	defer t.stateMu.Unlock()
+
+	t.State.LastError = err.Error()
+	t.State.LastErrorAt = now
+	t.State.ConsecutiveFailures++
+
+	if retry.BreakerFailures == 0 || t.State.ConsecutiveFailures < retry.BreakerFailures {
+		return false
+	}
+
+	t.State.BreakerOpenUntil = now.Add(retry.BreakerCoolDown)
+
+	return true
+}
+
+// taskGroup pulls data for all tasks of the data provider sharing the same interval with the single upstream call
+type taskGroup struct {
+	provider string
+	interval int64
+	retry    RetryPolicy
+	tasks    map[string]*Task
+	tasksMu  sync.RWMutex
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func newTaskGroup(provider string, interval int64, retry RetryPolicy) *taskGroup {
+	return &taskGroup{
+		provider: provider,
+		interval: interval,
+		retry:    retry,
+		tasks:    make(map[string]*Task),
+		cancel:   func() {},
+	}
+}
+
+func buildTaskGroupName(provider string, interval int64) string {
+	return fmt.Sprintf("%s:%d", provider, interval)
+}
+
+func (g *taskGroup) add(name string, t *Task) {
+	g.tasksMu.Lock()
+	defer g.tasksMu.Unlock()
+
+	g.tasks[name] = t
+}
+
+// remove the task and report whether the group has become empty
+func (g *taskGroup) remove(name string) bool {
+	g.tasksMu.Lock()
+	defer g.tasksMu.Unlock()
+
+	delete(g.tasks, name)
+
+	return len(g.tasks) == 0
+}
+
+// available return tasks of the group which should be pulled
+func (g *taskGroup) available(now time.Time) []*Task {
+	g.tasksMu.RLock()
+	defer g.tasksMu.RUnlock()
+
+	tasks := make([]*Task, 0, len(g.tasks))
+	for _, name := range slices.Sorted(maps.Keys(g.tasks)) {
+		if t := g.tasks[name]; t.available(now) {
+			tasks = append(tasks, t)
+		}
+	}
+
+	return tasks
+}
+
+// run pulling in the background until the group is closed, closing the group aborts the pull in flight
+func (g *taskGroup) run(r RestClient, l *log.Logger, dataPipe []chan *domain.Data) {
+	var ctx context.Context
+
+	ctx, g.cancel = context.WithCancel(context.Background())
+	g.done = make(chan struct{})
 	timer := time.NewTimer(time.Duration(rand.Intn(defaultRunTaskGap)+1) * time.Second)
 
 	go func() {
+		defer close(g.done)
+
 		for {
 			select {
-			case <-t.done:
+			case <-ctx.Done():
 				timer.Stop()
 
 				return
 			case <-timer.C:
-				timer.Reset(time.Duration(atomic.LoadInt64(&t.Interval)) * time.Second)
-
-				data, err := r.Get(t.From, t.To)
-				if err != nil {
+				if err := g.pull(ctx, r, dataPipe); err != nil && ctx.Err() == nil {
 					l.Println(err)
-
-					continue
 				}
 
-				for i := range dataPipe {
-					dataPipe[i] <- data
-				}
+				timer.Reset(g.delay(r))
 			}
 		}
 	}()
 }
 
-func (t *Task) close() {
-	t.closeOnce.Do(func() { close(t.done) })
+// delay until the next pull, the interval is stretched when the monthly budget of the data provider is running out
+func (g *taskGroup) delay(r RestClient) time.Duration {
+	interval := time.Duration(g.interval) * time.Second
+
+	if b, ok := budget(r); ok {
+		interval *= time.Duration(b.SlowDown)
+	}
+
+	return interval
+}
+
+// pull data for all available tasks of the group and update the tasks state, the pairs missed in the answer are
+// counted as failed, the pulled data carry the trace of the tick to the consumers of the data pipes
+func (g *taskGroup) pull(ctx context.Context, r RestClient, dataPipe []chan *domain.Data) (err error) {
+	tasks := g.available(time.Now())
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ctx, span := tracing.Start(ctx, "puller.pull",
+		attribute.String("provider", g.provider),
+		attribute.Int64("interval", g.interval),
+		attribute.Int("pairs", len(tasks)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	pairs := make([]domain.Pair, len(tasks))
+	for i, t := range tasks {
+		pairs[i] = domain.Pair{From: t.From, To: t.To}
+	}
+
+	result, fetchErr := g.fetch(ctx, r, pairs)
+	if ctx.Err() != nil {
+		// the group is closed, the tasks could already be moved to another group, so their state is left untouched
+		return ctx.Err()
+	}
+
+	received := make(map[domain.Pair]*domain.Data, len(result))
+	for _, data := range result {
+		received[buildPair(data.FromSymbol, data.ToSymbol)] = data
+	}
+
+	if len(pairs) == 1 && len(result) == 1 {
+		received[buildPair(pairs[0].From, pairs[0].To)] = result[0]
+	}
+
+	var (
+		now  = time.Now()
+		errs []error
+	)
+
+	if fetchErr != nil {
+		errs = append(errs, fmt.Errorf("failed to pull %d pairs from %s: %w", len(pairs), g.provider, fetchErr))
+	}
+
+	for _, t := range tasks {
+		data, ok := received[buildPair(t.From, t.To)]
+		if ok {
+			t.succeed(now)
+			data.SetTrace(tracing.TraceParent(ctx))
+
+			// the full data pipe must not keep the group from closing, the data is dropped then
+			for i := range dataPipe {
+				select {
+				case dataPipe[i] <- data:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			continue
+		}
+
+		taskErr := fetchErr
+		if taskErr == nil {
+			taskErr = errNoData
+		}
+
+		if t.fail(now, taskErr, g.retry) {
+			errs = append(errs, fmt.Errorf("%w: %s/%s from %s is skipped for %s",
+				errBreakerOpen, t.From, t.To, g.provider, g.retry.BreakerCoolDown))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// fetch data for the pairs, a single pair is fetched with the plain Get, several pairs with the GetMany, so there is
+// one upstream call per attempt, the failed call is retried with the jittered exponential backoff
+func (g *taskGroup) fetch(ctx context.Context, r RestClient, pairs []domain.Pair) ([]*domain.Data, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := get(ctx, r, pairs)
+		if err == nil || attempt >= g.retry.Attempts || errors.Is(err, ratelimit.ErrBudgetExhausted) {
+			return result, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(jitteredBackoff(g.retry.Backoff, attempt)):
+		}
+	}
+}
+
+// wait for the running pull of the cancelled group to finish, so no data is sent to the data pipes afterward, it does
+// nothing for the nil group
+func (g *taskGroup) wait() {
+	if g != nil && g.done != nil {
+		<-g.done
+	}
+}
+
+func get(ctx context.Context, r RestClient, pairs []domain.Pair) ([]*domain.Data, error) {
+	if len(pairs) > 1 {
+		return r.GetMany(ctx, pairs)
+	}
+
+	data, err := r.Get(ctx, pairs[0].From, pairs[0].To)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*domain.Data{data}, nil
+}
+
+// jitteredBackoff return the delay before the retry, it doubles with every attempt and is randomized within its
+// upper half, so the tasks failed at once do not retry at once
+func jitteredBackoff(base time.Duration, attempt int) time.Duration {
+	d := base << attempt
+	if d <= 0 {
+		return 0
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func buildPair(from, to string) domain.Pair {
+	return domain.Pair{From: strings.ToUpper(from), To: strings.ToUpper(to)}
 }
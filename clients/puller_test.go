@@ -0,0 +1,386 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/ratelimit"
+)
+
+func TestParseTaskName(t *testing.T) {
+	tests := []struct {
+		name         string
+		task         string
+		wantProvider string
+		wantFrom     string
+		wantTo       string
+		wantOk       bool
+	}{
+		{
+			name:         "task name with provider",
+			task:         buildTaskName("kraken", "btc", "usdt"),
+			wantProvider: "kraken",
+			wantFrom:     "BTC",
+			wantTo:       "USDT",
+			wantOk:       true,
+		},
+		{
+			name:     "legacy task name",
+			task:     "BTC:USDT",
+			wantFrom: "BTC",
+			wantTo:   "USDT",
+			wantOk:   true,
+		},
+		{
+			name:   "legacy ws session name",
+			task:   "WS:BTC:USDT",
+			wantOk: false,
+		},
+		{
+			name:   "ws session name",
+			task:   "WS:HUOBI:BTC:USDT",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotProvider, gotFrom, gotTo, gotOk := parseTaskName(tt.task)
+			if gotProvider != tt.wantProvider || gotFrom != tt.wantFrom || gotTo != tt.wantTo || gotOk != tt.wantOk {
+				t.Errorf("parseTaskName() = %v, %v, %v, %v, want %v, %v, %v, %v",
+					gotProvider, gotFrom, gotTo, gotOk, tt.wantProvider, tt.wantFrom, tt.wantTo, tt.wantOk)
+			}
+		})
+	}
+}
+
+type mockSessionRepo struct{}
+
+func (m *mockSessionRepo) AddTask(_ context.Context, _ string, _ int64) error    { return nil }
+func (m *mockSessionRepo) UpdateTask(_ context.Context, _ string, _ int64) error { return nil }
+func (m *mockSessionRepo) RemoveTask(_ context.Context, _ string) error          { return nil }
+func (m *mockSessionRepo) Close() error                                          { return nil }
+
+func (m *mockSessionRepo) GetSession(_ context.Context) (map[string]int64, error) {
+	return map[string]int64{}, nil
+}
+
+func TestRestPuller_groups(t *testing.T) {
+	ctx := context.Background()
+
+	providers := NewProviders("kraken", nil, nil)
+	providers.AddRest("kraken", &mockRestClient{})
+	providers.AddRest("huobi", &mockRestClient{})
+
+	p := NewPuller(providers, log.New(io.Discard, "", 0), &mockSessionRepo{}, RetryPolicy{})
+
+	for _, task := range []struct {
+		provider string
+		from     string
+		interval int64
+	}{
+		{provider: "kraken", from: "BTC", interval: 60},
+		{provider: "kraken", from: "ETH", interval: 60},
+		{provider: "kraken", from: "XRP", interval: 30},
+		{provider: "huobi", from: "BTC", interval: 60},
+	} {
+		if _, err := p.AddTask(ctx, task.provider, task.from, "USDT", task.interval); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wantGroups := func(want map[string]int) {
+		t.Helper()
+
+		got := make(map[string]int, len(p.groups))
+		for name, g := range p.groups {
+			got[name] = len(g.tasks)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("groups = %v, want %v", got, want)
+		}
+	}
+
+	wantGroups(map[string]int{"kraken:60": 2, "kraken:30": 1, "huobi:60": 1})
+
+	p.UpdateTask(ctx, p.Task("kraken", "XRP", "USDT"), 60)
+	wantGroups(map[string]int{"kraken:60": 3, "huobi:60": 1})
+
+	p.RemoveTask(ctx, "huobi", "BTC", "USDT")
+	p.RemoveTask(ctx, "", "ETH", "USDT")
+	wantGroups(map[string]int{"kraken:60": 2})
+}
+
+func Test_taskGroup_pull(t *testing.T) {
+	r := &mockRestClient{price: 1}
+	pipe := make(chan *domain.Data, 3)
+
+	g := newTaskGroup("kraken", 60, RetryPolicy{})
+	for _, from := range []string{"BTC", "ETH", "XRP"} {
+		g.add(buildTaskName("kraken", from, "USDT"), &Task{Provider: "kraken", From: from, To: "USDT"})
+	}
+
+	if err := g.pull(context.Background(), r, []chan *domain.Data{pipe}); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.manyCalls != 1 || len(pipe) != 3 {
+		t.Errorf("pull() GetMany calls = %d, sent = %d, want 1 call and 3 pairs", r.manyCalls, len(pipe))
+	}
+}
+
+type mockBudgetClient struct {
+	mockRestClient
+	budget ratelimit.Budget
+}
+
+func (m *mockBudgetClient) Budget() ratelimit.Budget {
+	return m.budget
+}
+
+func (m *mockBudgetClient) RestoreBudget(_ int64, _ time.Time) {}
+
+func Test_taskGroup_delay(t *testing.T) {
+	tests := []struct {
+		name string
+		r    RestClient
+		want time.Duration
+	}{
+		{
+			name: "no budget",
+			r:    &mockRestClient{},
+			want: time.Minute,
+		},
+		{
+			name: "enough budget",
+			r:    &mockBudgetClient{budget: ratelimit.Budget{Limit: 100, Remaining: 50, SlowDown: 1}},
+			want: time.Minute,
+		},
+		{
+			name: "budget is running out",
+			r:    &mockBudgetClient{budget: ratelimit.Budget{Limit: 100, Remaining: 5, SlowDown: 4}},
+			want: 4 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTaskGroup("kraken", 60, RetryPolicy{}).delay(tt.r); got != tt.want {
+				t.Errorf("delay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type flakyRestClient struct {
+	mockRestClient
+	failures int
+}
+
+func (f *flakyRestClient) GetMany(ctx context.Context, pairs []domain.Pair) ([]*domain.Data, error) {
+	if f.failures > 0 {
+		f.failures--
+		f.manyCalls++
+
+		return nil, errUpstream
+	}
+
+	return f.mockRestClient.GetMany(ctx, pairs)
+}
+
+func Test_taskGroup_pullRetry(t *testing.T) {
+	r := &flakyRestClient{mockRestClient: mockRestClient{price: 1}, failures: 2}
+	pipe := make(chan *domain.Data, 2)
+
+	g := newTaskGroup("kraken", 60, RetryPolicy{Attempts: 2, Backoff: time.Millisecond})
+	for _, from := range []string{"BTC", "ETH"} {
+		g.add(buildTaskName("kraken", from, "USDT"), &Task{Provider: "kraken", From: from, To: "USDT"})
+	}
+
+	if err := g.pull(context.Background(), r, []chan *domain.Data{pipe}); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.manyCalls != 3 || len(pipe) != 2 {
+		t.Errorf("pull() GetMany calls = %d, sent = %d, want 3 calls and 2 pairs", r.manyCalls, len(pipe))
+	}
+
+	for _, task := range g.available(time.Now()) {
+		if task.State.LastSuccess.IsZero() || task.State.ConsecutiveFailures != 0 {
+			t.Errorf("pull() task state = %+v, want success", task.State)
+		}
+	}
+}
+
+func Test_taskGroup_pullBreaker(t *testing.T) {
+	r := &mockRestClient{err: errUpstream}
+	task := &Task{Provider: "kraken", From: "BTC", To: "USDT"}
+
+	g := newTaskGroup("kraken", 60, RetryPolicy{BreakerFailures: 2, BreakerCoolDown: time.Minute})
+	g.add(buildTaskName("kraken", "BTC", "USDT"), task)
+
+	if err := g.pull(context.Background(), r, nil); !errors.Is(err, errUpstream) || errors.Is(err, errBreakerOpen) {
+		t.Errorf("pull() error = %v, wantErr %v", err, errUpstream)
+	}
+
+	if err := g.pull(context.Background(), r, nil); !errors.Is(err, errBreakerOpen) {
+		t.Errorf("pull() error = %v, wantErr %v", err, errBreakerOpen)
+	}
+
+	if err := g.pull(context.Background(), r, nil); err != nil || r.calls != 2 {
+		t.Errorf("pull() error = %v, calls = %d, want the task skipped", err, r.calls)
+	}
+
+	if task.State.ConsecutiveFailures != 2 || task.State.LastError != errUpstream.Error() ||
+		task.available(time.Now()) || !task.available(task.State.BreakerOpenUntil) {
+		t.Errorf("pull() task state = %+v, want the open breaker", task.State)
+	}
+}
+
+func Test_jitteredBackoff(t *testing.T) {
+	for attempt := range 4 {
+		d := time.Second << attempt
+		if got := jitteredBackoff(time.Second, attempt); got < d/2 || got > d {
+			t.Errorf("jitteredBackoff() = %v, want within [%v, %v]", got, d/2, d)
+		}
+	}
+
+	if got := jitteredBackoff(0, 1); got != 0 {
+		t.Errorf("jitteredBackoff() = %v, want 0", got)
+	}
+}
+
+func TestTask_MarshalJSON(t *testing.T) {
+	task := &Task{Provider: "kraken", From: "BTC", To: "USDT", Interval: 60}
+	task.fail(time.Date(2025, 5, 19, 10, 0, 0, 0, time.UTC), errUpstream, RetryPolicy{})
+
+	got, err := json.Marshal(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"provider":"kraken","from":"BTC","to":"USDT","interval":60,"state":{"last_error":"upstream is down",` +
+		`"last_error_at":"2025-05-19T10:00:00Z","consecutive_failures":1}}`
+	if string(got) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", got, want)
+	}
+
+	// the task of the data provider with the monthly budget reports what is left of it
+	task.rest = &limitedRestClient{limiter: ratelimit.New(0, 100)}
+	if got, err = json.Marshal(task); err != nil {
+		t.Fatal(err)
+	}
+
+	if want = `"budget":{"limit":100,"used":0,"remaining":100,`; !strings.Contains(string(got), want) {
+		t.Errorf("MarshalJSON() = %s, want it containing %s", got, want)
+	}
+}
+
+type blockingRestClient struct {
+	mockRestClient
+}
+
+func (b *blockingRestClient) Get(ctx context.Context, _ string, _ string) (*domain.Data, error) {
+	<-ctx.Done()
+
+	return nil, ctx.Err()
+}
+
+func Test_taskGroup_pullCanceled(t *testing.T) {
+	task := &Task{Provider: "kraken", From: "BTC", To: "USDT"}
+
+	g := newTaskGroup("kraken", 60, RetryPolicy{Attempts: 3, Backoff: time.Minute})
+	g.add(buildTaskName("kraken", "BTC", "USDT"), task)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := g.pull(ctx, &blockingRestClient{}, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("pull() error = %v, wantErr %v", err, context.DeadlineExceeded)
+	}
+
+	if task.State.ConsecutiveFailures != 0 || task.State.LastError != "" {
+		t.Errorf("pull() task state = %+v, want untouched by the aborted pull", task.State)
+	}
+}
+
+func Test_taskGroup_pullFullPipe(t *testing.T) {
+	g := newTaskGroup("kraken", 60, RetryPolicy{})
+	g.add(buildTaskName("kraken", "BTC", "USDT"), &Task{Provider: "kraken", From: "BTC", To: "USDT"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// nobody reads the pipe, the pull is aborted when the group is closed
+	pipe := make(chan *domain.Data)
+
+	err := g.pull(ctx, &mockRestClient{price: 1}, []chan *domain.Data{pipe})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("pull() error = %v, wantErr %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRestPuller_leaveGroupUnlocked(t *testing.T) {
+	ctx := context.Background()
+
+	providers := NewProviders("kraken", nil, nil)
+	providers.AddRest("kraken", &mockRestClient{})
+
+	p := NewPuller(providers, log.New(io.Discard, "", 0), &mockSessionRepo{}, RetryPolicy{})
+
+	// the group with the pull in flight finishing when done is closed
+	name := buildTaskName("kraken", "BTC", "USDT")
+	task := &Task{Provider: "kraken", From: "BTC", To: "USDT", Interval: 60}
+	done := make(chan struct{})
+	cancelled := make(chan struct{})
+
+	g := newTaskGroup("kraken", 60, RetryPolicy{})
+	g.cancel = func() { close(cancelled) }
+	g.done = done
+	g.add(name, task)
+	p.groups[buildTaskGroupName("kraken", 60)] = g
+	p.tasks[name] = task
+
+	updated := make(chan struct{})
+	go func() {
+		defer close(updated)
+
+		p.UpdateTask(ctx, task, 30)
+	}()
+
+	<-cancelled
+
+	// the tasks are listed and marshaled while the update waits for the pull in flight
+	listed := make(chan struct{})
+	go func() {
+		defer close(listed)
+
+		for range 100 {
+			if _, err := json.Marshal(p.ListTasks()); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+
+	select {
+	case <-listed:
+	case <-time.After(time.Second):
+		t.Error("ListTasks() is blocked by the update waiting for the pull in flight")
+	}
+
+	close(done)
+	<-updated
+
+	if got := task.interval(); got != 30 {
+		t.Errorf("UpdateTask() interval = %d, want 30", got)
+	}
+
+	_ = p.Close()
+}
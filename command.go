@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/streamdp/ccd/config"
+	"github.com/streamdp/ccd/db"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/apikeys"
+	"github.com/streamdp/ccd/pkg/migrate"
+)
+
+var (
+	errUnknownCommand = errors.New(
+		"unknown command, use \"migrate up|down|status\", \"timescale\" or \"keys create NAME SCOPES\"",
+	)
+	errNoSchemaMigrate = errors.New("database has no schema migrations")
+	errNoApiKeys       = errors.New("database can't keep the api keys")
+	errNoTimescale     = errors.New("database can't use the TimescaleDB extension")
+	errNoExtension     = errors.New("TimescaleDB extension is not installed")
+)
+
+// runCommand given after the flags instead of running the service
+func runCommand(ctx context.Context, d any, args []string, ts *config.Timescale) error {
+	switch {
+	case len(args) == 2 && args[0] == "migrate":
+		return runMigrate(ctx, d, args[1])
+	case len(args) == 1 && args[0] == "timescale":
+		return runTimescale(ctx, d, ts)
+	case len(args) == 4 && args[0] == "keys" && args[1] == "create":
+		return runKeysCreate(ctx, d, args[2], args[3])
+	}
+
+	return errUnknownCommand
+}
+
+func runMigrate(ctx context.Context, d any, command string) error {
+	m, err := newMigrator(d)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		n, errUp := m.Up(ctx)
+		if errUp != nil {
+			return errUp
+		}
+
+		fmt.Printf("applied %d migrations\n", n)
+	case "down":
+		mg, errDown := m.Down(ctx)
+		if errDown != nil {
+			return errDown
+		}
+
+		fmt.Printf("rolled back %04d_%s\n", mg.Version, mg.Name)
+	case "status":
+		status, errStatus := m.Status(ctx)
+		if errStatus != nil {
+			return errStatus
+		}
+
+		for _, s := range status {
+			applied := "pending"
+			if s.Applied {
+				applied = "applied at " + s.AppliedAt.Format(time.RFC3339)
+			}
+
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, applied)
+		}
+	default:
+		return errUnknownCommand
+	}
+
+	return nil
+}
+
+// runTimescale finish the backfill of the row time and turn the data table into the hypertable, the existing rows are
+// moved to the hypertable chunks in one transaction, so the table is locked until it is done
+func runTimescale(ctx context.Context, d any, ts *config.Timescale) error {
+	tsdb, ok := d.(db.Timescaler)
+	if !ok {
+		return errNoTimescale
+	}
+
+	if b, isBackfiller := d.(db.Backfiller); isBackfiller {
+		n, err := b.Backfill(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to backfill: %w", err)
+		}
+
+		fmt.Printf("backfilled %d rows\n", n)
+	}
+
+	found, err := tsdb.Timescale(ctx, ts.CompressAfter(), ts.Retention())
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return errNoExtension
+	}
+
+	fmt.Println("TimescaleDB hypertable and continuous aggregates are set up")
+
+	return nil
+}
+
+// setUpTimescale on the startup when it is enabled, the service keeps running without the hypertable when the
+// TimescaleDB extension is not installed
+func setUpTimescale(ctx context.Context, l *log.Logger, tsdb db.Timescaler, ts *config.Timescale) {
+	found, err := tsdb.Timescale(ctx, ts.CompressAfter(), ts.Retention())
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			l.Printf("failed to set up TimescaleDB: %v", err)
+		}
+
+		return
+	}
+
+	if !found {
+		l.Println("TimescaleDB extension is not installed, the data table is kept as it is")
+
+		return
+	}
+
+	l.Println("TimescaleDB hypertable and continuous aggregates are set up")
+}
+
+// runKeysCreate create the api key with the comma separated scopes, e.g. the admin key replacing the lost one, the key
+// is printed once
+func runKeysCreate(ctx context.Context, d any, name, scopes string) error {
+	store, ok := d.(apikeys.Store)
+	if !ok {
+		return errNoApiKeys
+	}
+
+	key, secret, err := apikeys.New(store).Create(ctx, &domain.ApiKey{Name: name, Scopes: domain.SplitScopes(scopes)})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created api key %d %s with the scopes %s, it is not shown again:\n%s\n",
+		key.Id, key.Name, strings.Join(key.Scopes, ","), secret)
+
+	return nil
+}
+
+func newMigrator(d any) (*migrate.Migrator, error) {
+	sm, ok := d.(db.SchemaMigrator)
+	if !ok {
+		return nil, errNoSchemaMigrate
+	}
+
+	m, err := sm.Migrator()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	return m, nil
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -21,24 +23,59 @@ type App struct {
 	SessionStore string
 	ApiKey       string
 	DatabaseUrl  string
-
-	Http  *Http
-	Redis *Redis
+	OtlpEndpoint string
+	AlertsSecret string
+
+	Http      *Http
+	Redis     *Redis
+	Failover  *Failover
+	Limits    *Limits
+	Retry     *Retry
+	Batch     *Batch
+	Timescale *Timescale
+	Nats      *Nats
+	Mqtt      *Mqtt
 
 	runMode string
 	debug   bool
+	migrate bool
+	auth    bool
+	command []string
 	version string
 }
 
 func NewAppConfig() *App {
 	return &App{
-		Http: &Http{},
+		Http: &Http{shutdownTimeout: shutdownDefaultTimeout},
 		Redis: &Redis{
 			Host:     redisDefaultHost,
 			Port:     redisDefaultPort,
 			Password: "",
 			Db:       redisDefaultDb,
 		},
+		Failover: &Failover{
+			errors:   failoverDefaultErrors,
+			coolDown: failoverDefaultCoolDown,
+		},
+		Limits: &Limits{},
+		Retry: &Retry{
+			attempts:        retryDefaultAttempts,
+			backoff:         retryDefaultBackoff,
+			breakerFailures: retryDefaultBreakerFailures,
+			breakerCoolDown: retryDefaultBreakerCoolDown,
+		},
+		Batch: &Batch{
+			size:     batchDefaultSize,
+			interval: batchDefaultInterval,
+		},
+		Timescale: &Timescale{
+			compressAfter: timescaleDefaultCompressAfter,
+		},
+		Nats: &Nats{},
+		Mqtt: &Mqtt{
+			ClientId: mqttDefaultClientId,
+			qos:      mqttDefaultQos,
+		},
 
 		runMode: gin.ReleaseMode,
 		version: version,
@@ -54,6 +91,30 @@ func (a *App) Validate() error {
 		return err
 	}
 
+	if err := a.Failover.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.Limits.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.Retry.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.Batch.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.Timescale.Validate(); err != nil {
+		return err
+	}
+
+	if err := a.Mqtt.Validate(); err != nil {
+		return err
+	}
+
 	if a.DatabaseUrl == "" {
 		return errEmptyDatabaseUrl
 	}
@@ -69,6 +130,50 @@ func (a *App) RunMode() string {
 	return a.runMode
 }
 
+// Migrate return true when the database schema migrations are applied on startup
+func (a *App) Migrate() bool {
+	return a.migrate
+}
+
+// Auth return true when the api requests require the api key with the scope, otherwise the api is open to everyone
+// who can reach the port
+func (a *App) Auth() bool {
+	return a.auth
+}
+
+// Command return the subcommand with its arguments given after the flags, e.g. "migrate up", it is empty when the
+// service is run
+func (a *App) Command() []string {
+	return a.command
+}
+
+// FailoverChain return the ordered names of the rest providers used to get the actual price, the enabled data
+// providers order is used when the chain isn't configured
+func (a *App) FailoverChain() []string {
+	if chain := a.Failover.Chain(); len(chain) != 0 {
+		return chain
+	}
+
+	return a.DataProviders()
+}
+
+// DataProviders return the names of the enabled data providers, the first one is the default
+func (a *App) DataProviders() []string {
+	var providers []string
+
+	for p := range strings.SplitSeq(a.DataProvider, ",") {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" && !slices.Contains(providers, p) {
+			providers = append(providers, p)
+		}
+	}
+
+	if len(providers) == 0 {
+		return []string{defaultDataProvider}
+	}
+
+	return providers
+}
+
 func (a *App) loadEnvs() error {
 	if a.DatabaseUrl = os.Getenv("CCDC_DATABASEURL"); a.DatabaseUrl == "" {
 		return fmt.Errorf("failed to load 'CCDC_DATABASEURL' env: %w", errEmptyDatabaseUrl)
@@ -78,12 +183,60 @@ func (a *App) loadEnvs() error {
 		a.DataProvider = strings.ToLower(dataProvider)
 	}
 
+	if failover := os.Getenv("CCDC_FAILOVER"); failover != "" {
+		a.Failover.chain = failover
+	}
+
+	if rateLimits := os.Getenv("CCDC_RATELIMITS"); rateLimits != "" {
+		a.Limits.rates = rateLimits
+	}
+
+	if budgets := os.Getenv("CCDC_BUDGETS"); budgets != "" {
+		a.Limits.budgets = budgets
+	}
+
+	if otlpEndpoint := os.Getenv("CCDC_OTLP_ENDPOINT"); otlpEndpoint != "" {
+		a.OtlpEndpoint = otlpEndpoint
+	}
+
 	a.ApiKey = os.Getenv("CCDC_APIKEY")
+	a.AlertsSecret = os.Getenv("CCDC_ALERTS_SECRET")
+	a.Nats.Url = os.Getenv("CCDC_NATS_URL")
+	a.Nats.Stream = os.Getenv("CCDC_NATS_STREAM")
+	a.Mqtt.Url = os.Getenv("CCDC_MQTT_URL")
+	a.Mqtt.CaFile = os.Getenv("CCDC_MQTT_CA")
+	a.Mqtt.CertFile = os.Getenv("CCDC_MQTT_CERT")
+	a.Mqtt.KeyFile = os.Getenv("CCDC_MQTT_KEY")
+
+	if clientId := os.Getenv("CCDC_MQTT_CLIENT_ID"); clientId != "" {
+		a.Mqtt.ClientId = clientId
+	}
+
+	if qos := os.Getenv("CCDC_MQTT_QOS"); qos != "" {
+		n, err := strconv.Atoi(qos)
+		if err != nil {
+			return fmt.Errorf("invalid CCDC_MQTT_QOS: %w", errWrongMqttQos)
+		}
+
+		a.Mqtt.qos = n
+	}
 
 	if sessionStore := os.Getenv("CCDC_SESSIONSTORE"); sessionStore != "" {
 		a.SessionStore = strings.ToLower(sessionStore)
 	}
 
+	if strings.ToLower(os.Getenv("CCDC_MIGRATE")) == "false" {
+		a.migrate = false
+	}
+
+	if strings.ToLower(os.Getenv("CCDC_TIMESCALE")) == "true" {
+		a.Timescale.enabled = true
+	}
+
+	if strings.ToLower(os.Getenv("CCDC_AUTH")) == "false" {
+		a.auth = false
+	}
+
 	if strings.ToLower(os.Getenv("CCDC_DEBUG")) == "true" {
 		a.debug = true
 	}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	batchDefaultSize     = 500
+	batchDefaultInterval = 1000
+)
+
+var errBatchNotPositive = errors.New("batch size and interval must be positive")
+
+// Batch settings of the database writer, the collected data is saved when the batch is full or the interval is over
+type Batch struct {
+	size     int
+	interval int
+}
+
+// Size return the maximum number of rows saved with the single batch
+func (b *Batch) Size() int {
+	return b.size
+}
+
+// Interval return how long the rows wait for the batch to be full
+func (b *Batch) Interval() time.Duration {
+	return time.Duration(b.interval) * time.Millisecond
+}
+
+func (b *Batch) Validate() error {
+	if b.size <= 0 || b.interval <= 0 {
+		return fmt.Errorf("batch: %w", errBatchNotPositive)
+	}
+
+	return nil
+}
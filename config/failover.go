@@ -0,0 +1,60 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	failoverDefaultErrors   = 3
+	failoverDefaultCoolDown = 60
+)
+
+var (
+	errFailoverErrors   = errors.New("number of errors before the cool-down must be positive")
+	errFailoverCoolDown = errors.New("cool-down cannot be negative")
+)
+
+// Failover settings of the rest providers chain used to get the actual price
+type Failover struct {
+	chain    string
+	errors   int
+	coolDown int
+}
+
+// Chain return ordered names of the rest providers, an empty chain means the enabled data providers order
+func (f *Failover) Chain() []string {
+	var chain []string
+
+	for p := range strings.SplitSeq(f.chain, ",") {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			chain = append(chain, p)
+		}
+	}
+
+	return chain
+}
+
+// Errors return the number of consecutive errors after which the provider is put on the cool-down
+func (f *Failover) Errors() int {
+	return f.errors
+}
+
+// CoolDown return how long the failed provider is skipped
+func (f *Failover) CoolDown() time.Duration {
+	return time.Duration(f.coolDown) * time.Second
+}
+
+func (f *Failover) Validate() error {
+	if f.errors <= 0 {
+		return fmt.Errorf("failover: %w", errFailoverErrors)
+	}
+
+	if f.coolDown < 0 {
+		return fmt.Errorf("failover: %w", errFailoverCoolDown)
+	}
+
+	return nil
+}
@@ -21,18 +21,63 @@ func LoadConfig() (*App, error) {
 	flag.BoolVar(&showHelp, "h", false, "display help")
 	flag.BoolVar(&showVersion, "v", false, "display version")
 	flag.BoolVar(&appCfg.debug, "debug", false, "run the program in debug mode")
+	flag.BoolVar(&appCfg.migrate, "migrate", true, "apply the database schema migrations on startup")
+	flag.BoolVar(&appCfg.auth, "auth", true, "require the api key with the scope for the api requests, disable it"+
+		" only to keep the api open to everyone who can reach the port")
 	flag.IntVar(&appCfg.Http.port, "port", httpServerDefaultPort, "set specify port")
 	flag.StringVar(&appCfg.SessionStore, "session", defaultSessionStore,
 		"set session store \"db\" or \"redis\"")
 	flag.IntVar(&appCfg.Http.clientTimeout, "timeout", httpDefaultTimeout, "HTTP client timeout")
 	flag.IntVar(&appCfg.Http.serverTimeout, "server-timeout", httpDefaultTimeout, "HTTP server timeout")
-	flag.StringVar(&appCfg.DataProvider, "dataprovider", defaultDataProvider, "use selected data provider"+
-		" (\"cryptocompare\", \"huobi\", \"kraken\")")
+	flag.IntVar(&appCfg.Http.shutdownTimeout, "shutdown-timeout", shutdownDefaultTimeout,
+		"how long in seconds to wait for the buffered data to be saved on the exit signal")
+	flag.StringVar(&appCfg.DataProvider, "dataprovider", defaultDataProvider, "use selected data providers"+
+		" separated by comma, the first one is the default (\"binance\", \"coinbase\", \"cryptocompare\", \"huobi\", \"kraken\")")
+	flag.StringVar(&appCfg.Failover.chain, "failover", "", "ordered rest providers separated by comma to get"+
+		" the actual price, the data providers order is used by default")
+	flag.IntVar(&appCfg.Failover.errors, "failover-errors", failoverDefaultErrors,
+		"consecutive errors before the rest provider is put on the cool-down")
+	flag.IntVar(&appCfg.Failover.coolDown, "failover-cooldown", failoverDefaultCoolDown,
+		"how long in seconds the failed rest provider is skipped")
+	flag.StringVar(&appCfg.Limits.rates, "rate-limits", "", "upstream calls per second allowed for the data"+
+		" providers, e.g. \"cryptocompare=10,kraken=2\", built-in limits are used by default")
+	flag.StringVar(&appCfg.Limits.budgets, "budgets", "", "upstream calls per calendar month allowed for the"+
+		" data providers, e.g. \"cryptocompare=100000\", unlimited by default")
+	flag.IntVar(&appCfg.Retry.attempts, "retries", retryDefaultAttempts,
+		"retries of the failed pull within the puller task tick")
+	flag.IntVar(&appCfg.Retry.backoff, "retry-backoff", retryDefaultBackoff,
+		"delay in milliseconds before the first retry, it doubles with every next one")
+	flag.IntVar(&appCfg.Retry.breakerFailures, "breaker-failures", retryDefaultBreakerFailures,
+		"consecutive failed ticks after which the puller task is skipped, 0 disables the breaker")
+	flag.IntVar(&appCfg.Retry.breakerCoolDown, "breaker-cooldown", retryDefaultBreakerCoolDown,
+		"how long in seconds the puller task with the open breaker is skipped")
+	flag.IntVar(&appCfg.Batch.size, "batch-size", batchDefaultSize,
+		"maximum number of the collected data rows saved to the database at once")
+	flag.IntVar(&appCfg.Batch.interval, "batch-interval", batchDefaultInterval,
+		"how long in milliseconds the collected data waits for the database batch to be full")
+	flag.BoolVar(&appCfg.Timescale.enabled, "timescale", false, "set up the TimescaleDB hypertable and the candle"+
+		" aggregates on startup when the extension is installed, like the \"timescale\" command does")
+	flag.IntVar(&appCfg.Timescale.compressAfter, "timescale-compress-after", timescaleDefaultCompressAfter,
+		"compress the TimescaleDB data chunks older than the given days, 0 disables the compression")
+	flag.IntVar(&appCfg.Timescale.retention, "timescale-retention", 0,
+		"drop the TimescaleDB data chunks older than the given days, the candles are kept, 0 keeps the data forever")
+	flag.StringVar(&appCfg.OtlpEndpoint, "otlp-endpoint", "", "OTLP/HTTP endpoint to export traces to,"+
+		" e.g. \"http://localhost:4318\", tracing is disabled by default")
 	flag.Parse()
 
+	appCfg.command = flag.Args()
+
 	if showHelp {
 		fmt.Println("ccd is a microservice that collect data from several crypto data providers using its API.")
 		fmt.Println("")
+		fmt.Println("Commands:")
+		fmt.Println("  migrate up|down|status")
+		fmt.Println("        apply pending, roll back the last or list the database schema migrations")
+		fmt.Println("  timescale")
+		fmt.Println("        turn the PostgreSQL data table into the TimescaleDB hypertable and create the candle aggregates")
+		fmt.Println("  keys create NAME SCOPES")
+		fmt.Println("        create the api key with the comma separated scopes, e.g. \"keys create ops admin\"")
+		fmt.Println("")
 		flag.Usage()
 		os.Exit(1)
 	}
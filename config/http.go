@@ -7,16 +7,21 @@ import (
 )
 
 const (
-	httpServerDefaultPort = 8080
-	httpDefaultTimeout    = 5000
+	httpServerDefaultPort  = 8080
+	httpDefaultTimeout     = 5000
+	shutdownDefaultTimeout = 30
 )
 
-var errWrongNetworkPort = errors.New("port must be between 0 and 65535")
+var (
+	errWrongNetworkPort     = errors.New("port must be between 0 and 65535")
+	errWrongShutdownTimeout = errors.New("shutdown timeout must be positive")
+)
 
 type Http struct {
-	port          int
-	clientTimeout int
-	serverTimeout int
+	port            int
+	clientTimeout   int
+	serverTimeout   int
+	shutdownTimeout int
 }
 
 func (h *Http) ClientTimeout() time.Duration {
@@ -27,6 +32,11 @@ func (h *Http) ServerTimeout() time.Duration {
 	return time.Duration(h.serverTimeout) * time.Millisecond
 }
 
+// ShutdownTimeout return how long the app waits for the components to drain on the exit signal
+func (h *Http) ShutdownTimeout() time.Duration {
+	return time.Duration(h.shutdownTimeout) * time.Second
+}
+
 func (h *Http) Port() int {
 	return h.port
 }
@@ -36,5 +46,9 @@ func (h *Http) Validate() error {
 		return fmt.Errorf("http: %w", errWrongNetworkPort)
 	}
 
+	if h.shutdownTimeout <= 0 {
+		return fmt.Errorf("http: %w", errWrongShutdownTimeout)
+	}
+
 	return nil
 }
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	errLimitFormat   = errors.New("limit must be in the \"provider=value\" format")
+	errLimitNegative = errors.New("limit cannot be negative")
+)
+
+// Limits of the upstream calls per data provider, both lists are in the "provider=value,provider=value" format
+type Limits struct {
+	rates   string
+	budgets string
+
+	rate   map[string]float64
+	budget map[string]int64
+}
+
+// Rate return the number of calls per second allowed for the data provider or the provider default
+func (l *Limits) Rate(provider string, defaultRate float64) float64 {
+	if r, ok := l.rate[provider]; ok {
+		return r
+	}
+
+	return defaultRate
+}
+
+// Budget return the number of calls per calendar month allowed for the data provider, zero means unlimited
+func (l *Limits) Budget(provider string) int64 {
+	return l.budget[provider]
+}
+
+func (l *Limits) Validate() error {
+	var err error
+
+	if l.rate, err = parseLimits(l.rates, func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	}); err != nil {
+		return fmt.Errorf("rate limits: %w", err)
+	}
+
+	if l.budget, err = parseLimits(l.budgets, func(s string) (int64, error) {
+		return strconv.ParseInt(s, 10, 64)
+	}); err != nil {
+		return fmt.Errorf("budgets: %w", err)
+	}
+
+	return nil
+}
+
+func parseLimits[T int64 | float64](s string, parse func(string) (T, error)) (map[string]T, error) {
+	limits := make(map[string]T)
+
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+
+		provider, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errLimitFormat, item)
+		}
+
+		v, err := parse(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errLimitFormat, err)
+		}
+
+		if v < 0 {
+			return nil, fmt.Errorf("%w: %s", errLimitNegative, item)
+		}
+
+		limits[strings.ToLower(strings.TrimSpace(provider))] = v
+	}
+
+	return limits, nil
+}
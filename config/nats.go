@@ -0,0 +1,13 @@
+package config
+
+// Nats settings of the NATS publisher, the collected data is published only when Url is set, it is stored with the
+// JetStream stream when Stream is set
+type Nats struct {
+	Url    string
+	Stream string
+}
+
+// Enabled return true when the collected data is published to NATS
+func (n *Nats) Enabled() bool {
+	return n.Url != ""
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	retryDefaultAttempts        = 2
+	retryDefaultBackoff         = 500
+	retryDefaultBreakerFailures = 5
+	retryDefaultBreakerCoolDown = 300
+)
+
+var (
+	errRetryNegative   = errors.New("retries, backoff and breaker settings cannot be negative")
+	errRetryNoCoolDown = errors.New("breaker cool-down must be positive when the breaker is enabled")
+)
+
+// Retry settings of the puller tasks, the failed pull is retried within the tick with the jittered exponential
+// backoff, the task circuit breaker opens after the number of consecutive failed ticks
+type Retry struct {
+	attempts        int
+	backoff         int
+	breakerFailures int
+	breakerCoolDown int
+}
+
+// Attempts return the number of retries after the failed pull within the tick
+func (r *Retry) Attempts() int {
+	return r.attempts
+}
+
+// Backoff return the delay before the first retry, it doubles with every next one
+func (r *Retry) Backoff() time.Duration {
+	return time.Duration(r.backoff) * time.Millisecond
+}
+
+// BreakerFailures return the number of consecutive failed ticks after which the task circuit breaker opens, zero
+// disables the breaker
+func (r *Retry) BreakerFailures() int {
+	return r.breakerFailures
+}
+
+// BreakerCoolDown return how long the task with the open circuit breaker is skipped
+func (r *Retry) BreakerCoolDown() time.Duration {
+	return time.Duration(r.breakerCoolDown) * time.Second
+}
+
+func (r *Retry) Validate() error {
+	if r.attempts < 0 || r.backoff < 0 || r.breakerFailures < 0 || r.breakerCoolDown < 0 {
+		return fmt.Errorf("retry: %w", errRetryNegative)
+	}
+
+	if r.breakerFailures > 0 && r.breakerCoolDown == 0 {
+		return fmt.Errorf("retry: %w", errRetryNoCoolDown)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	timescaleDefaultCompressAfter = 7
+	timescaleMinRetention         = 7
+)
+
+var (
+	errNegativeCompressAfter = errors.New("compress after must not be negative")
+	errWrongRetention        = fmt.Errorf("retention must be 0 or at least %d days", timescaleMinRetention)
+)
+
+// Timescale policies of the data hypertable, they are applied only when the TimescaleDB extension is installed
+type Timescale struct {
+	enabled       bool
+	compressAfter int
+	retention     int
+}
+
+// Enabled report whether the hypertable is set up on the startup when the TimescaleDB extension is installed
+func (t *Timescale) Enabled() bool {
+	return t.enabled
+}
+
+// CompressAfter return the age of the data chunks to be compressed, zero disables the compression
+func (t *Timescale) CompressAfter() time.Duration {
+	return time.Duration(t.compressAfter) * 24 * time.Hour
+}
+
+// Retention return the age of the data chunks to be dropped, zero keeps the data forever
+func (t *Timescale) Retention() time.Duration {
+	return time.Duration(t.retention) * 24 * time.Hour
+}
+
+func (t *Timescale) Validate() error {
+	if t.compressAfter < 0 {
+		return fmt.Errorf("timescale: %w", errNegativeCompressAfter)
+	}
+
+	// the continuous aggregates are refreshed over the last days, the raw data must outlive the refresh window
+	if t.retention != 0 && t.retention < timescaleMinRetention {
+		return fmt.Errorf("timescale: %w", errWrongRetention)
+	}
+
+	return nil
+}
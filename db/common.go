@@ -4,24 +4,44 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
 	"strings"
+	"time"
 
 	"github.com/streamdp/ccd/config"
+	"github.com/streamdp/ccd/db/memory"
 	"github.com/streamdp/ccd/db/mysql"
 	"github.com/streamdp/ccd/db/postgresql"
+	"github.com/streamdp/ccd/db/sqlite"
 	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/migrate"
 )
 
 // Database interface makes it possible to expand the list of data storages
 type Database interface {
 	Insert(ctx context.Context, data *domain.Data) (result sql.Result, err error)
-	GetLast(ctx context.Context, from, to string) (result *domain.Data, err error)
+	GetLast(ctx context.Context, provider, from, to string) (result *domain.Data, err error)
+	GetRange(ctx context.Context, q *domain.RangeQuery) (result []*domain.Data, err error)
 	DataPipe() chan *domain.Data
 
 	Close() error
 }
 
+// SchemaMigrator is implemented by the databases with the embedded schema migrations
+type SchemaMigrator interface {
+	Migrator() (*migrate.Migrator, error)
+}
+
+// Backfiller is implemented by the databases filling in the columns added by the migrations to the existing rows, it
+// runs in the background after the migrations
+type Backfiller interface {
+	Backfill(ctx context.Context) (int64, error)
+}
+
+// Timescaler is implemented by the databases able to use the TimescaleDB extension
+type Timescaler interface {
+	Timescale(ctx context.Context, compressAfter, retention time.Duration) (bool, error)
+}
+
 func Connect(cfg *config.App) (any, error) {
 	var (
 		database any
@@ -32,6 +52,10 @@ func Connect(cfg *config.App) (any, error) {
 	switch driverName {
 	case postgresql.Postgres, "postgresql":
 		database, err = postgresql.Connect(cfg.DatabaseUrl)
+	case memory.Memory:
+		database, err = memory.Connect(connectionString)
+	case sqlite.Sqlite:
+		database, err = sqlite.Connect(connectionString)
 	case mysql.Mysql:
 		fallthrough
 	default:
@@ -45,14 +69,6 @@ func Connect(cfg *config.App) (any, error) {
 	return database, nil
 }
 
-func Serve(ctx context.Context, d Database, l *log.Logger) {
-	for data := range d.DataPipe() {
-		if _, err := d.Insert(ctx, data); err != nil {
-			l.Println(err)
-		}
-	}
-}
-
 func getDataSource(dataBaseUrl string) (string, string) {
 	driverName := mysql.Mysql
 	connectionString := dataBaseUrl
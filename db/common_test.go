@@ -23,7 +23,22 @@ func Test_getDataSource(t *testing.T) {
 			wantDriverName:       "postgresql",
 			wantConnectionString: "postgres:postgres@127.0.0.1:5432/db?sslmode=disable",
 		},
-
+		{
+			name: "get sqlite datasource",
+			args: args{
+				dataBaseUrl: "sqlite:///var/lib/ccd/ccd.db",
+			},
+			wantDriverName:       "sqlite",
+			wantConnectionString: "/var/lib/ccd/ccd.db",
+		},
+		{
+			name: "get memory datasource",
+			args: args{
+				dataBaseUrl: "memory://",
+			},
+			wantDriverName:       "memory",
+			wantConnectionString: "",
+		},
 		{
 			name: "get mysql datasource",
 			args: args{
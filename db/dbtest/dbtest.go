@@ -0,0 +1,752 @@
+// Package dbtest is the contract every storage backend is tested against, the memory backend is its reference
+// implementation
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/migrate"
+	"github.com/streamdp/ccd/pkg/sessionrepo"
+	"github.com/streamdp/ccd/pkg/symbolsrepo"
+)
+
+// Store implements all store interfaces, it is the same as db.Database extended with the session and symbols stores
+type Store interface {
+	Insert(ctx context.Context, data *domain.Data) (result sql.Result, err error)
+	GetLast(ctx context.Context, provider, from, to string) (result *domain.Data, err error)
+	GetRange(ctx context.Context, q *domain.RangeQuery) (result []*domain.Data, err error)
+	DataPipe() chan *domain.Data
+
+	sessionrepo.SessionStore
+	symbolsrepo.SymbolsStore
+}
+
+// batchInserter is the same as db.BatchInserter, the batch contract runs only for the stores implementing it
+type batchInserter interface {
+	InsertBatch(ctx context.Context, data []*domain.Data) (int64, error)
+}
+
+// alertStore is the same as alerts.Store, the alerts contract runs only for the stores implementing it
+type alertStore interface {
+	Alerts(ctx context.Context) (alerts []*domain.Alert, err error)
+	AddAlert(ctx context.Context, a *domain.Alert) (id int64, err error)
+	UpdateAlert(ctx context.Context, a *domain.Alert) (result sql.Result, err error)
+	RemoveAlert(ctx context.Context, id int64) (result sql.Result, err error)
+}
+
+// sinkStore is the same as sinks.Store, the sinks contract runs only for the stores implementing it
+type sinkStore interface {
+	Sinks(ctx context.Context) (sinks []*domain.Sink, err error)
+	AddSink(ctx context.Context, s *domain.Sink) (id int64, err error)
+	UpdateSink(ctx context.Context, s *domain.Sink) (result sql.Result, err error)
+	RemoveSink(ctx context.Context, id int64) (result sql.Result, err error)
+}
+
+// apiKeyStore is the same as apikeys.Store, the api keys contract runs only for the stores implementing it
+type apiKeyStore interface {
+	ApiKeys(ctx context.Context) (keys []*domain.ApiKey, err error)
+	AddApiKey(ctx context.Context, k *domain.ApiKey) (id int64, err error)
+	UpdateApiKey(ctx context.Context, k *domain.ApiKey) (result sql.Result, err error)
+	RemoveApiKey(ctx context.Context, id int64) (result sql.Result, err error)
+}
+
+// budgetStore is the same as clients.BudgetStore, the budgets contract runs only for the stores implementing it
+type budgetStore interface {
+	Budgets(ctx context.Context) (budgets []*domain.Budget, err error)
+	SaveBudget(ctx context.Context, b *domain.Budget) (result sql.Result, err error)
+}
+
+// Run the contract, newStore must return the empty store with the default symbols, it is called for every subtest
+func Run(t *testing.T, newStore func(t *testing.T) Store) {
+	t.Helper()
+
+	t.Run("Insert", func(t *testing.T) { testInsert(t, newStore(t)) })
+	t.Run("GetLast", func(t *testing.T) { testGetLast(t, newStore(t)) })
+	t.Run("GetRange", func(t *testing.T) { testGetRange(t, newStore(t)) })
+	t.Run("InsertBatch", func(t *testing.T) {
+		b, ok := newStore(t).(batchInserter)
+		if !ok {
+			t.Skip("store has no batch inserts")
+		}
+
+		testInsertBatch(t, b)
+	})
+	t.Run("Alerts", func(t *testing.T) {
+		a, ok := newStore(t).(alertStore)
+		if !ok {
+			t.Skip("store has no alerts")
+		}
+
+		testAlerts(t, a)
+	})
+	t.Run("Sinks", func(t *testing.T) {
+		s, ok := newStore(t).(sinkStore)
+		if !ok {
+			t.Skip("store has no sinks")
+		}
+
+		testSinks(t, s)
+	})
+	t.Run("ApiKeys", func(t *testing.T) {
+		s, ok := newStore(t).(apiKeyStore)
+		if !ok {
+			t.Skip("store has no api keys")
+		}
+
+		testApiKeys(t, s)
+	})
+	t.Run("Budgets", func(t *testing.T) {
+		b, ok := newStore(t).(budgetStore)
+		if !ok {
+			t.Skip("store has no budgets")
+		}
+
+		testBudgets(t, b)
+	})
+	t.Run("Session", func(t *testing.T) { testSession(t, newStore(t)) })
+	t.Run("Symbols", func(t *testing.T) { testSymbols(t, newStore(t)) })
+}
+
+// baselineTables are never rolled back by the migrations, so they are dropped by hand
+var baselineTables = []string{"session", "symbols", "data", "schema_migrations"}
+
+// Migrate the empty schema, all applied migrations are rolled back and the baseline tables are dropped first, so the
+// store of the shared database server is empty as well
+func Migrate(t *testing.T, d *sql.DB, m *migrate.Migrator) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	for {
+		_, err := m.Down(ctx)
+		if errors.Is(err, migrate.ErrNothingToRollBack) || errors.Is(err, migrate.ErrIrreversible) {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("Down() error = %v", err)
+		}
+	}
+
+	for _, table := range baselineTables {
+		if _, err := d.ExecContext(ctx, "drop table if exists "+table); err != nil {
+			t.Fatalf("failed to drop %s: %v", table, err)
+		}
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+}
+
+func testInsert(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	if _, err := s.Insert(ctx, nil); err == nil {
+		t.Error("Insert() of nil data error = nil")
+	}
+
+	if _, err := s.Insert(ctx, &domain.Data{FromSymbol: "FOO", ToSymbol: "USDT", LastUpdate: 1747644163}); err == nil {
+		t.Error("Insert() of the unknown symbol error = nil")
+	}
+
+	want := &domain.Data{
+		FromSymbol:      "BTC",
+		ToSymbol:        "USDT",
+		Change24Hour:    1,
+		ChangePct24Hour: 2,
+		Open24Hour:      3,
+		Volume24Hour:    4,
+		Low24Hour:       5,
+		High24Hour:      6,
+		Price:           7,
+		Supply:          8,
+		MktCap:          9,
+		LastUpdate:      1747644163,
+		DisplayDataRaw:  `{"price":7}`,
+		Provider:        "huobi",
+	}
+
+	if _, err := s.Insert(ctx, want); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	got, err := s.GetLast(ctx, "", "BTC", "USDT")
+	if err != nil {
+		t.Fatalf("GetLast() error = %v", err)
+	}
+
+	want.Id = got.Id
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetLast() got = %v, want %v", got, want)
+	}
+}
+
+func testGetLast(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	if _, err := s.GetLast(ctx, "", "BTC", "USDT"); err == nil {
+		t.Error("GetLast() of the empty store error = nil")
+	}
+
+	for _, data := range []*domain.Data{
+		{FromSymbol: "BTC", ToSymbol: "USDT", Price: 101, LastUpdate: 1747644164, Provider: "kraken"},
+		{FromSymbol: "BTC", ToSymbol: "USDT", Price: 100, LastUpdate: 1747644163, Provider: "huobi"},
+		{FromSymbol: "ETH", ToSymbol: "USDT", Price: 10, LastUpdate: 1747644165, Provider: "kraken"},
+		{FromSymbol: "ETH", ToSymbol: "USDT", Price: 12, LastUpdate: 1747644166, Provider: "huobi"},
+		{FromSymbol: "ETH", ToSymbol: "USDT", Price: 11, LastUpdate: 1747644165500, Provider: "huobi"},
+	} {
+		if _, err := s.Insert(ctx, data); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+	}
+
+	got, err := s.GetLast(ctx, "", "BTC", "USDT")
+	if err != nil {
+		t.Fatalf("GetLast() error = %v", err)
+	}
+
+	if got.Price != 101 || got.LastUpdate != 1747644164 || got.Provider != "kraken" {
+		t.Errorf("GetLast() got = %v, want the row updated at 1747644164", got)
+	}
+
+	if got, err = s.GetLast(ctx, "huobi", "BTC", "USDT"); err != nil || got.Price != 100 {
+		t.Errorf("GetLast() of the provider got = %v, %v, want the huobi row", got, err)
+	}
+
+	// the seconds and milliseconds rows are ordered by the row time
+	if got, err = s.GetLast(ctx, "", "ETH", "USDT"); err != nil || got.Price != 12 {
+		t.Errorf("GetLast() of the mixed timestamps got = %v, %v, want the row updated at 1747644166", got, err)
+	}
+
+	if _, err = s.GetLast(ctx, "binance", "BTC", "USDT"); err == nil {
+		t.Error("GetLast() of the missing provider error = nil")
+	}
+
+	if _, err = s.GetLast(ctx, "", "XRP", "USDT"); err == nil {
+		t.Error("GetLast() of the missing pair error = nil")
+	}
+}
+
+func testGetRange(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	if _, err := s.GetRange(ctx, nil); err == nil {
+		t.Error("GetRange() of nil query error = nil")
+	}
+
+	// the rows are saved with both seconds and milliseconds timestamps, the range is always in milliseconds
+	lastUpdates := []int64{1747644161, 1747644162000, 1747644163, 1747644164000}
+	for _, lastUpdate := range lastUpdates {
+		if _, err := s.Insert(ctx, &domain.Data{FromSymbol: "BTC", ToSymbol: "USDT", LastUpdate: lastUpdate}); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+	}
+
+	// the legacy rows are stored without the provider, they match any provider
+	for _, data := range []*domain.Data{
+		{FromSymbol: "ETH", ToSymbol: "USDT", LastUpdate: 1747644162},
+		{FromSymbol: "ETH", ToSymbol: "USDT", LastUpdate: 1747644163, Provider: "kraken"},
+		{FromSymbol: "ETH", ToSymbol: "USDT", LastUpdate: 1747644164, Provider: "consolidated"},
+	} {
+		if _, err := s.Insert(ctx, data); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+	}
+
+	all, err := s.GetRange(ctx, &domain.RangeQuery{From: "BTC", To: "USDT", Limit: 10})
+	if err != nil {
+		t.Fatalf("GetRange() error = %v", err)
+	}
+
+	if got := rangeLastUpdates(all); !reflect.DeepEqual(got, lastUpdates) {
+		t.Fatalf("GetRange() got = %v, want %v", got, lastUpdates)
+	}
+
+	tests := []struct {
+		name string
+		q    *domain.RangeQuery
+		want []int64
+	}{
+		{
+			name: "range in milliseconds",
+			q:    &domain.RangeQuery{From: "BTC", To: "USDT", Start: 1747644162000, End: 1747644163000, Limit: 10},
+			want: []int64{1747644162000, 1747644163},
+		},
+		{
+			name: "page with the extra row",
+			q:    &domain.RangeQuery{From: "BTC", To: "USDT", Limit: 1},
+			want: []int64{1747644161, 1747644162000},
+		},
+		{
+			name: "next page",
+			q: &domain.RangeQuery{
+				From:   "BTC",
+				To:     "USDT",
+				Limit:  1,
+				Cursor: &domain.Cursor{LastUpdate: 1747644162000, Id: all[1].Id},
+			},
+			want: []int64{1747644163, 1747644164000},
+		},
+		{
+			name: "rows of the provider",
+			q:    &domain.RangeQuery{Provider: "kraken", From: "ETH", To: "USDT", Limit: 10},
+			want: []int64{1747644162, 1747644163},
+		},
+		{
+			name: "rows of all providers",
+			q:    &domain.RangeQuery{From: "ETH", To: "USDT", Limit: 10},
+			want: []int64{1747644162, 1747644163, 1747644164},
+		},
+		{
+			name: "missing pair",
+			q:    &domain.RangeQuery{From: "XRP", To: "USDT", Limit: 10},
+			want: []int64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, errRange := s.GetRange(ctx, tt.q)
+			if errRange != nil {
+				t.Fatalf("GetRange() error = %v", errRange)
+			}
+
+			if got := rangeLastUpdates(rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetRange() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func testInsertBatch(t *testing.T, b batchInserter) {
+	ctx := context.Background()
+
+	n, err := b.InsertBatch(ctx, []*domain.Data{
+		{FromSymbol: "BTC", ToSymbol: "USDT", Price: 100, LastUpdate: 1747644163},
+		{FromSymbol: "FOO", ToSymbol: "USDT", Price: 1, LastUpdate: 1747644163},
+		{FromSymbol: "eth", ToSymbol: "usdt", Price: 10, LastUpdate: 1747644164},
+	})
+	if err == nil {
+		t.Error("InsertBatch() with the unknown symbol error = nil")
+	}
+
+	if n != 2 {
+		t.Errorf("InsertBatch() = %v, want 2", n)
+	}
+
+	s, ok := b.(Store)
+	if !ok {
+		return
+	}
+
+	got, err := s.GetLast(ctx, "", "ETH", "USDT")
+	if err != nil {
+		t.Fatalf("GetLast() error = %v", err)
+	}
+
+	if got.Price != 10 {
+		t.Errorf("GetLast() price = %v, want 10", got.Price)
+	}
+}
+
+func testAlerts(t *testing.T, s alertStore) {
+	ctx := context.Background()
+
+	want := []*domain.Alert{
+		{From: "BTC", To: "USDT", Rule: domain.AlertPriceAbove, Threshold: 100000, CoolDown: 300},
+		{
+			From:       "ETH",
+			To:         "USDT",
+			Rule:       domain.AlertPercentMove,
+			Threshold:  5,
+			Window:     3600,
+			CoolDown:   600,
+			WebhookUrl: "https://example.com/hook",
+		},
+	}
+
+	for _, a := range want {
+		id, err := s.AddAlert(ctx, a)
+		if err != nil {
+			t.Fatalf("AddAlert() error = %v", err)
+		}
+
+		a.Id = id
+	}
+
+	if want[0].Id == want[1].Id {
+		t.Errorf("AddAlert() returned the same id %d twice", want[0].Id)
+	}
+
+	want[0].Threshold = 110000
+	if _, err := s.UpdateAlert(ctx, want[0]); err != nil {
+		t.Fatalf("UpdateAlert() error = %v", err)
+	}
+
+	// the unchanged row is counted, so the missing alert is told apart from the one updated with the same values
+	unchanged, err := s.UpdateAlert(ctx, want[0])
+	if err != nil {
+		t.Fatalf("UpdateAlert() error = %v", err)
+	}
+
+	if n, _ := unchanged.RowsAffected(); n != 1 {
+		t.Errorf("UpdateAlert() of the unchanged alert rows affected = %v, want 1", n)
+	}
+
+	got, err := s.Alerts(ctx)
+	if err != nil {
+		t.Fatalf("Alerts() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Alerts() got = %v, want %v", got, want)
+	}
+
+	result, err := s.RemoveAlert(ctx, want[0].Id)
+	if err != nil {
+		t.Fatalf("RemoveAlert() error = %v", err)
+	}
+
+	if n, _ := result.RowsAffected(); n != 1 {
+		t.Errorf("RemoveAlert() rows affected = %v, want 1", n)
+	}
+
+	if result, err = s.RemoveAlert(ctx, want[0].Id); err != nil {
+		t.Fatalf("RemoveAlert() of the missing alert error = %v", err)
+	}
+
+	if n, _ := result.RowsAffected(); n != 0 {
+		t.Errorf("RemoveAlert() of the missing alert rows affected = %v, want 0", n)
+	}
+
+	if got, err = s.Alerts(ctx); err != nil {
+		t.Fatalf("Alerts() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("Alerts() after RemoveAlert() got = %v, want %v", got, want[1:])
+	}
+}
+
+func testSinks(t *testing.T, s sinkStore) {
+	ctx := context.Background()
+
+	want := []*domain.Sink{
+		{Url: "https://example.com/ticks"},
+		{
+			Url:    "http://127.0.0.1:8081/ticks",
+			Pairs:  []domain.Pair{{From: "BTC", To: "USDT"}, {From: "ETH", To: "USDT"}},
+			Secret: "secret",
+		},
+	}
+
+	for _, sink := range want {
+		id, err := s.AddSink(ctx, sink)
+		if err != nil {
+			t.Fatalf("AddSink() error = %v", err)
+		}
+
+		sink.Id = id
+	}
+
+	if want[0].Id == want[1].Id {
+		t.Errorf("AddSink() returned the same id %d twice", want[0].Id)
+	}
+
+	want[0].Pairs = []domain.Pair{{From: "BTC", To: "USD"}}
+	if _, err := s.UpdateSink(ctx, want[0]); err != nil {
+		t.Fatalf("UpdateSink() error = %v", err)
+	}
+
+	// the unchanged row is counted, so the missing sink is told apart from the one updated with the same values
+	unchanged, err := s.UpdateSink(ctx, want[0])
+	if err != nil {
+		t.Fatalf("UpdateSink() error = %v", err)
+	}
+
+	if n, _ := unchanged.RowsAffected(); n != 1 {
+		t.Errorf("UpdateSink() of the unchanged sink rows affected = %v, want 1", n)
+	}
+
+	got, err := s.Sinks(ctx)
+	if err != nil {
+		t.Fatalf("Sinks() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sinks() got = %v, want %v", got, want)
+	}
+
+	result, err := s.RemoveSink(ctx, want[0].Id)
+	if err != nil {
+		t.Fatalf("RemoveSink() error = %v", err)
+	}
+
+	if n, _ := result.RowsAffected(); n != 1 {
+		t.Errorf("RemoveSink() rows affected = %v, want 1", n)
+	}
+
+	if result, err = s.RemoveSink(ctx, want[0].Id); err != nil {
+		t.Fatalf("RemoveSink() of the missing sink error = %v", err)
+	}
+
+	if n, _ := result.RowsAffected(); n != 0 {
+		t.Errorf("RemoveSink() of the missing sink rows affected = %v, want 0", n)
+	}
+
+	if got, err = s.Sinks(ctx); err != nil {
+		t.Fatalf("Sinks() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("Sinks() after RemoveSink() got = %v, want %v", got, want[1:])
+	}
+}
+
+func testApiKeys(t *testing.T, s apiKeyStore) {
+	ctx := context.Background()
+
+	want := []*domain.ApiKey{
+		{
+			Name:    "admin",
+			Prefix:  "ccd_AAAAAAAA",
+			Hash:    strings.Repeat("a", 64),
+			Scopes:  []string{domain.ScopeAdmin},
+			Created: 1747659413,
+		},
+		{
+			Name:    "dashboard",
+			Prefix:  "ccd_BBBBBBBB",
+			Hash:    strings.Repeat("b", 64),
+			Scopes:  []string{domain.ScopeRead, domain.ScopeWs},
+			Created: 1747659414,
+		},
+	}
+
+	for _, key := range want {
+		id, err := s.AddApiKey(ctx, key)
+		if err != nil {
+			t.Fatalf("AddApiKey() error = %v", err)
+		}
+
+		key.Id = id
+	}
+
+	if want[0].Id == want[1].Id {
+		t.Errorf("AddApiKey() returned the same id %d twice", want[0].Id)
+	}
+
+	// the key is rotated
+	want[0].Prefix = "ccd_CCCCCCCC"
+	want[0].Hash = strings.Repeat("c", 64)
+	if _, err := s.UpdateApiKey(ctx, want[0]); err != nil {
+		t.Fatalf("UpdateApiKey() error = %v", err)
+	}
+
+	got, err := s.ApiKeys(ctx)
+	if err != nil {
+		t.Fatalf("ApiKeys() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ApiKeys() got = %v, want %v", got, want)
+	}
+
+	result, err := s.RemoveApiKey(ctx, want[0].Id)
+	if err != nil {
+		t.Fatalf("RemoveApiKey() error = %v", err)
+	}
+
+	if n, _ := result.RowsAffected(); n != 1 {
+		t.Errorf("RemoveApiKey() rows affected = %v, want 1", n)
+	}
+
+	if result, err = s.RemoveApiKey(ctx, want[0].Id); err != nil {
+		t.Fatalf("RemoveApiKey() of the missing key error = %v", err)
+	}
+
+	if n, _ := result.RowsAffected(); n != 0 {
+		t.Errorf("RemoveApiKey() of the missing key rows affected = %v, want 0", n)
+	}
+
+	if got, err = s.ApiKeys(ctx); err != nil {
+		t.Fatalf("ApiKeys() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("ApiKeys() after RemoveApiKey() got = %v, want %v", got, want[1:])
+	}
+}
+
+func testBudgets(t *testing.T, s budgetStore) {
+	ctx := context.Background()
+
+	if _, err := s.SaveBudget(ctx, nil); err == nil {
+		t.Error("SaveBudget() of nil error = nil")
+	}
+
+	// the saved budget of the data provider is replaced
+	for _, b := range []*domain.Budget{
+		{Provider: "kraken", Used: 10, ResetAt: 1748736000},
+		{Provider: "cryptocompare", Used: 5, ResetAt: 1748736000},
+		{Provider: "kraken", Used: 12, ResetAt: 1748736000},
+	} {
+		if _, err := s.SaveBudget(ctx, b); err != nil {
+			t.Fatalf("SaveBudget() error = %v", err)
+		}
+	}
+
+	got, err := s.Budgets(ctx)
+	if err != nil {
+		t.Fatalf("Budgets() error = %v", err)
+	}
+
+	want := []*domain.Budget{
+		{Provider: "cryptocompare", Used: 5, ResetAt: 1748736000},
+		{Provider: "kraken", Used: 12, ResetAt: 1748736000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Budgets() got = %v, want %v", got, want)
+	}
+}
+
+func testSession(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	if _, err := s.AddTask(ctx, "", 60); err == nil {
+		t.Error("AddTask() with the empty name error = nil")
+	}
+
+	if _, err := s.RemoveTask(ctx, ""); err == nil {
+		t.Error("RemoveTask() with the empty name error = nil")
+	}
+
+	for _, n := range []string{"huobi:btc:usdt", "kraken:eth:usdt", "huobi:btc:usdt"} {
+		if _, err := s.AddTask(ctx, n, 60); err != nil {
+			t.Fatalf("AddTask() error = %v", err)
+		}
+	}
+
+	if _, err := s.UpdateTask(ctx, "kraken:eth:usdt", 30); err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+
+	// the unchanged task is counted, the missing one is not
+	for n, want := range map[string]int64{"kraken:eth:usdt": 1, "kraken:xrp:usdt": 0} {
+		result, err := s.UpdateTask(ctx, n, 30)
+		if err != nil {
+			t.Fatalf("UpdateTask() error = %v", err)
+		}
+
+		if got, _ := result.RowsAffected(); got != want {
+			t.Errorf("UpdateTask() of %s rows affected = %v, want %v", n, got, want)
+		}
+	}
+
+	got, err := s.GetSession(ctx)
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+
+	want := map[string]int64{"HUOBI:BTC:USDT": 60, "KRAKEN:ETH:USDT": 30}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetSession() got = %v, want %v", got, want)
+	}
+
+	if _, err = s.RemoveTask(ctx, "huobi:btc:usdt"); err != nil {
+		t.Fatalf("RemoveTask() error = %v", err)
+	}
+
+	if got, err = s.GetSession(ctx); err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+
+	want = map[string]int64{"KRAKEN:ETH:USDT": 30}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetSession() after RemoveTask() got = %v, want %v", got, want)
+	}
+}
+
+func testSymbols(t *testing.T, s Store) {
+	if _, err := s.AddSymbol("", ""); err == nil {
+		t.Error("AddSymbol() with the empty symbol error = nil")
+	}
+
+	if _, err := s.AddSymbol("btc", "₿"); err == nil {
+		t.Error("AddSymbol() of the existing symbol error = nil")
+	}
+
+	if _, err := s.AddSymbol("foo", "f"); err != nil {
+		t.Fatalf("AddSymbol() error = %v", err)
+	}
+
+	if _, err := s.UpdateSymbol("foo", "ƒ"); err != nil {
+		t.Fatalf("UpdateSymbol() error = %v", err)
+	}
+
+	// the unchanged symbol is counted, the missing one is not
+	for symbol, want := range map[string]int64{"foo": 1, "bar": 0} {
+		result, err := s.UpdateSymbol(symbol, "ƒ")
+		if err != nil {
+			t.Fatalf("UpdateSymbol() error = %v", err)
+		}
+
+		if got, _ := result.RowsAffected(); got != want {
+			t.Errorf("UpdateSymbol() of %s rows affected = %v, want %v", symbol, got, want)
+		}
+	}
+
+	got, err := symbols(s)
+	if err != nil {
+		t.Fatalf("Symbols() error = %v", err)
+	}
+
+	if got["BTC"] != '₿' || got["USDT"] != '₮' {
+		t.Errorf("Symbols() got = %v, want the default symbols", got)
+	}
+
+	if got["FOO"] != 'Ƒ' {
+		t.Errorf("Symbols() FOO = %q, want %q", got["FOO"], 'Ƒ')
+	}
+
+	if _, err = s.RemoveSymbol("foo"); err != nil {
+		t.Fatalf("RemoveSymbol() error = %v", err)
+	}
+
+	if got, err = symbols(s); err != nil {
+		t.Fatalf("Symbols() error = %v", err)
+	}
+
+	if _, ok := got["FOO"]; ok {
+		t.Error("Symbols() after RemoveSymbol() got FOO")
+	}
+}
+
+func symbols(s Store) (map[string]rune, error) {
+	list, err := s.Symbols()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]rune, len(list))
+	for _, symbol := range list {
+		res[symbol.Symbol] = symbol.Unicode
+	}
+
+	return res, nil
+}
+
+func rangeLastUpdates(rows []*domain.Data) []int64 {
+	res := make([]int64, len(rows))
+	for i := range rows {
+		res[i] = rows[i].LastUpdate
+	}
+
+	return res
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+var errEmptyAlert = errors.New("empty alert")
+
+// Alerts return all alert rules ordered by id
+func (d *Db) Alerts(_ context.Context) ([]*domain.Alert, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	alerts := make([]*domain.Alert, 0, len(d.alerts))
+	for _, id := range slices.Sorted(maps.Keys(d.alerts)) {
+		a := *d.alerts[id]
+		alerts = append(alerts, &a)
+	}
+
+	return alerts, nil
+}
+
+// AddAlert rule and return its id
+func (d *Db) AddAlert(_ context.Context, a *domain.Alert) (int64, error) {
+	if a == nil {
+		return 0, errEmptyAlert
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.alertId++
+
+	alert := *a
+	alert.Id = d.alertId
+	d.alerts[alert.Id] = &alert
+
+	return alert.Id, nil
+}
+
+// UpdateAlert rule with the id of the given one
+func (d *Db) UpdateAlert(_ context.Context, a *domain.Alert) (sql.Result, error) {
+	if a == nil {
+		return nil, errEmptyAlert
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.alerts[a.Id]; !ok {
+		return result{}, nil
+	}
+
+	alert := *a
+	d.alerts[alert.Id] = &alert
+
+	return result{rowsAffected: 1}, nil
+}
+
+// RemoveAlert rule by id
+func (d *Db) RemoveAlert(_ context.Context, id int64) (sql.Result, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.alerts[id]; !ok {
+		return result{}, nil
+	}
+
+	delete(d.alerts, id)
+
+	return result{rowsAffected: 1}, nil
+}
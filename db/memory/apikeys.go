@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+var errEmptyApiKey = errors.New("empty api key")
+
+// ApiKeys return all api keys ordered by id
+func (d *Db) ApiKeys(_ context.Context) ([]*domain.ApiKey, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	keys := make([]*domain.ApiKey, 0, len(d.apiKeys))
+	for _, id := range slices.Sorted(maps.Keys(d.apiKeys)) {
+		keys = append(keys, copyApiKey(d.apiKeys[id]))
+	}
+
+	return keys, nil
+}
+
+// AddApiKey and return its id
+func (d *Db) AddApiKey(_ context.Context, k *domain.ApiKey) (int64, error) {
+	if k == nil {
+		return 0, errEmptyApiKey
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.apiKeyId++
+
+	key := copyApiKey(k)
+	key.Id = d.apiKeyId
+	d.apiKeys[key.Id] = key
+
+	return key.Id, nil
+}
+
+// UpdateApiKey with the id of the given one
+func (d *Db) UpdateApiKey(_ context.Context, k *domain.ApiKey) (sql.Result, error) {
+	if k == nil {
+		return nil, errEmptyApiKey
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.apiKeys[k.Id]; !ok {
+		return result{}, nil
+	}
+
+	d.apiKeys[k.Id] = copyApiKey(k)
+
+	return result{rowsAffected: 1}, nil
+}
+
+// RemoveApiKey by id
+func (d *Db) RemoveApiKey(_ context.Context, id int64) (sql.Result, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.apiKeys[id]; !ok {
+		return result{}, nil
+	}
+
+	delete(d.apiKeys, id)
+
+	return result{rowsAffected: 1}, nil
+}
+
+func copyApiKey(k *domain.ApiKey) *domain.ApiKey {
+	key := *k
+	key.Scopes = slices.Clone(k.Scopes)
+
+	return &key
+}
@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+var errEmptyBudget = errors.New("empty budget")
+
+// Budgets return the used monthly budgets of all data providers ordered by the provider name
+func (d *Db) Budgets(_ context.Context) ([]*domain.Budget, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	budgets := make([]*domain.Budget, 0, len(d.budgets))
+	for _, provider := range slices.Sorted(maps.Keys(d.budgets)) {
+		b := d.budgets[provider]
+		budgets = append(budgets, &b)
+	}
+
+	return budgets, nil
+}
+
+// SaveBudget of the data provider, it replaces the saved one
+func (d *Db) SaveBudget(_ context.Context, b *domain.Budget) (sql.Result, error) {
+	if b == nil {
+		return nil, errEmptyBudget
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.budgets[b.Provider] = *b
+
+	return result{rowsAffected: 1}, nil
+}
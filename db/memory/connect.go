@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+const (
+	Memory = "memory"
+
+	defaultCapacity = 1000
+)
+
+var errWrongCapacity = errors.New("capacity should be positive")
+
+// Db keeps the data, symbols, session, alerts, sinks, api keys and budgets in memory, only the last rows of every pair are kept
+// in the ring buffers, everything is lost on exit
+type Db struct {
+	capacity int
+
+	data     map[domain.Pair]*ring
+	lastId   int64
+	symbols  map[string]rune
+	session  map[string]int64
+	alerts   map[int64]*domain.Alert
+	alertId  int64
+	sinks    map[int64]*domain.Sink
+	sinkId   int64
+	apiKeys  map[int64]*domain.ApiKey
+	apiKeyId int64
+	budgets  map[string]domain.Budget
+	mu       sync.RWMutex
+
+	pipe chan *domain.Data
+}
+
+// result of the statement, it mirrors the sql.Result of the sql databases
+type result struct {
+	lastInsertId int64
+	rowsAffected int64
+}
+
+func (r result) LastInsertId() (int64, error) {
+	return r.lastInsertId, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+func (d *Db) DataPipe() chan *domain.Data {
+	return d.pipe
+}
+
+// Connect create the empty store with the default symbols, the number of rows kept per pair is set with the
+// "capacity" option, e.g. "?capacity=5000"
+func Connect(dataSource string) (*Db, error) {
+	options, err := url.ParseQuery(strings.TrimPrefix(dataSource, "?"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse options: %w", err)
+	}
+
+	capacity := defaultCapacity
+	if c := options.Get("capacity"); c != "" {
+		if capacity, err = strconv.Atoi(c); err != nil {
+			return nil, fmt.Errorf("failed to parse capacity: %w", err)
+		}
+
+		if capacity <= 0 {
+			return nil, errWrongCapacity
+		}
+	}
+
+	d := &Db{
+		capacity: capacity,
+		data:     make(map[domain.Pair]*ring),
+		symbols:  make(map[string]rune, len(defaultSymbols)),
+		session:  make(map[string]int64),
+		alerts:   make(map[int64]*domain.Alert),
+		sinks:    make(map[int64]*domain.Sink),
+		apiKeys:  make(map[int64]*domain.ApiKey),
+		budgets:  make(map[string]domain.Budget),
+		pipe:     make(chan *domain.Data, 1000),
+	}
+
+	for _, s := range defaultSymbols {
+		d.symbols[s.Symbol] = s.Unicode
+	}
+
+	return d, nil
+}
+
+// Close the store, the data pipe must not be used afterward
+func (d *Db) Close() error {
+	close(d.pipe)
+
+	return nil
+}
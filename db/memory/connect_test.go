@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/streamdp/ccd/db/dbtest"
+	"github.com/streamdp/ccd/domain"
+)
+
+func TestDb(t *testing.T) {
+	dbtest.Run(t, func(t *testing.T) dbtest.Store {
+		t.Helper()
+
+		d, err := Connect("")
+		if err != nil {
+			t.Fatalf("Connect() error = %v", err)
+		}
+
+		t.Cleanup(func() { _ = d.Close() })
+
+		return d
+	})
+}
+
+func TestConnect(t *testing.T) {
+	tests := []struct {
+		name         string
+		dataSource   string
+		wantCapacity int
+		wantErr      bool
+	}{
+		{
+			name:         "default capacity",
+			dataSource:   "",
+			wantCapacity: defaultCapacity,
+		},
+		{
+			name:         "capacity option",
+			dataSource:   "?capacity=5000",
+			wantCapacity: 5000,
+		},
+		{
+			name:       "wrong capacity",
+			dataSource: "?capacity=0",
+			wantErr:    true,
+		},
+		{
+			name:       "capacity is not a number",
+			dataSource: "?capacity=many",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Connect(tt.dataSource)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Connect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			t.Cleanup(func() { _ = got.Close() })
+
+			if got.capacity != tt.wantCapacity {
+				t.Errorf("Connect() capacity = %v, want %v", got.capacity, tt.wantCapacity)
+			}
+		})
+	}
+}
+
+func TestDb_ring(t *testing.T) {
+	d, err := Connect("?capacity=2")
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	t.Cleanup(func() { _ = d.Close() })
+
+	ctx := context.Background()
+
+	for _, lastUpdate := range []int64{1747644161, 1747644162, 1747644163} {
+		if _, err = d.Insert(ctx, &domain.Data{FromSymbol: "BTC", ToSymbol: "USDT", LastUpdate: lastUpdate}); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+	}
+
+	rows, err := d.GetRange(ctx, &domain.RangeQuery{From: "BTC", To: "USDT", Limit: 10})
+	if err != nil {
+		t.Fatalf("GetRange() error = %v", err)
+	}
+
+	got := make([]int64, len(rows))
+	for i := range rows {
+		got[i] = rows[i].LastUpdate
+	}
+
+	if want := []int64{1747644162, 1747644163}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetRange() got = %v, want %v", got, want)
+	}
+}
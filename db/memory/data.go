@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/streamdp/ccd/db/symbolid"
+	"github.com/streamdp/ccd/domain"
+)
+
+var (
+	errEmptyData  = errors.New("cant insert empty data")
+	errEmptyQuery = errors.New("empty query")
+	errNoData     = errors.New("no data for the pair")
+)
+
+// ring buffer of the last rows of the pair, the oldest row is overwritten when it is full
+type ring struct {
+	rows []*domain.Data
+	next int
+}
+
+func newRing(capacity int) *ring {
+	return &ring{rows: make([]*domain.Data, 0, capacity)}
+}
+
+func (r *ring) add(data *domain.Data) {
+	if len(r.rows) < cap(r.rows) {
+		r.rows = append(r.rows, data)
+
+		return
+	}
+
+	r.rows[r.next] = data
+	r.next = (r.next + 1) % len(r.rows)
+}
+
+// GetLast row with the most recent data of the data provider for the selected currencies pair, the row of any data
+// provider is returned when the provider is empty, the rows are ordered by the row time in milliseconds
+func (d *Db) GetLast(_ context.Context, provider, from, to string) (*domain.Data, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var last *domain.Data
+
+	if r, ok := d.data[buildPair(from, to)]; ok {
+		for _, data := range r.rows {
+			if !matchProvider(data.Provider, provider) {
+				continue
+			}
+
+			if last == nil || compareRows(
+				domain.MilliTimestamp(data.LastUpdate), data.Id, domain.MilliTimestamp(last.LastUpdate), last.Id,
+			) > 0 {
+				last = data
+			}
+		}
+	}
+
+	if last == nil {
+		return nil, fmt.Errorf("%w: %s/%s", errNoData, from, to)
+	}
+
+	return copyRow(last, from, to), nil
+}
+
+// Insert clients.Data from the clients.DataPipe to the Db
+func (d *Db) Insert(_ context.Context, data *domain.Data) (sql.Result, error) {
+	if data == nil {
+		return nil, errEmptyData
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.insert(data); err != nil {
+		return nil, err
+	}
+
+	return result{lastInsertId: d.lastId, rowsAffected: 1}, nil
+}
+
+// InsertBatch of the rows, rows with unknown symbols are skipped, the number of the inserted rows is returned
+func (d *Db) InsertBatch(_ context.Context, data []*domain.Data) (int64, error) {
+	var (
+		n    int64
+		errs []error
+	)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, row := range data {
+		if err := d.insert(row); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		n++
+	}
+
+	return n, errors.Join(errs...)
+}
+
+// GetRange rows for the selected currencies pair ordered by the lastupdate, one extra row is fetched to detect the
+// next page
+func (d *Db) GetRange(_ context.Context, q *domain.RangeQuery) ([]*domain.Data, error) {
+	if q == nil {
+		return nil, errEmptyQuery
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	r, ok := d.data[buildPair(q.From, q.To)]
+	if !ok {
+		return nil, nil
+	}
+
+	var rows []*domain.Data
+
+	for _, data := range r.rows {
+		if !matchProvider(data.Provider, q.Provider) {
+			continue
+		}
+
+		ts := domain.MilliTimestamp(data.LastUpdate)
+		if ts < q.Start || q.End != 0 && ts > q.End {
+			continue
+		}
+
+		if q.Cursor != nil && compareRows(ts, data.Id, q.Cursor.LastUpdate, q.Cursor.Id) <= 0 {
+			continue
+		}
+
+		rows = append(rows, data)
+	}
+
+	slices.SortFunc(rows, func(a, b *domain.Data) int {
+		return compareRows(domain.MilliTimestamp(a.LastUpdate), a.Id, domain.MilliTimestamp(b.LastUpdate), b.Id)
+	})
+
+	if len(rows) > q.Limit+1 {
+		rows = rows[:q.Limit+1]
+	}
+
+	result := make([]*domain.Data, len(rows))
+	for i := range rows {
+		result[i] = copyRow(rows[i], q.From, q.To)
+	}
+
+	return result, nil
+}
+
+// insert the copy of the row, the caller must hold the lock
+func (d *Db) insert(data *domain.Data) error {
+	for _, s := range []string{data.FromSymbol, data.ToSymbol} {
+		if _, ok := d.symbols[strings.ToUpper(s)]; !ok {
+			return fmt.Errorf("%w: %s", symbolid.ErrUnknownSymbol, s)
+		}
+	}
+
+	p := buildPair(data.FromSymbol, data.ToSymbol)
+
+	r, ok := d.data[p]
+	if !ok {
+		r = newRing(d.capacity)
+		d.data[p] = r
+	}
+
+	d.lastId++
+
+	row := *data
+	row.Id = d.lastId
+	row.FromSymbol, row.ToSymbol = p.From, p.To
+	row.SetTrace("")
+
+	r.add(&row)
+
+	return nil
+}
+
+// copyRow so the stored row is not changed by the caller
+func copyRow(data *domain.Data, from, to string) *domain.Data {
+	row := *data
+	row.FromSymbol, row.ToSymbol = from, to
+
+	return &row
+}
+
+// matchProvider of the row, the legacy rows stored without the provider match any provider
+func matchProvider(rowProvider, provider string) bool {
+	return provider == "" || rowProvider == "" || rowProvider == provider
+}
+
+func compareRows(aLastUpdate, aId, bLastUpdate, bId int64) int {
+	if c := cmp.Compare(aLastUpdate, bLastUpdate); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(aId, bId)
+}
+
+func buildPair(from, to string) domain.Pair {
+	return domain.Pair{From: strings.ToUpper(from), To: strings.ToUpper(to)}
+}
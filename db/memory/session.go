@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"strings"
+)
+
+var (
+	errEmptyTaskName = errors.New("empty task name")
+	errEmptySymbol   = errors.New("empty symbol")
+)
+
+func (d *Db) AddTask(_ context.Context, n string, i int64) (sql.Result, error) {
+	if n == "" {
+		return nil, errEmptyTaskName
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n = strings.ToUpper(n)
+	if _, ok := d.session[n]; ok {
+		return result{}, nil
+	}
+
+	d.session[n] = i
+
+	return result{rowsAffected: 1}, nil
+}
+
+func (d *Db) UpdateTask(_ context.Context, n string, i int64) (sql.Result, error) {
+	if n == "" {
+		return nil, errEmptyTaskName
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n = strings.ToUpper(n)
+	if _, ok := d.session[n]; !ok {
+		return result{}, nil
+	}
+
+	d.session[n] = i
+
+	return result{rowsAffected: 1}, nil
+}
+
+func (d *Db) RemoveTask(_ context.Context, n string) (sql.Result, error) {
+	if n == "" {
+		return nil, errEmptyTaskName
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n = strings.ToUpper(n)
+	if _, ok := d.session[n]; !ok {
+		return result{}, nil
+	}
+
+	delete(d.session, n)
+
+	return result{rowsAffected: 1}, nil
+}
+
+func (d *Db) GetSession(_ context.Context) (map[string]int64, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return maps.Clone(d.session), nil
+}
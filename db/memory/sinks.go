@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+var errEmptySink = errors.New("empty sink")
+
+// Sinks return all data sinks ordered by id
+func (d *Db) Sinks(_ context.Context) ([]*domain.Sink, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	sinks := make([]*domain.Sink, 0, len(d.sinks))
+	for _, id := range slices.Sorted(maps.Keys(d.sinks)) {
+		sinks = append(sinks, copySink(d.sinks[id]))
+	}
+
+	return sinks, nil
+}
+
+// AddSink and return its id
+func (d *Db) AddSink(_ context.Context, s *domain.Sink) (int64, error) {
+	if s == nil {
+		return 0, errEmptySink
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sinkId++
+
+	sink := copySink(s)
+	sink.Id = d.sinkId
+	d.sinks[sink.Id] = sink
+
+	return sink.Id, nil
+}
+
+// UpdateSink with the id of the given one
+func (d *Db) UpdateSink(_ context.Context, s *domain.Sink) (sql.Result, error) {
+	if s == nil {
+		return nil, errEmptySink
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.sinks[s.Id]; !ok {
+		return result{}, nil
+	}
+
+	d.sinks[s.Id] = copySink(s)
+
+	return result{rowsAffected: 1}, nil
+}
+
+// RemoveSink by id
+func (d *Db) RemoveSink(_ context.Context, id int64) (sql.Result, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.sinks[id]; !ok {
+		return result{}, nil
+	}
+
+	delete(d.sinks, id)
+
+	return result{rowsAffected: 1}, nil
+}
+
+func copySink(s *domain.Sink) *domain.Sink {
+	sink := *s
+	sink.Pairs = slices.Clone(s.Pairs)
+
+	return &sink
+}
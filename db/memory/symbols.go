@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+var errSymbolExists = errors.New("symbol already exists")
+
+// defaultSymbols the store is created with, the same as the sql databases are seeded with
+var defaultSymbols = []domain.Symbol{
+	{Symbol: "USDT", Unicode: '₮'},
+	{Symbol: "BTC", Unicode: '₿'},
+	{Symbol: "ETH", Unicode: '⟠'},
+	{Symbol: "USD", Unicode: '$'},
+	{Symbol: "XRP", Unicode: '✕'},
+	{Symbol: "LTC", Unicode: 'Ł'},
+	{Symbol: "EUR", Unicode: '€'},
+	{Symbol: "GBP", Unicode: '£'},
+	{Symbol: "JPY", Unicode: '¥'},
+}
+
+func (d *Db) AddSymbol(s, u string) (sql.Result, error) {
+	if s == "" {
+		return nil, errEmptySymbol
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s = strings.ToUpper(s)
+	if _, ok := d.symbols[s]; ok {
+		return nil, fmt.Errorf("%w: %s", errSymbolExists, s)
+	}
+
+	d.symbols[s], _ = utf8.DecodeRuneInString(strings.ToUpper(u))
+
+	return result{rowsAffected: 1}, nil
+}
+
+func (d *Db) UpdateSymbol(s, u string) (sql.Result, error) {
+	if s == "" {
+		return nil, errEmptySymbol
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s = strings.ToUpper(s)
+	if _, ok := d.symbols[s]; !ok {
+		return result{}, nil
+	}
+
+	d.symbols[s], _ = utf8.DecodeRuneInString(strings.ToUpper(u))
+
+	return result{rowsAffected: 1}, nil
+}
+
+func (d *Db) RemoveSymbol(s string) (sql.Result, error) {
+	if s == "" {
+		return nil, errEmptySymbol
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s = strings.ToUpper(s)
+	if _, ok := d.symbols[s]; !ok {
+		return result{}, nil
+	}
+
+	delete(d.symbols, s)
+
+	return result{rowsAffected: 1}, nil
+}
+
+func (d *Db) Symbols() ([]*domain.Symbol, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	symbols := make([]*domain.Symbol, 0, len(d.symbols))
+	for _, s := range slices.Sorted(maps.Keys(d.symbols)) {
+		symbols = append(symbols, &domain.Symbol{
+			Symbol:  s,
+			Unicode: d.symbols[s],
+		})
+	}
+
+	return symbols, nil
+}
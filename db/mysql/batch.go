@@ -0,0 +1,107 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/streamdp/ccd/db/symbolid"
+	"github.com/streamdp/ccd/domain"
+)
+
+const (
+	dataColumns = "fromSym,toSym,change24hour,changepct24hour,open24hour,volume24hour,low24hour,high24hour,price," +
+		"supply,mktcap,lastupdate,displaydataraw,provider"
+	dataColumnsNumber = 14
+
+	// maxBatchRows keeps the number of the statement placeholders below the mysql limit of 65535
+	maxBatchRows = 1000
+)
+
+// InsertBatch of the rows with the multi-row insert, rows with unknown symbols are skipped, the number of the
+// inserted rows is returned
+func (d *Db) InsertBatch(ctx context.Context, data []*domain.Data) (int64, error) {
+	var (
+		args = make([]any, 0, min(len(data), maxBatchRows)*dataColumnsNumber)
+		n    int64
+		errs []error
+	)
+
+	exec := func() {
+		if len(args) == 0 {
+			return
+		}
+
+		result, err := d.ExecContext(ctx, buildBatchInsert(len(args)/dataColumnsNumber), args...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %w", errExecuteQuery, err))
+		} else if rows, errRows := result.RowsAffected(); errRows == nil {
+			n += rows
+		}
+
+		args = args[:0]
+	}
+
+	for _, row := range data {
+		from, err := d.symbols.Get(ctx, row.FromSymbol)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		to, err := d.symbols.Get(ctx, row.ToSymbol)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		args = append(args,
+			from,
+			to,
+			row.Change24Hour,
+			row.ChangePct24Hour,
+			row.Open24Hour,
+			row.Volume24Hour,
+			row.Low24Hour,
+			row.High24Hour,
+			row.Price,
+			row.Supply,
+			row.MktCap,
+			row.LastUpdate,
+			row.DisplayDataRaw,
+			row.Provider,
+		)
+
+		if len(args) == maxBatchRows*dataColumnsNumber {
+			exec()
+		}
+	}
+
+	exec()
+
+	return n, errors.Join(errs...)
+}
+
+// buildBatchInsert query of the data rows
+func buildBatchInsert(rows int) string {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?,", dataColumnsNumber), ",") + ")"
+
+	return "insert into data (" + dataColumns + ") values " + strings.TrimSuffix(strings.Repeat(row+",", rows), ",")
+}
+
+func (d *Db) symbolId(ctx context.Context, symbol string) (int64, error) {
+	var id int64
+	if err := d.QueryRowContext(ctx, `select _id from symbols where symbol=?;`, symbol).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%w: %s", symbolid.ErrUnknownSymbol, symbol)
+		}
+
+		return 0, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,37 @@
+package mysql
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_buildBatchInsert(t *testing.T) {
+	tests := []struct {
+		name string
+		rows int
+		want string
+	}{
+		{
+			name: "single row",
+			rows: 1,
+			want: "insert into data (" + dataColumns + ") values (?,?,?,?,?,?,?,?,?,?,?,?,?,?)",
+		},
+		{
+			name: "several rows",
+			rows: 2,
+			want: "insert into data (" + dataColumns + ") values (?,?,?,?,?,?,?,?,?,?,?,?,?,?)," +
+				"(?,?,?,?,?,?,?,?,?,?,?,?,?,?)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildBatchInsert(tt.rows); got != tt.want {
+				t.Errorf("buildBatchInsert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := len(strings.Split(dataColumns, ",")); got != dataColumnsNumber {
+		t.Errorf("columns = %v, want %v", got, dataColumnsNumber)
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
+	"github.com/streamdp/ccd/db/symbolid"
 	"github.com/streamdp/ccd/domain"
 )
 
@@ -14,6 +15,8 @@ const Mysql = "mysql"
 type Db struct {
 	*sql.DB
 
+	symbols *symbolid.Cache
+
 	pipe chan *domain.Data
 }
 
@@ -23,15 +26,36 @@ func (d *Db) DataPipe() chan *domain.Data {
 
 // Connect after prepare to the Db
 func Connect(dataSource string) (*Db, error) {
-	sqlDb, err := sql.Open(Mysql, dataSource)
+	dsn, err := foundRowsDSN(dataSource)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDb, err := sql.Open(Mysql, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	return &Db{
+	d := &Db{
 		DB:   sqlDb,
 		pipe: make(chan *domain.Data, 1000),
-	}, nil
+	}
+	d.symbols = symbolid.New(d.symbolId)
+
+	return d, nil
+}
+
+// foundRowsDSN sets clientFoundRows, so the updates report the matched rows instead of the changed ones like the other
+// databases do, and the row updated with the same values is not taken for the missing one
+func foundRowsDSN(dataSource string) (string, error) {
+	cfg, err := mysql.ParseDSN(dataSource)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse data source: %w", err)
+	}
+
+	cfg.ClientFoundRows = true
+
+	return cfg.FormatDSN(), nil
 }
 
 // Close Db connection
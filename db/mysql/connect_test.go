@@ -0,0 +1,72 @@
+package mysql
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/streamdp/ccd/db/dbtest"
+)
+
+// TestDb runs the store contract against the database given with the CCD_TEST_MYSQL_URL env, e.g.
+// "root:root@tcp(127.0.0.1:3306)/ccd_test", all tables of the database are dropped
+func TestDb(t *testing.T) {
+	dataSource := os.Getenv("CCD_TEST_MYSQL_URL")
+	if dataSource == "" {
+		t.Skip("CCD_TEST_MYSQL_URL is not set")
+	}
+
+	dbtest.Run(t, func(t *testing.T) dbtest.Store {
+		t.Helper()
+
+		d, err := Connect(dataSource)
+		if err != nil {
+			t.Fatalf("Connect() error = %v", err)
+		}
+
+		t.Cleanup(func() { _ = d.Close() })
+
+		m, err := d.Migrator()
+		if err != nil {
+			t.Fatalf("Migrator() error = %v", err)
+		}
+
+		dbtest.Migrate(t, d.DB, m)
+
+		return d
+	})
+}
+
+func Test_foundRowsDSN(t *testing.T) {
+	tests := []struct {
+		name       string
+		dataSource string
+		wantErr    bool
+	}{
+		{
+			name:       "without params",
+			dataSource: "root:root@tcp(127.0.0.1:3306)/ccd",
+		},
+		{
+			name:       "with params",
+			dataSource: "root:root@tcp(127.0.0.1:3306)/ccd?parseTime=true",
+		},
+		{
+			name:       "invalid",
+			dataSource: "root:root@tcp(127.0.0.1:3306",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := foundRowsDSN(tt.dataSource)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("foundRowsDSN() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !strings.Contains(got, "clientFoundRows=true") {
+				t.Errorf("foundRowsDSN() = %v, want clientFoundRows=true", got)
+			}
+		})
+	}
+}
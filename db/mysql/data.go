@@ -9,10 +9,14 @@ import (
 	"github.com/streamdp/ccd/domain"
 )
 
-var errEmptyData = errors.New("empty data")
+var (
+	errEmptyData  = errors.New("empty data")
+	errEmptyQuery = errors.New("empty query")
+)
 
-// GetLast row with the most recent data for the selected currencies pair
-func (d *Db) GetLast(ctx context.Context, from string, to string) (*domain.Data, error) {
+// GetLast row with the most recent data of the data provider for the selected currencies pair, the row of any data
+// provider is returned when the provider is empty, the rows are ordered by the row time in milliseconds
+func (d *Db) GetLast(ctx context.Context, provider, from, to string) (*domain.Data, error) {
 	result := &domain.Data{
 		FromSymbol: from,
 		ToSymbol:   to,
@@ -31,13 +35,15 @@ func (d *Db) GetLast(ctx context.Context, from string, to string) (*domain.Data,
 		    supply,
 		    mktcap,
 		    lastupdate, 
-		    displaydataraw 
+		    displaydataraw,
+		    provider
 		from data 
 		where fromSym=(select _id from symbols where symbol=?) 
 		  and toSym=(select _id from symbols where symbol=?) 
-		ORDER BY lastupdate DESC limit 1;
+		  and (? = '' or provider in (?, ''))
+		ORDER BY ts DESC, _id DESC limit 1;
 `
-	if err := d.QueryRowContext(ctx, query, from, to).Scan(
+	if err := d.QueryRowContext(ctx, query, from, to, provider, provider).Scan(
 		&result.Id,
 		&result.Change24Hour,
 		&result.ChangePct24Hour,
@@ -50,6 +56,7 @@ func (d *Db) GetLast(ctx context.Context, from string, to string) (*domain.Data,
 		&result.MktCap,
 		&result.LastUpdate,
 		&result.DisplayDataRaw,
+		&result.Provider,
 	); err != nil {
 		return nil, fmt.Errorf("%w: %w", errCopyResult, err)
 	}
@@ -76,12 +83,13 @@ func (d *Db) Insert(ctx context.Context, data *domain.Data) (sql.Result, error)
 		                  supply, 
 		                  mktcap,
 		                  lastupdate,
-		                  displaydataraw
+		                  displaydataraw,
+		                  provider
 		) 
 		values (
 		        (SELECT _id FROM symbols WHERE symbol=?),
 		        (SELECT _id FROM symbols WHERE symbol=?),
-		        ?,?,?,?,?,?,?,?,?,?,?
+		        ?,?,?,?,?,?,?,?,?,?,?,?
 		)
 `
 
@@ -101,6 +109,7 @@ func (d *Db) Insert(ctx context.Context, data *domain.Data) (sql.Result, error)
 		&data.MktCap,
 		&data.LastUpdate,
 		&data.DisplayDataRaw,
+		&data.Provider,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
@@ -108,3 +117,155 @@ func (d *Db) Insert(ctx context.Context, data *domain.Data) (sql.Result, error)
 
 	return result, nil
 }
+
+// GetRange rows for the selected currencies pair ordered by the lastupdate, one extra row is fetched to detect the
+// next page
+func (d *Db) GetRange(ctx context.Context, q *domain.RangeQuery) ([]*domain.Data, error) {
+	if q == nil {
+		return nil, errEmptyQuery
+	}
+
+	query := `
+		select
+		    _id,
+		    change24hour,
+		    changepct24hour,
+		    open24hour,
+		    volume24hour,
+		    low24hour,
+		    high24hour,
+		    price,
+		    supply,
+		    mktcap,
+		    cast(lastupdate as signed),
+		    displaydataraw,
+		    provider
+		from data
+		where fromSym=(select _id from symbols where symbol=?)
+		  and toSym=(select _id from symbols where symbol=?)
+		  and (? = '' or provider in (?, ''))
+		  and ts >= ?
+`
+	args := []any{q.From, q.To, q.Provider, q.Provider, q.Start}
+
+	if q.End != 0 {
+		query += `		  and ts <= ?
+`
+		args = append(args, q.End)
+	}
+
+	// the cursor is spelled out, mysql doesn't use the index range for the row comparison
+	if q.Cursor != nil {
+		query += `		  and ts >= ? and (ts > ? or _id > ?)
+`
+		args = append(args, q.Cursor.LastUpdate, q.Cursor.LastUpdate, q.Cursor.Id)
+	}
+
+	query += fmt.Sprintf("		ORDER BY ts, _id limit %d;", q.Limit+1)
+
+	//nolint:sqlclosecheck
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var result []*domain.Data
+
+	for rows.Next() {
+		data := &domain.Data{
+			FromSymbol: q.From,
+			ToSymbol:   q.To,
+		}
+		if err = rows.Scan(
+			&data.Id,
+			&data.Change24Hour,
+			&data.ChangePct24Hour,
+			&data.Open24Hour,
+			&data.Volume24Hour,
+			&data.Low24Hour,
+			&data.High24Hour,
+			&data.Price,
+			&data.Supply,
+			&data.MktCap,
+			&data.LastUpdate,
+			&data.DisplayDataRaw,
+			&data.Provider,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %w", errCopyResult, err)
+		}
+
+		result = append(result, data)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errParseResults, err)
+	}
+
+	return result, nil
+}
+
+// Candles aggregated on the database side from the rows of the selected currencies pair
+func (d *Db) Candles(ctx context.Context, q *domain.CandleQuery) ([]*domain.Candle, error) {
+	if q == nil {
+		return nil, errEmptyQuery
+	}
+
+	query := `
+		select distinct
+		    bucket,
+		    first_value(price) over w,
+		    max(price) over w,
+		    min(price) over w,
+		    last_value(price) over w,
+		    last_value(volume24hour) over w
+		from (
+		    select _id, price, volume24hour, ts, ts - ts % ? as bucket
+		    from data
+		    where fromSym=(select _id from symbols where symbol=?)
+		      and toSym=(select _id from symbols where symbol=?)
+		      and (? = '' or provider in (?, ''))
+		      and ts >= ?
+`
+	args := []any{q.Resolution.Millis(), q.From, q.To, q.Provider, q.Provider, q.Start}
+
+	if q.End != 0 {
+		query += `		      and ts <= ?
+`
+		args = append(args, q.End)
+	}
+
+	query += `		) b
+		window w as (partition by bucket order by ts, _id rows between unbounded preceding and unbounded following)
+		ORDER BY bucket limit ?;
+`
+	args = append(args, q.Limit)
+
+	//nolint:sqlclosecheck
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var result []*domain.Candle
+
+	for rows.Next() {
+		c := &domain.Candle{}
+		if err = rows.Scan(&c.Time, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume24Hour); err != nil {
+			return nil, fmt.Errorf("%w: %w", errCopyResult, err)
+		}
+
+		result = append(result, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errParseResults, err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/streamdp/ccd/pkg/migrate"
+)
+
+const (
+	// migrateLockName of the named lock held while the schema migrations are applied
+	migrateLockName = "ccd_schema_migrations"
+	// migrateLockTimeout in seconds to wait for the instance applying the migrations
+	migrateLockTimeout = 600
+)
+
+var errLockTimeout = errors.New("timed out waiting for the lock")
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrator of the embedded schema migrations
+func (d *Db) Migrator() (*migrate.Migrator, error) {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
+
+	return migrate.New(d.DB, fsys, func(int) string {
+		return "?"
+	}, namedLock)
+}
+
+// namedLock of the session, it is released when the connection is closed as well
+func namedLock(ctx context.Context, conn *sql.Conn) (func(), error) {
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "select get_lock(?, ?)", migrateLockName, migrateLockTimeout).Scan(
+		&locked,
+	); err != nil {
+		return nil, err
+	}
+
+	if locked.Int64 != 1 {
+		return nil, errLockTimeout
+	}
+
+	return func() {
+		_, _ = conn.ExecContext(context.Background(), "select release_lock(?)", migrateLockName)
+	}, nil
+}
@@ -0,0 +1,44 @@
+create table if not exists data
+(
+    _id             int auto_increment primary key,
+    fromSym         int        not null,
+    toSym           int        not null,
+    change24hour    double     null,
+    changepct24hour double     null,
+    open24hour      double     null,
+    volume24hour    double     null,
+    low24hour       double     null,
+    high24hour      double     null,
+    price           double     null,
+    supply          double     null,
+    mktcap          double     null,
+    lastupdate      mediumtext not null,
+    displaydataraw  text       null
+) default charset utf8 collate = utf8_general_ci;
+
+create table if not exists symbols
+(
+    _id int auto_increment primary key,
+    symbol varchar(64) collate latin1_swedish_ci default '' not null,
+    unicode char null,
+    constraint symbols_symbol_uindex unique (symbol)
+) default charset utf8 collate = utf8_general_ci;
+
+insert ignore into symbols(symbol, unicode)
+    values ('USDT','₮'),
+           ('BTC','₿'),
+           ('ETH','⟠'),
+           ('USD','$'),
+           ('XRP','✕'),
+           ('LTC','Ł'),
+           ('EUR','€'),
+           ('GBP','£'),
+           ('JPY','¥');
+
+create table if not exists session
+(
+    _id       int auto_increment primary key,
+    task_name varchar(64) not null default '',
+    `interval`  integer default 60 not null,
+    constraint session_task_name_uindex unique (task_name)
+) default charset utf8 collate = utf8_general_ci;
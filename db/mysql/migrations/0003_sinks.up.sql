@@ -0,0 +1,7 @@
+create table if not exists sinks
+(
+    _id    int auto_increment primary key,
+    url    text not null,
+    pairs  text not null,
+    secret text not null
+) default charset utf8 collate = utf8_general_ci;
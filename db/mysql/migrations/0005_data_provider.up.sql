@@ -0,0 +1,12 @@
+-- the databases set up before several data providers were supported have no provider column, mysql has no
+-- "add column if not exists", so the column is looked up first
+set @ddl = (
+    select if(count(*) = 0,
+              'alter table data add column provider varchar(32) not null default ''''',
+              'do 0')
+    from information_schema.columns
+    where table_schema = database() and table_name = 'data' and column_name = 'provider'
+);
+prepare add_provider from @ddl;
+execute add_provider;
+deallocate prepare add_provider;
@@ -0,0 +1,3 @@
+alter table data
+    drop index data_pair_ts_index,
+    drop column ts;
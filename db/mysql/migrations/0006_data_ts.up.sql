@@ -0,0 +1,6 @@
+-- the row time in milliseconds, lastupdate is stored as it is received, in seconds or in milliseconds, the virtual
+-- column is computed on read, so adding it doesn't rewrite the table, and its index serves the range queries
+alter table data
+    add column ts bigint as (if(cast(lastupdate as signed) < 1000000000000,
+                                cast(lastupdate as signed) * 1000, cast(lastupdate as signed))) virtual,
+    add index data_pair_ts_index (fromSym, toSym, ts);
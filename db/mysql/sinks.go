@@ -0,0 +1,105 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+var errEmptySink = errors.New("empty sink")
+
+// Sinks return all data sinks ordered by id
+func (d *Db) Sinks(ctx context.Context) ([]*domain.Sink, error) {
+	query := `
+		select _id, url, pairs, secret
+		from sinks
+		order by _id;
+`
+
+	//nolint:sqlclosecheck
+	rows, err := d.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var sinks []*domain.Sink
+
+	for rows.Next() {
+		var (
+			s     = &domain.Sink{}
+			pairs string
+		)
+		if err = rows.Scan(&s.Id, &s.Url, &pairs, &s.Secret); err != nil {
+			return nil, fmt.Errorf("%w: %w", errCopyResult, err)
+		}
+
+		s.Pairs = domain.SplitPairs(pairs)
+		sinks = append(sinks, s)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", errParseResults, rows.Err())
+	}
+
+	return sinks, nil
+}
+
+// AddSink and return its id
+func (d *Db) AddSink(ctx context.Context, s *domain.Sink) (int64, error) {
+	if s == nil {
+		return 0, errEmptySink
+	}
+
+	query := `
+		insert into sinks (url, pairs, secret)
+		values (?,?,?);
+`
+
+	result, err := d.ExecContext(ctx, query, s.Url, domain.JoinPairs(s.Pairs), s.Secret)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", errCopyResult, err)
+	}
+
+	return id, nil
+}
+
+// UpdateSink with the id of the given one
+func (d *Db) UpdateSink(ctx context.Context, s *domain.Sink) (sql.Result, error) {
+	if s == nil {
+		return nil, errEmptySink
+	}
+
+	query := `
+		update sinks
+		set url=?, pairs=?, secret=?
+		where _id=?;
+`
+
+	result, err := d.ExecContext(ctx, query, s.Url, domain.JoinPairs(s.Pairs), s.Secret, s.Id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return result, nil
+}
+
+// RemoveSink by id
+func (d *Db) RemoveSink(ctx context.Context, id int64) (sql.Result, error) {
+	result, err := d.ExecContext(ctx, `delete from sinks where _id=?;`, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return result, nil
+}
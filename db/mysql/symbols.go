@@ -55,6 +55,8 @@ func (d *Db) RemoveSymbol(s string) (sql.Result, error) {
 		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
 	}
 
+	d.symbols.Remove(s)
+
 	return result, nil
 }
 
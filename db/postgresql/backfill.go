@@ -0,0 +1,137 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+const (
+	// backfillLockId of the advisory lock held while the ts column is backfilled, so one instance does it at a time
+	backfillLockId = 4_367_616_002
+
+	// backfillBatch is the number of the _id values filled in by one statement
+	backfillBatch = 10_000
+)
+
+var errBackfillPending = errors.New("the ts column is not backfilled yet")
+
+// Backfill the ts column of the rows stored before it was added and build its index, every batch is committed on its
+// own and the index is built concurrently, so the writes to the table are not blocked for long. The column is set not
+// null at the end, it marks the backfill done, so the next run does nothing. It returns the number of the filled rows,
+// the instance started while another one is backfilling waits for it and finds the column filled then.
+func (d *Db) Backfill(ctx context.Context) (int64, error) {
+	conn, err := d.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	unlock, err := sessionLock(ctx, conn, backfillLockId)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+	defer unlock()
+
+	done, err := d.backfilled(ctx)
+	if err != nil || done {
+		return 0, err
+	}
+
+	if err = d.createTsIndex(ctx, conn); err != nil {
+		return 0, err
+	}
+
+	n, err := d.fillTs(ctx, conn)
+	if err != nil {
+		return n, err
+	}
+
+	// the validated check lets the not null be set without one more scan of the table under the exclusive lock
+	for _, query := range []string{
+		`alter table data drop constraint if exists data_ts_not_null;`,
+		`alter table data add constraint data_ts_not_null check (ts is not null) not valid;`,
+		`alter table data validate constraint data_ts_not_null;`,
+		`alter table data alter column ts set not null;`,
+		`alter table data drop constraint data_ts_not_null;`,
+	} {
+		if _, err = conn.ExecContext(ctx, query); err != nil {
+			return n, fmt.Errorf("%w: %w", errExecuteQuery, err)
+		}
+	}
+
+	return n, nil
+}
+
+// backfilled report whether the ts column is set not null already
+func (d *Db) backfilled(ctx context.Context) (bool, error) {
+	var notNull bool
+	if err := d.QueryRowContext(ctx,
+		`select attnotnull from pg_attribute where attrelid = 'data'::regclass and attname = 'ts';`,
+	).Scan(&notNull); err != nil {
+		return false, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return notNull, nil
+}
+
+// createTsIndex concurrently, the index left invalid by the failed build is dropped and built again
+func (d *Db) createTsIndex(ctx context.Context, conn *sql.Conn) error {
+	var valid sql.NullBool
+	if err := conn.QueryRowContext(ctx,
+		`select indisvalid from pg_index where indexrelid = to_regclass('data_pair_ts_index');`,
+	).Scan(&valid); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	if valid.Valid && valid.Bool {
+		return nil
+	}
+
+	for _, query := range []string{
+		`drop index concurrently if exists data_pair_ts_index;`,
+		`create index concurrently data_pair_ts_index on data (fromsym, tosym, ts);`,
+	} {
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("%w: %w", errExecuteQuery, err)
+		}
+	}
+
+	return nil
+}
+
+// fillTs of the rows walking the primary key down in batches, the rows inserted meanwhile have the ts set already
+func (d *Db) fillTs(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var last sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `select max(_id) from data;`).Scan(&last); err != nil {
+		return 0, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	var n int64
+
+	for upper := last.Int64; upper > 0; upper -= backfillBatch {
+		res, err := conn.ExecContext(ctx, `
+			update data
+			set ts = to_timestamp(case when lastupdate::bigint < 1000000000000
+			                          then lastupdate::bigint else lastupdate::bigint / 1000.0 end)
+			where _id > $1 and _id <= $2 and ts is null;`,
+			upper-backfillBatch, upper,
+		)
+		if err != nil {
+			return n, fmt.Errorf("%w: %w", errExecuteQuery, err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return n, fmt.Errorf("%w: %w", errExecuteQuery, err)
+		}
+
+		n += affected
+	}
+
+	return n, nil
+}
@@ -0,0 +1,113 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/streamdp/ccd/db/symbolid"
+	"github.com/streamdp/ccd/domain"
+)
+
+var dataColumns = []string{
+	"fromsym",
+	"tosym",
+	"change24hour",
+	"changepct24hour",
+	"open24hour",
+	"volume24hour",
+	"low24hour",
+	"high24hour",
+	"price",
+	"supply",
+	"mktcap",
+	"lastupdate",
+	"displaydataraw",
+	"provider",
+	"ts",
+}
+
+// InsertBatch of the rows with the single COPY, rows with unknown symbols are skipped, the rows are inserted one by
+// one when the COPY fails, the number of the inserted rows is returned
+func (d *Db) InsertBatch(ctx context.Context, data []*domain.Data) (int64, error) {
+	var (
+		rows  = make([][]any, 0, len(data))
+		valid = make([]*domain.Data, 0, len(data))
+		errs  []error
+	)
+
+	for _, row := range data {
+		from, err := d.symbols.Get(ctx, row.FromSymbol)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		to, err := d.symbols.Get(ctx, row.ToSymbol)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		rows = append(rows, []any{
+			from,
+			to,
+			row.Change24Hour,
+			row.ChangePct24Hour,
+			row.Open24Hour,
+			row.Volume24Hour,
+			row.Low24Hour,
+			row.High24Hour,
+			row.Price,
+			row.Supply,
+			row.MktCap,
+			strconv.FormatInt(row.LastUpdate, 10),
+			row.DisplayDataRaw,
+			row.Provider,
+			rowTime(row.LastUpdate),
+		})
+		valid = append(valid, row)
+	}
+
+	if len(rows) == 0 {
+		return 0, errors.Join(errs...)
+	}
+
+	n, err := d.pool.CopyFrom(ctx, pgx.Identifier{"data"}, dataColumns, pgx.CopyFromRows(rows))
+	if err == nil {
+		return n, errors.Join(errs...)
+	}
+
+	errs = append(errs, fmt.Errorf("%w: %w", errExecuteQuery, err))
+
+	// the COPY is atomic, nothing is inserted when it fails
+	n = 0
+	for _, row := range valid {
+		if _, err = d.Insert(ctx, row); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+		n++
+	}
+
+	return n, errors.Join(errs...)
+}
+
+func (d *Db) symbolId(ctx context.Context, symbol string) (int64, error) {
+	var id int64
+	if err := d.QueryRowContext(ctx, `select _id from symbols where symbol=$1;`, symbol).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%w: %s", symbolid.ErrUnknownSymbol, symbol)
+		}
+
+		return 0, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return id, nil
+}
@@ -1,10 +1,14 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"sync/atomic"
 
-	_ "github.com/lib/pq"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/streamdp/ccd/db/symbolid"
 	"github.com/streamdp/ccd/domain"
 )
 
@@ -14,6 +18,14 @@ const Postgres = "postgres"
 type Db struct {
 	*sql.DB
 
+	// pool of the pgx connections backs the *sql.DB and is used directly to COPY the batches of rows
+	pool    *pgxpool.Pool
+	symbols *symbolid.Cache
+
+	// aggregates report whether the continuous aggregates of the candles are found, the missing ones are looked up
+	// again on the next candles request
+	aggregates atomic.Bool
+
 	pipe chan *domain.Data
 }
 
@@ -23,21 +35,30 @@ func (d *Db) DataPipe() chan *domain.Data {
 
 // Connect after prepare to the Db
 func Connect(dataSource string) (*Db, error) {
-	sqlDb, err := sql.Open(Postgres, dataSource)
+	pool, err := pgxpool.New(context.Background(), dataSource)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	return &Db{
-		DB:   sqlDb,
+	d := &Db{
+		DB:   stdlib.OpenDBFromPool(pool),
+		pool: pool,
 		pipe: make(chan *domain.Data, 1000),
-	}, nil
+	}
+	d.symbols = symbolid.New(d.symbolId)
+
+	return d, nil
 }
 
 // Close Db connection
 func (d *Db) Close() error {
 	defer close(d.pipe)
 
+	if d.pool != nil {
+		// the pool isn't closed with the *sql.DB opened from it
+		defer d.pool.Close()
+	}
+
 	if d.DB == nil {
 		return nil
 	}
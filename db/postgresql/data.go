@@ -5,14 +5,20 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/streamdp/ccd/domain"
 )
 
-var errEmptyData = errors.New("cant insert empty data")
+var (
+	errEmptyData  = errors.New("cant insert empty data")
+	errEmptyQuery = errors.New("empty query")
+)
 
-// GetLast row with the most recent data for the selected currencies pair
-func (d *Db) GetLast(ctx context.Context, from string, to string) (*domain.Data, error) {
+// GetLast row with the most recent data of the data provider for the selected currencies pair, the row of any data
+// provider is returned when the provider is empty, the rows not backfilled yet are skipped
+func (d *Db) GetLast(ctx context.Context, provider, from, to string) (*domain.Data, error) {
 	result := &domain.Data{
 		FromSymbol: from,
 		ToSymbol:   to,
@@ -31,13 +37,16 @@ func (d *Db) GetLast(ctx context.Context, from string, to string) (*domain.Data,
 		       supply,
 		       mktcap, 
 		       lastupdate,
-		       displaydataraw
+		       displaydataraw,
+		       provider
 		from data 
 		where fromSym=(select _id from symbols where symbol=$1)
 		  and toSym=(select _id from symbols where symbol=$2)
-		ORDER BY lastupdate DESC limit 1;
+		  and ($3 = '' or provider in ($3, ''))
+		  and ts is not null
+		ORDER BY ts DESC, _id DESC limit 1;
 `
-	if err := d.QueryRowContext(ctx, query, from, to).Scan(
+	if err := d.QueryRowContext(ctx, query, from, to, provider).Scan(
 		&result.Id,
 		&result.Change24Hour,
 		&result.ChangePct24Hour,
@@ -50,6 +59,7 @@ func (d *Db) GetLast(ctx context.Context, from string, to string) (*domain.Data,
 		&result.MktCap,
 		&result.LastUpdate,
 		&result.DisplayDataRaw,
+		&result.Provider,
 	); err != nil {
 		return nil, fmt.Errorf("%w: %w", errCopyResult, err)
 	}
@@ -76,12 +86,14 @@ func (d *Db) Insert(ctx context.Context, data *domain.Data) (sql.Result, error)
                   supply, 
                   mktcap, 
                   lastupdate,
-                  displaydataraw
+                  displaydataraw,
+                  provider,
+                  ts
         ) 
 		values (
 		        (SELECT _id FROM symbols WHERE symbol=$1),
 		        (SELECT _id FROM symbols WHERE symbol=$2),
-		        $3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13
+		        $3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15
 		)
 `
 
@@ -99,12 +111,200 @@ func (d *Db) Insert(ctx context.Context, data *domain.Data) (sql.Result, error)
 		&data.Price,
 		&data.Supply,
 		&data.MktCap,
-		&data.LastUpdate,
+		strconv.FormatInt(data.LastUpdate, 10),
 		&data.DisplayDataRaw,
+		&data.Provider,
+		rowTime(data.LastUpdate),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return result, nil
+}
+
+// GetRange rows for the selected currencies pair ordered by the lastupdate, one extra row is fetched to detect the
+// next page
+func (d *Db) GetRange(ctx context.Context, q *domain.RangeQuery) ([]*domain.Data, error) {
+	if q == nil {
+		return nil, errEmptyQuery
+	}
+
+	query := `
+		select
+		       _id,
+		       change24hour,
+		       changepct24hour,
+		       open24hour,
+		       volume24hour,
+		       low24hour,
+		       high24hour,
+		       price,
+		       supply,
+		       mktcap,
+		       lastupdate::bigint,
+		       displaydataraw,
+		       provider
+		from data
+		where fromSym=(select _id from symbols where symbol=$1)
+		  and toSym=(select _id from symbols where symbol=$2)
+		  and ($3 = '' or provider in ($3, ''))
+		  and ts >= $4::timestamptz
+		  and ($5::timestamptz is null or ts <= $5::timestamptz)
+`
+	args := []any{q.From, q.To, q.Provider, time.UnixMilli(q.Start).UTC(), rangeEnd(q.End)}
+
+	if q.Cursor != nil {
+		query += `		  and (ts, _id) > ($6, $7)
+`
+		args = append(args, time.UnixMilli(q.Cursor.LastUpdate).UTC(), q.Cursor.Id)
+	}
+
+	query += fmt.Sprintf("		ORDER BY ts, _id limit %d;", q.Limit+1)
+
+	//nolint:sqlclosecheck
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var result []*domain.Data
+
+	for rows.Next() {
+		data := &domain.Data{
+			FromSymbol: q.From,
+			ToSymbol:   q.To,
+		}
+		if err = rows.Scan(
+			&data.Id,
+			&data.Change24Hour,
+			&data.ChangePct24Hour,
+			&data.Open24Hour,
+			&data.Volume24Hour,
+			&data.Low24Hour,
+			&data.High24Hour,
+			&data.Price,
+			&data.Supply,
+			&data.MktCap,
+			&data.LastUpdate,
+			&data.DisplayDataRaw,
+			&data.Provider,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %w", errCopyResult, err)
+		}
+
+		result = append(result, data)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", errParseResults, rows.Err())
+	}
+
+	return result, nil
+}
+
+// Candles aggregated on the database side from the rows of the selected currencies pair, they are read from the
+// continuous aggregates when the TimescaleDB is set up
+func (d *Db) Candles(ctx context.Context, q *domain.CandleQuery) ([]*domain.Candle, error) {
+	if q == nil {
+		return nil, errEmptyQuery
+	}
+
+	var (
+		query string
+		args  = []any{q.From, q.To, nil, time.UnixMilli(q.Start).UTC(), rangeEnd(q.End), q.Limit, q.Provider}
 	)
+
+	if view, ok := d.candleView(ctx, q.Resolution); ok {
+		// the bars of the aggregates are rolled up to the resolution, the bar holding the start is returned whole
+		query = fmt.Sprintf(`
+		select
+		       (extract(epoch from b) * 1000)::bigint,
+		       first(open, bucket),
+		       max(high),
+		       min(low),
+		       last(close, bucket),
+		       last(volume24hour, bucket)
+		from (
+		    select time_bucket($3::interval, bucket) as b, bucket, open, high, low, close, volume24hour
+		    from %s
+		    where fromSym=(select _id from symbols where symbol=$1)
+		      and toSym=(select _id from symbols where symbol=$2)
+		      and ($7 = '' or provider in ($7, ''))
+		      and bucket >= time_bucket($3::interval, $4::timestamptz)
+		      and ($5::timestamptz is null or bucket <= $5::timestamptz)
+		) v
+		group by b
+		ORDER BY b limit $6;
+`, view)
+		args[2] = pgInterval(time.Duration(q.Resolution.Millis()) * time.Millisecond)
+	} else {
+		query = `
+		select
+		       bucket,
+		       (array_agg(price order by ts, _id))[1],
+		       max(price),
+		       min(price),
+		       (array_agg(price order by ts desc, _id desc))[1],
+		       (array_agg(volume24hour order by ts desc, _id desc))[1]
+		from (
+		    select _id, price, volume24hour, ts, ms - ms % $3 as bucket
+		    from (
+		        select _id, price, volume24hour, ts, (extract(epoch from ts) * 1000)::bigint as ms
+		        from data
+		        where fromSym=(select _id from symbols where symbol=$1)
+		          and toSym=(select _id from symbols where symbol=$2)
+		          and ($7 = '' or provider in ($7, ''))
+		          and ts >= $4::timestamptz
+		          and ($5::timestamptz is null or ts <= $5::timestamptz)
+		    ) d
+		) b
+		group by bucket
+		ORDER BY bucket limit $6;
+`
+		args[2] = q.Resolution.Millis()
+	}
+
+	//nolint:sqlclosecheck
+	rows, err := d.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
 	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var result []*domain.Candle
+
+	for rows.Next() {
+		c := &domain.Candle{}
+		if err = rows.Scan(&c.Time, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume24Hour); err != nil {
+			return nil, fmt.Errorf("%w: %w", errCopyResult, err)
+		}
+
+		result = append(result, c)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", errParseResults, rows.Err())
+	}
 
 	return result, nil
 }
+
+// rowTime of the lastupdate given in seconds or milliseconds
+func rowTime(lastUpdate int64) time.Time {
+	return time.UnixMilli(domain.MilliTimestamp(lastUpdate)).UTC()
+}
+
+// rangeEnd of the range given in milliseconds, nil is returned for the open range
+func rangeEnd(end int64) any {
+	if end == 0 {
+		return nil
+	}
+
+	return time.UnixMilli(end).UTC()
+}
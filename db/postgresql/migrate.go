@@ -0,0 +1,46 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+
+	"github.com/streamdp/ccd/pkg/migrate"
+)
+
+// migrateLockId of the advisory lock held while the schema migrations are applied
+const migrateLockId = 4_367_616_001
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrator of the embedded schema migrations
+func (d *Db) Migrator() (*migrate.Migrator, error) {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
+
+	return migrate.New(d.DB, fsys, func(n int) string {
+		return "$" + strconv.Itoa(n)
+	}, advisoryLock)
+}
+
+// advisoryLock of the session held while the migrations are applied
+func advisoryLock(ctx context.Context, conn *sql.Conn) (func(), error) {
+	return sessionLock(ctx, conn, migrateLockId)
+}
+
+// sessionLock wait for the advisory lock of the session, it is released when the connection is closed as well
+func sessionLock(ctx context.Context, conn *sql.Conn, id int64) (func(), error) {
+	if _, err := conn.ExecContext(ctx, "select pg_advisory_lock($1)", id); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_, _ = conn.ExecContext(context.Background(), "select pg_advisory_unlock($1)", id)
+	}, nil
+}
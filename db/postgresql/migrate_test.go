@@ -0,0 +1,27 @@
+package postgresql
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/streamdp/ccd/pkg/migrate"
+)
+
+func Test_migrations(t *testing.T) {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := migrate.Parse(fsys)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// only the baseline adopting the existing tables is never rolled back
+	for _, m := range got {
+		if (m.Down == "") != (m.Version == 1) {
+			t.Errorf("migration %04d_%s down script = %q", m.Version, m.Name, m.Down)
+		}
+	}
+}
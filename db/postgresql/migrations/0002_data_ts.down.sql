@@ -0,0 +1,2 @@
+drop index if exists data_pair_ts_index;
+alter table data drop column if exists ts;
@@ -0,0 +1,4 @@
+-- the timestamp of the row, lastupdate is kept as it was received, in seconds or milliseconds. The column is added
+-- empty, so the migration doesn't rewrite the table, the older rows are filled in and the index is built in the
+-- background after the startup, see Db.Backfill
+alter table data add column if not exists ts timestamptz;
@@ -0,0 +1,11 @@
+create table if not exists alerts
+(
+    _id              serial not null primary key,
+    fromsym          varchar(64) not null,
+    tosym            varchar(64) not null,
+    rule             varchar(32) not null,
+    threshold        double precision not null,
+    window_seconds   bigint default 0 not null,
+    cooldown_seconds bigint default 0 not null,
+    webhook_url      text default '' not null
+);
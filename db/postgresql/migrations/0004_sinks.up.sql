@@ -0,0 +1,7 @@
+create table if not exists sinks
+(
+    _id    serial not null primary key,
+    url    text not null,
+    pairs  text default '' not null,
+    secret text default '' not null
+);
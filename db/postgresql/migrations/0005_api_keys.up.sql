@@ -0,0 +1,9 @@
+create table if not exists api_keys
+(
+    _id     serial not null primary key,
+    name    text not null,
+    prefix  varchar(16) not null,
+    hash    varchar(64) not null unique,
+    scopes  text not null,
+    created bigint not null
+);
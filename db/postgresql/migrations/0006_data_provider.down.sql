@@ -0,0 +1 @@
+alter table data drop column if exists provider;
@@ -0,0 +1,2 @@
+-- the databases set up before several data providers were supported have no provider column
+alter table data add column if not exists provider varchar(32) default '' not null;
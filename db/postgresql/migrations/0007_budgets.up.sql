@@ -0,0 +1,6 @@
+create table if not exists budgets
+(
+    provider varchar(32) not null primary key,
+    used     bigint not null,
+    reset_at bigint not null
+);
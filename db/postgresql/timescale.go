@@ -0,0 +1,284 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+const (
+	// chunkInterval of the data hypertable
+	chunkInterval = 24 * time.Hour
+
+	// timescaleLockId of the advisory lock held while the hypertable is set up, so the instances started at once with
+	// the timescale option don't race
+	timescaleLockId = 4_367_616_003
+)
+
+// aggregate is the continuous aggregate of the candles, the last startOffset is refreshed every schedule, the bars
+// younger than endOffset are aggregated from the raw rows on read
+type aggregate struct {
+	view        string
+	bucket      time.Duration
+	startOffset time.Duration
+	endOffset   time.Duration
+	schedule    time.Duration
+}
+
+var aggregates = []aggregate{
+	{view: "data_1m", bucket: time.Minute, startOffset: 24 * time.Hour, endOffset: time.Minute, schedule: time.Minute},
+	{view: "data_1h", bucket: time.Hour, startOffset: 48 * time.Hour, endOffset: time.Hour, schedule: time.Hour},
+	{view: "data_1d", bucket: 24 * time.Hour, startOffset: 72 * time.Hour, endOffset: 24 * time.Hour, schedule: time.Hour},
+}
+
+// candleViews the candles of the resolution are rolled up from, the resolution is a multiple of the view bucket
+var candleViews = map[domain.Resolution]string{
+	"1m": "data_1m",
+	"5m": "data_1m",
+	"1h": "data_1h",
+	"1d": "data_1d",
+}
+
+// Timescale turn the data table into the hypertable partitioned on the ts, create the continuous aggregates of the
+// candles of every data provider and apply the compression and retention policies, zero compressAfter or retention
+// removes the policy. It does nothing and returns false when the TimescaleDB extension is not installed. The ts column
+// must be backfilled first. The first run migrates the rows to the hypertable in one transaction and materializes the
+// aggregates, the table is locked for a while then on the big table, so it is run by the explicit command or on the
+// startup when it is enabled. The instances run it one at a time.
+func (d *Db) Timescale(ctx context.Context, compressAfter, retention time.Duration) (bool, error) {
+	conn, err := d.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	unlock, err := sessionLock(ctx, conn, timescaleLockId)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+	defer unlock()
+
+	var installed bool
+	if err = d.QueryRowContext(ctx,
+		`select exists(select 1 from pg_extension where extname='timescaledb');`,
+	).Scan(&installed); err != nil {
+		return false, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	if !installed {
+		return false, nil
+	}
+
+	backfilled, err := d.backfilled(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if !backfilled {
+		return false, errBackfillPending
+	}
+
+	if err = d.createHypertable(ctx); err != nil {
+		return false, fmt.Errorf("failed to create hypertable: %w", err)
+	}
+
+	if err = d.setCompression(ctx, compressAfter); err != nil {
+		return false, fmt.Errorf("failed to set compression: %w", err)
+	}
+
+	for _, a := range aggregates {
+		if err = d.createAggregate(ctx, a); err != nil {
+			return false, fmt.Errorf("failed to create continuous aggregate %s: %w", a.view, err)
+		}
+	}
+
+	if err = d.setRetention(ctx, retention); err != nil {
+		return false, fmt.Errorf("failed to set retention: %w", err)
+	}
+
+	d.aggregates.Store(true)
+
+	return true, nil
+}
+
+func (d *Db) createHypertable(ctx context.Context) error {
+	var exists bool
+	if err := d.QueryRowContext(ctx,
+		`select exists(select 1 from timescaledb_information.hypertables where hypertable_name='data');`,
+	).Scan(&exists); err != nil {
+		return fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	tx, err := d.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// the unique indexes of the hypertable must include the partitioning column
+	for _, query := range []string{
+		`alter table data drop constraint if exists data_pkey;`,
+		`alter table data add primary key (_id, ts);`,
+		fmt.Sprintf(`select create_hypertable('data', 'ts', chunk_time_interval => interval '%s', migrate_data => true);`,
+			pgInterval(chunkInterval)),
+	} {
+		if _, err = tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("%w: %w", errExecuteQuery, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return nil
+}
+
+func (d *Db) setCompression(ctx context.Context, compressAfter time.Duration) error {
+	if _, err := d.ExecContext(ctx,
+		`select remove_compression_policy('data', if_exists => true);`,
+	); err != nil {
+		return fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	if compressAfter == 0 {
+		return nil
+	}
+
+	var enabled bool
+	if err := d.QueryRowContext(ctx,
+		`select compression_enabled from timescaledb_information.hypertables where hypertable_name='data';`,
+	).Scan(&enabled); err != nil {
+		return fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	// the settings can't be changed while there are compressed chunks, so they are set only once
+	if !enabled {
+		if _, err := d.ExecContext(ctx, `alter table data set (
+		    timescaledb.compress,
+		    timescaledb.compress_segmentby = 'fromsym, tosym, provider',
+		    timescaledb.compress_orderby = 'ts, _id'
+		);`); err != nil {
+			return fmt.Errorf("%w: %w", errExecuteQuery, err)
+		}
+	}
+
+	if _, err := d.ExecContext(ctx,
+		`select add_compression_policy('data', compress_after => $1::interval);`, pgInterval(compressAfter),
+	); err != nil {
+		return fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return nil
+}
+
+func (d *Db) createAggregate(ctx context.Context, a aggregate) error {
+	var exists bool
+	if err := d.QueryRowContext(ctx, `select to_regclass($1) is not null;`, a.view).Scan(&exists); err != nil {
+		return fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	if !exists {
+		// the view is materialized outside the transaction, the bars of the last endOffset are aggregated on read
+		for _, query := range []string{
+			fmt.Sprintf(`create materialized view %s
+			with (timescaledb.continuous, timescaledb.materialized_only = false) as
+			select
+			       fromsym,
+			       tosym,
+			       provider,
+			       time_bucket(interval '%s', ts) as bucket,
+			       first(price, ts) as open,
+			       max(price) as high,
+			       min(price) as low,
+			       last(price, ts) as close,
+			       last(volume24hour, ts) as volume24hour
+			from data
+			group by fromsym, tosym, provider, bucket
+			with no data;`, a.view, pgInterval(a.bucket)),
+			fmt.Sprintf(`call refresh_continuous_aggregate('%s', null, now() - interval '%s');`,
+				a.view, pgInterval(a.endOffset)),
+		} {
+			if _, err := d.ExecContext(ctx, query); err != nil {
+				return fmt.Errorf("%w: %w", errExecuteQuery, err)
+			}
+		}
+	}
+
+	if _, err := d.ExecContext(ctx, `select add_continuous_aggregate_policy($1,
+		    start_offset => $2::interval,
+		    end_offset => $3::interval,
+		    schedule_interval => $4::interval,
+		    if_not_exists => true
+		);`, a.view, pgInterval(a.startOffset), pgInterval(a.endOffset), pgInterval(a.schedule),
+	); err != nil {
+		return fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return nil
+}
+
+func (d *Db) setRetention(ctx context.Context, retention time.Duration) error {
+	if _, err := d.ExecContext(ctx, `select remove_retention_policy('data', if_exists => true);`); err != nil {
+		return fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	if retention == 0 {
+		return nil
+	}
+
+	// only the raw rows are dropped, the bars of the continuous aggregates are kept
+	if _, err := d.ExecContext(ctx,
+		`select add_retention_policy('data', drop_after => $1::interval);`, pgInterval(retention),
+	); err != nil {
+		return fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return nil
+}
+
+// candleView return the continuous aggregate the candles of the resolution are read from, the aggregates are looked
+// up until they are found, so the instances which do not set them up read them as well once they are created
+func (d *Db) candleView(ctx context.Context, r domain.Resolution) (string, bool) {
+	view, ok := candleViews[r]
+	if !ok {
+		return "", false
+	}
+
+	if d.aggregates.Load() {
+		return view, true
+	}
+
+	var found bool
+	if err := d.QueryRowContext(ctx,
+		`select to_regclass('data_1m') is not null
+		    and to_regclass('data_1h') is not null
+		    and to_regclass('data_1d') is not null;`,
+	).Scan(&found); err != nil {
+		return "", false
+	}
+
+	// the missing aggregates are not cached, they may be created by the other instance later
+	if found {
+		d.aggregates.Store(true)
+	}
+
+	return view, found
+}
+
+// pgInterval of the duration
+func pgInterval(d time.Duration) string {
+	return fmt.Sprintf("%d seconds", int64(d/time.Second))
+}
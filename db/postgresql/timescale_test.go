@@ -0,0 +1,73 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+func Test_candleViews(t *testing.T) {
+	buckets := make(map[string]time.Duration, len(aggregates))
+	for _, a := range aggregates {
+		buckets[a.view] = a.bucket
+	}
+
+	for _, r := range domain.Resolutions() {
+		view, ok := candleViews[r]
+		if !ok {
+			t.Errorf("no continuous aggregate for the %s resolution", r)
+
+			continue
+		}
+
+		bucket, ok := buckets[view]
+		if !ok {
+			t.Errorf("continuous aggregate %s of the %s resolution is not created", view, r)
+
+			continue
+		}
+
+		if resolution := time.Duration(r.Millis()) * time.Millisecond; resolution%bucket != 0 {
+			t.Errorf("%s resolution is not a multiple of the %s bucket %s", r, view, bucket)
+		}
+	}
+}
+
+func Test_aggregates(t *testing.T) {
+	for _, a := range aggregates {
+		if a.startOffset < 2*a.bucket+a.endOffset {
+			t.Errorf("%s refresh window must cover at least two buckets", a.view)
+		}
+
+		if a.startOffset >= 7*24*time.Hour {
+			t.Errorf("%s refresh window must be shorter than the minimal retention", a.view)
+		}
+	}
+}
+
+func Test_pgInterval(t *testing.T) {
+	tests := []struct {
+		name string
+		d    time.Duration
+		want string
+	}{
+		{
+			name: "minute",
+			d:    time.Minute,
+			want: "60 seconds",
+		},
+		{
+			name: "day",
+			d:    24 * time.Hour,
+			want: "86400 seconds",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pgInterval(tt.d); got != tt.want {
+				t.Errorf("pgInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+var errEmptyAlert = errors.New("empty alert")
+
+// Alerts return all alert rules ordered by id
+func (d *Db) Alerts(ctx context.Context) ([]*domain.Alert, error) {
+	query := `
+		select _id, fromsym, tosym, rule, threshold, window_seconds, cooldown_seconds, webhook_url
+		from alerts
+		order by _id;
+`
+
+	//nolint:sqlclosecheck
+	rows, err := d.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var alerts []*domain.Alert
+
+	for rows.Next() {
+		a := &domain.Alert{}
+		if err = rows.Scan(
+			&a.Id, &a.From, &a.To, &a.Rule, &a.Threshold, &a.Window, &a.CoolDown, &a.WebhookUrl,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %w", errCopyResult, err)
+		}
+
+		alerts = append(alerts, a)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", errParseResults, rows.Err())
+	}
+
+	return alerts, nil
+}
+
+// AddAlert rule and return its id
+func (d *Db) AddAlert(ctx context.Context, a *domain.Alert) (int64, error) {
+	if a == nil {
+		return 0, errEmptyAlert
+	}
+
+	query := `
+		insert into alerts (fromsym, tosym, rule, threshold, window_seconds, cooldown_seconds, webhook_url)
+		values (?,?,?,?,?,?,?)
+		returning _id;
+`
+
+	var id int64
+	if err := d.QueryRowContext(
+		ctx, query, a.From, a.To, string(a.Rule), a.Threshold, a.Window, a.CoolDown, a.WebhookUrl,
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return id, nil
+}
+
+// UpdateAlert rule with the id of the given one
+func (d *Db) UpdateAlert(ctx context.Context, a *domain.Alert) (sql.Result, error) {
+	if a == nil {
+		return nil, errEmptyAlert
+	}
+
+	query := `
+		update alerts
+		set fromsym=?, tosym=?, rule=?, threshold=?,
+		    window_seconds=?, cooldown_seconds=?, webhook_url=?
+		where _id=?;
+`
+
+	result, err := d.ExecContext(
+		ctx, query, a.From, a.To, string(a.Rule), a.Threshold, a.Window, a.CoolDown, a.WebhookUrl, a.Id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return result, nil
+}
+
+// RemoveAlert rule by id
+func (d *Db) RemoveAlert(ctx context.Context, id int64) (sql.Result, error) {
+	result, err := d.ExecContext(ctx, `delete from alerts where _id=?;`, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return result, nil
+}
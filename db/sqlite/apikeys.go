@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+var errEmptyApiKey = errors.New("empty api key")
+
+// ApiKeys return all api keys ordered by id
+func (d *Db) ApiKeys(ctx context.Context) ([]*domain.ApiKey, error) {
+	query := `
+		select _id, name, prefix, hash, scopes, created
+		from api_keys
+		order by _id;
+`
+
+	//nolint:sqlclosecheck
+	rows, err := d.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var keys []*domain.ApiKey
+
+	for rows.Next() {
+		var (
+			k      = &domain.ApiKey{}
+			scopes string
+		)
+		if err = rows.Scan(&k.Id, &k.Name, &k.Prefix, &k.Hash, &scopes, &k.Created); err != nil {
+			return nil, fmt.Errorf("%w: %w", errCopyResult, err)
+		}
+
+		k.Scopes = domain.SplitScopes(scopes)
+		keys = append(keys, k)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", errParseResults, rows.Err())
+	}
+
+	return keys, nil
+}
+
+// AddApiKey and return its id
+func (d *Db) AddApiKey(ctx context.Context, k *domain.ApiKey) (int64, error) {
+	if k == nil {
+		return 0, errEmptyApiKey
+	}
+
+	query := `
+		insert into api_keys (name, prefix, hash, scopes, created)
+		values (?,?,?,?,?)
+		returning _id;
+`
+
+	var id int64
+	if err := d.QueryRowContext(
+		ctx, query, k.Name, k.Prefix, k.Hash, strings.Join(k.Scopes, ","), k.Created,
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return id, nil
+}
+
+// UpdateApiKey with the id of the given one
+func (d *Db) UpdateApiKey(ctx context.Context, k *domain.ApiKey) (sql.Result, error) {
+	if k == nil {
+		return nil, errEmptyApiKey
+	}
+
+	query := `
+		update api_keys
+		set name=?, prefix=?, hash=?, scopes=?, created=?
+		where _id=?;
+`
+
+	result, err := d.ExecContext(ctx, query, k.Name, k.Prefix, k.Hash, strings.Join(k.Scopes, ","), k.Created, k.Id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return result, nil
+}
+
+// RemoveApiKey by id
+func (d *Db) RemoveApiKey(ctx context.Context, id int64) (sql.Result, error) {
+	result, err := d.ExecContext(ctx, `delete from api_keys where _id=?;`, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return result, nil
+}
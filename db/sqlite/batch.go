@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/streamdp/ccd/db/symbolid"
+	"github.com/streamdp/ccd/domain"
+)
+
+// InsertBatch of the rows within the single transaction, rows with unknown symbols are skipped, the number of the
+// inserted rows is returned
+func (d *Db) InsertBatch(ctx context.Context, data []*domain.Data) (int64, error) {
+	var errs []error
+
+	type row struct {
+		from, to int64
+		data     *domain.Data
+	}
+
+	rows := make([]row, 0, len(data))
+
+	for _, r := range data {
+		from, err := d.symbols.Get(ctx, r.FromSymbol)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		to, err := d.symbols.Get(ctx, r.ToSymbol)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		rows = append(rows, row{from: from, to: to, data: r})
+	}
+
+	if len(rows) == 0 {
+		return 0, errors.Join(errs...)
+	}
+
+	tx, err := d.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.Join(append(errs, fmt.Errorf("%w: %w", errExecuteQuery, err))...)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	//nolint:sqlclosecheck
+	stmt, err := tx.PrepareContext(ctx, `insert into data (
+                  fromSym, toSym, change24hour, changepct24hour, open24hour, volume24hour, low24hour, high24hour,
+                  price, supply, mktcap, lastupdate, displaydataraw, provider
+        ) values (?,?,?,?,?,?,?,?,?,?,?,?,?,?)`)
+	if err != nil {
+		return 0, errors.Join(append(errs, fmt.Errorf("%w: %w", errExecuteQuery, err))...)
+	}
+	defer func(stmt *sql.Stmt) {
+		_ = stmt.Close()
+	}(stmt)
+
+	for _, r := range rows {
+		if _, err = stmt.ExecContext(ctx,
+			r.from,
+			r.to,
+			r.data.Change24Hour,
+			r.data.ChangePct24Hour,
+			r.data.Open24Hour,
+			r.data.Volume24Hour,
+			r.data.Low24Hour,
+			r.data.High24Hour,
+			r.data.Price,
+			r.data.Supply,
+			r.data.MktCap,
+			r.data.LastUpdate,
+			r.data.DisplayDataRaw,
+			r.data.Provider,
+		); err != nil {
+			return 0, errors.Join(append(errs, fmt.Errorf("%w: %w", errExecuteQuery, err))...)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Join(append(errs, fmt.Errorf("%w: %w", errExecuteQuery, err))...)
+	}
+
+	return int64(len(rows)), errors.Join(errs...)
+}
+
+func (d *Db) symbolId(ctx context.Context, symbol string) (int64, error) {
+	var id int64
+	if err := d.QueryRowContext(ctx, `select _id from symbols where symbol=?;`, symbol).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%w: %s", symbolid.ErrUnknownSymbol, symbol)
+		}
+
+		return 0, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return id, nil
+}
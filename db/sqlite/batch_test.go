@@ -0,0 +1,37 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/streamdp/ccd/db/symbolid"
+	"github.com/streamdp/ccd/domain"
+)
+
+func TestDb_InsertBatch(t *testing.T) {
+	d := newTestDb(t)
+	ctx := context.Background()
+
+	n, err := d.InsertBatch(ctx, []*domain.Data{
+		{FromSymbol: "BTC", ToSymbol: "USDT", Price: 100, LastUpdate: 1747644163},
+		{FromSymbol: "FOO", ToSymbol: "USDT", Price: 1, LastUpdate: 1747644163},
+		{FromSymbol: "eth", ToSymbol: "usdt", Price: 10, LastUpdate: 1747644164},
+	})
+	if !errors.Is(err, symbolid.ErrUnknownSymbol) {
+		t.Errorf("InsertBatch() error = %v, want %v", err, symbolid.ErrUnknownSymbol)
+	}
+
+	if n != 2 {
+		t.Errorf("InsertBatch() = %v, want 2", n)
+	}
+
+	got, err := d.GetLast(ctx, "", "ETH", "USDT")
+	if err != nil {
+		t.Fatalf("GetLast() error = %v", err)
+	}
+
+	if got.Price != 10 {
+		t.Errorf("GetLast() price = %v, want 10", got.Price)
+	}
+}
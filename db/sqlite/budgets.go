@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+var errEmptyBudget = errors.New("empty budget")
+
+// Budgets return the used monthly budgets of all data providers ordered by the provider name
+func (d *Db) Budgets(ctx context.Context) ([]*domain.Budget, error) {
+	//nolint:sqlclosecheck
+	rows, err := d.QueryContext(ctx, `select provider, used, reset_at from budgets order by provider;`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var budgets []*domain.Budget
+
+	for rows.Next() {
+		b := &domain.Budget{}
+		if err = rows.Scan(&b.Provider, &b.Used, &b.ResetAt); err != nil {
+			return nil, fmt.Errorf("%w: %w", errCopyResult, err)
+		}
+
+		budgets = append(budgets, b)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", errParseResults, rows.Err())
+	}
+
+	return budgets, nil
+}
+
+// SaveBudget of the data provider, it replaces the saved one
+func (d *Db) SaveBudget(ctx context.Context, b *domain.Budget) (sql.Result, error) {
+	if b == nil {
+		return nil, errEmptyBudget
+	}
+
+	query := `
+		insert into budgets (provider, used, reset_at)
+		values (?,?,?)
+		on conflict (provider) do update set used=excluded.used, reset_at=excluded.reset_at;
+`
+
+	result, err := d.ExecContext(ctx, query, b.Provider, b.Used, b.ResetAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/streamdp/ccd/db/symbolid"
+	"github.com/streamdp/ccd/domain"
+	_ "modernc.org/sqlite"
+)
+
+const Sqlite = "sqlite"
+
+// Db needed to add new methods for an instance *sql.Db
+type Db struct {
+	*sql.DB
+
+	symbols *symbolid.Cache
+
+	pipe chan *domain.Data
+}
+
+func (d *Db) DataPipe() chan *domain.Data {
+	return d.pipe
+}
+
+// Connect to the database file, the file is created when it doesn't exist
+func Connect(dataSource string) (*Db, error) {
+	sqlDb, err := sql.Open(Sqlite, dataSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// sqlite allows the single writer, the one connection also keeps the ":memory:" database alive
+	sqlDb.SetMaxOpenConns(1)
+
+	d := &Db{
+		DB:   sqlDb,
+		pipe: make(chan *domain.Data, 1000),
+	}
+	d.symbols = symbolid.New(d.symbolId)
+
+	return d, nil
+}
+
+// Close Db connection
+func (d *Db) Close() error {
+	defer close(d.pipe)
+
+	if d.DB == nil {
+		return nil
+	}
+
+	if err := d.DB.Close(); err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+
+	return nil
+}
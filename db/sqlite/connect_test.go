@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/streamdp/ccd/db/dbtest"
+	"github.com/streamdp/ccd/pkg/migrate"
+)
+
+func newTestDb(t *testing.T) *Db {
+	t.Helper()
+
+	d, err := Connect(filepath.Join(t.TempDir(), "ccd.db"))
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	t.Cleanup(func() { _ = d.Close() })
+
+	m, err := d.Migrator()
+	if err != nil {
+		t.Fatalf("Migrator() error = %v", err)
+	}
+
+	dbtest.Migrate(t, d.DB, m)
+
+	return d
+}
+
+func TestDb(t *testing.T) {
+	dbtest.Run(t, func(t *testing.T) dbtest.Store {
+		t.Helper()
+
+		return newTestDb(t)
+	})
+}
+
+func TestDb_Migrator(t *testing.T) {
+	d := newTestDb(t)
+
+	m, err := d.Migrator()
+	if err != nil {
+		t.Fatalf("Migrator() error = %v", err)
+	}
+
+	if _, err = m.Down(context.Background()); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+
+	n, err := m.Up(context.Background())
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	if n != 1 {
+		t.Errorf("Up() = %v, want 1", n)
+	}
+
+	// the baseline is never rolled back, so the data it adopted is kept
+	for {
+		if _, err = m.Down(context.Background()); err != nil {
+			break
+		}
+	}
+
+	if !errors.Is(err, migrate.ErrIrreversible) {
+		t.Errorf("Down() error = %v, wantErr %v", err, migrate.ErrIrreversible)
+	}
+
+	if _, err = d.ExecContext(context.Background(), "select count(*) from data"); err != nil {
+		t.Errorf("baseline table is dropped: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+var (
+	errEmptyData  = errors.New("cant insert empty data")
+	errEmptyQuery = errors.New("empty query")
+)
+
+// GetLast row with the most recent data of the data provider for the selected currencies pair, the row of any data
+// provider is returned when the provider is empty, the rows are ordered by the row time in milliseconds
+func (d *Db) GetLast(ctx context.Context, provider, from, to string) (*domain.Data, error) {
+	result := &domain.Data{
+		FromSymbol: from,
+		ToSymbol:   to,
+	}
+
+	query := `
+		select
+		       _id,
+		       change24hour,
+		       changepct24hour,
+		       open24hour,
+		       volume24hour,
+		       low24hour,
+		       high24hour,
+		       price,
+		       supply,
+		       mktcap,
+		       lastupdate,
+		       displaydataraw,
+		       provider
+		from data
+		where fromSym=(select _id from symbols where symbol=?)
+		  and toSym=(select _id from symbols where symbol=?)
+		  and (? = '' or provider in (?, ''))
+		ORDER BY case when lastupdate < 1000000000000 then lastupdate * 1000 else lastupdate end DESC, _id DESC
+		limit 1;
+`
+	if err := d.QueryRowContext(ctx, query, from, to, provider, provider).Scan(
+		&result.Id,
+		&result.Change24Hour,
+		&result.ChangePct24Hour,
+		&result.Open24Hour,
+		&result.Volume24Hour,
+		&result.Low24Hour,
+		&result.High24Hour,
+		&result.Price,
+		&result.Supply,
+		&result.MktCap,
+		&result.LastUpdate,
+		&result.DisplayDataRaw,
+		&result.Provider,
+	); err != nil {
+		return nil, fmt.Errorf("%w: %w", errCopyResult, err)
+	}
+
+	return result, nil
+}
+
+// Insert clients.Data from the clients.DataPipe to the Db
+func (d *Db) Insert(ctx context.Context, data *domain.Data) (sql.Result, error) {
+	if data == nil {
+		return nil, errEmptyData
+	}
+
+	query := `insert into data (
+                  fromSym,
+                  toSym,
+                  change24hour,
+                  changepct24hour,
+                  open24hour,
+                  volume24hour,
+                  low24hour,
+                  high24hour,
+                  price,
+                  supply,
+                  mktcap,
+                  lastupdate,
+                  displaydataraw,
+                  provider
+        )
+		values (
+		        (SELECT _id FROM symbols WHERE symbol=?),
+		        (SELECT _id FROM symbols WHERE symbol=?),
+		        ?,?,?,?,?,?,?,?,?,?,?,?
+		)
+`
+
+	result, err := d.ExecContext(
+		ctx,
+		query,
+		data.FromSymbol,
+		data.ToSymbol,
+		data.Change24Hour,
+		data.ChangePct24Hour,
+		data.Open24Hour,
+		data.Volume24Hour,
+		data.Low24Hour,
+		data.High24Hour,
+		data.Price,
+		data.Supply,
+		data.MktCap,
+		data.LastUpdate,
+		data.DisplayDataRaw,
+		data.Provider,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+
+	return result, nil
+}
+
+// GetRange rows for the selected currencies pair ordered by the lastupdate, one extra row is fetched to detect the
+// next page
+func (d *Db) GetRange(ctx context.Context, q *domain.RangeQuery) ([]*domain.Data, error) {
+	if q == nil {
+		return nil, errEmptyQuery
+	}
+
+	query := `
+		select
+		       _id,
+		       change24hour,
+		       changepct24hour,
+		       open24hour,
+		       volume24hour,
+		       low24hour,
+		       high24hour,
+		       price,
+		       supply,
+		       mktcap,
+		       lastupdate,
+		       displaydataraw,
+		       provider
+		from (
+		    select *,
+		           case when lastupdate < 1000000000000 then lastupdate * 1000 else lastupdate end as ts
+		    from data
+		    where fromSym=(select _id from symbols where symbol=?)
+		      and toSym=(select _id from symbols where symbol=?)
+		      and (? = '' or provider in (?, ''))
+		) d
+		where ts >= ?
+		  and (? = 0 or ts <= ?)
+`
+	args := []any{q.From, q.To, q.Provider, q.Provider, q.Start, q.End, q.End}
+
+	if q.Cursor != nil {
+		query += `		  and (ts, _id) > (?, ?)
+`
+		args = append(args, q.Cursor.LastUpdate, q.Cursor.Id)
+	}
+
+	query += fmt.Sprintf("		ORDER BY ts, _id limit %d;", q.Limit+1)
+
+	//nolint:sqlclosecheck
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExecuteQuery, err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var result []*domain.Data
+
+	for rows.Next() {
+		data := &domain.Data{
+			FromSymbol: q.From,
+			ToSymbol:   q.To,
+		}
+		if err = rows.Scan(
+			&data.Id,
+			&data.Change24Hour,
+			&data.ChangePct24Hour,
+			&data.Open24Hour,
+			&data.Volume24Hour,
+			&data.Low24Hour,
+			&data.High24Hour,
+			&data.Price,
+			&data.Supply,
+			&data.MktCap,
+			&data.LastUpdate,
+			&data.DisplayDataRaw,
+			&data.Provider,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %w", errCopyResult, err)
+		}
+
+		result = append(result, data)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", errParseResults, rows.Err())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/streamdp/ccd/pkg/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrator of the embedded schema migrations
+func (d *Db) Migrator() (*migrate.Migrator, error) {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
+
+	// the database is written by the single connection, so it needs no lock
+	return migrate.New(d.DB, fsys, func(int) string {
+		return "?"
+	}, nil)
+}
@@ -0,0 +1,47 @@
+create table if not exists data
+(
+    _id             integer primary key autoincrement,
+    fromsym         integer not null,
+    tosym           integer not null,
+    change24hour    real,
+    changepct24hour real,
+    open24hour      real,
+    volume24hour    real,
+    low24hour       real,
+    high24hour      real,
+    price           real,
+    supply          real,
+    mktcap          real,
+    lastupdate      integer not null,
+    displaydataraw  text,
+    provider        varchar(32) default '' not null
+);
+
+create index if not exists data_pair_lastupdate_index
+    on data (fromsym, tosym, lastupdate);
+
+create table if not exists symbols
+(
+    _id     integer primary key autoincrement,
+    symbol  varchar(64) default '' not null constraint symbols_symbol_uindex unique,
+    unicode char
+);
+
+insert into symbols(symbol, unicode)
+values ('USDT','₮'),
+       ('BTC','₿'),
+       ('ETH','⟠'),
+       ('USD','$'),
+       ('XRP','✕'),
+       ('LTC','Ł'),
+       ('EUR','€'),
+       ('GBP','£'),
+       ('JPY','¥')
+on conflict (symbol) do nothing;
+
+create table if not exists session
+(
+    _id        integer primary key autoincrement,
+    task_name  varchar(64) not null constraint session_task_name_uindex unique,
+    "interval" integer default 60 not null
+);
@@ -0,0 +1,11 @@
+create table if not exists alerts
+(
+    _id              integer primary key autoincrement,
+    fromsym          varchar(64) not null,
+    tosym            varchar(64) not null,
+    rule             varchar(32) not null,
+    threshold        real not null,
+    window_seconds   integer default 0 not null,
+    cooldown_seconds integer default 0 not null,
+    webhook_url      text default '' not null
+);
@@ -0,0 +1,9 @@
+create table if not exists api_keys
+(
+    _id     integer primary key autoincrement,
+    name    text not null,
+    prefix  text not null,
+    hash    text not null unique,
+    scopes  text not null,
+    created integer not null
+);
@@ -0,0 +1,6 @@
+create table if not exists budgets
+(
+    provider text not null primary key,
+    used     integer not null,
+    reset_at integer not null
+);
@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/streamdp/ccd/db/symbolid"
+	"github.com/streamdp/ccd/domain"
+)
+
+func TestDb_symbols(t *testing.T) {
+	d := newTestDb(t)
+
+	if _, err := d.AddSymbol("foo", "f"); err != nil {
+		t.Fatalf("AddSymbol() error = %v", err)
+	}
+
+	if _, err := d.UpdateSymbol("foo", "ƒ"); err != nil {
+		t.Fatalf("UpdateSymbol() error = %v", err)
+	}
+
+	symbols, err := d.Symbols()
+	if err != nil {
+		t.Fatalf("Symbols() error = %v", err)
+	}
+
+	var got *domain.Symbol
+	for _, s := range symbols {
+		if s.Symbol == "FOO" {
+			got = s
+		}
+	}
+
+	if got == nil || got.Unicode != 'Ƒ' {
+		t.Errorf("Symbols() FOO = %v, want unicode %q", got, 'Ƒ')
+	}
+
+	if _, err = d.symbols.Get(context.Background(), "FOO"); err != nil {
+		t.Fatalf("symbols.Get() error = %v", err)
+	}
+
+	if _, err = d.RemoveSymbol("foo"); err != nil {
+		t.Fatalf("RemoveSymbol() error = %v", err)
+	}
+
+	if _, err = d.symbols.Get(context.Background(), "FOO"); !errors.Is(err, symbolid.ErrUnknownSymbol) {
+		t.Errorf("symbols.Get() after RemoveSymbol() error = %v, want %v", err, symbolid.ErrUnknownSymbol)
+	}
+}
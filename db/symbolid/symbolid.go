@@ -0,0 +1,58 @@
+package symbolid
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+var ErrUnknownSymbol = errors.New("unknown symbol")
+
+// Cache of the symbol ids used by the batch inserts, the id is looked up in the database on the first use of the
+// symbol, so the rows are inserted without the symbol subqueries
+type Cache struct {
+	lookup func(ctx context.Context, symbol string) (int64, error)
+
+	ids map[string]int64
+	mu  sync.RWMutex
+}
+
+func New(lookup func(ctx context.Context, symbol string) (int64, error)) *Cache {
+	return &Cache{
+		lookup: lookup,
+		ids:    make(map[string]int64),
+	}
+}
+
+// Get the id of the symbol
+func (c *Cache) Get(ctx context.Context, symbol string) (int64, error) {
+	symbol = strings.ToUpper(symbol)
+
+	c.mu.RLock()
+	id, ok := c.ids[symbol]
+	c.mu.RUnlock()
+
+	if ok {
+		return id, nil
+	}
+
+	id, err := c.lookup(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.ids[symbol] = id
+	c.mu.Unlock()
+
+	return id, nil
+}
+
+// Remove the symbol id, it is looked up again on the next use
+func (c *Cache) Remove(symbol string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.ids, strings.ToUpper(symbol))
+}
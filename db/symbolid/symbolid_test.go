@@ -0,0 +1,78 @@
+package symbolid
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCache_Get(t *testing.T) {
+	ids := map[string]int64{"BTC": 1, "USD": 2}
+	lookups := 0
+
+	c := New(func(_ context.Context, symbol string) (int64, error) {
+		lookups++
+
+		id, ok := ids[symbol]
+		if !ok {
+			return 0, ErrUnknownSymbol
+		}
+
+		return id, nil
+	})
+
+	tests := []struct {
+		name        string
+		symbol      string
+		want        int64
+		wantLookups int
+		wantErr     error
+	}{
+		{
+			name:        "look up the symbol",
+			symbol:      "btc",
+			want:        1,
+			wantLookups: 1,
+		},
+		{
+			name:        "cached symbol",
+			symbol:      "BTC",
+			want:        1,
+			wantLookups: 1,
+		},
+		{
+			name:        "unknown symbol is not cached",
+			symbol:      "XXX",
+			wantLookups: 2,
+			wantErr:     ErrUnknownSymbol,
+		},
+		{
+			name:        "unknown symbol is looked up again",
+			symbol:      "XXX",
+			wantLookups: 3,
+			wantErr:     ErrUnknownSymbol,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Get(context.Background(), tt.symbol)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Get() got = %v, want %v", got, tt.want)
+			}
+
+			if lookups != tt.wantLookups {
+				t.Errorf("lookups = %v, want %v", lookups, tt.wantLookups)
+			}
+		})
+	}
+
+	c.Remove("btc")
+
+	if _, err := c.Get(context.Background(), "BTC"); err != nil || lookups != 4 {
+		t.Errorf("Get() after Remove() error = %v, lookups = %v, want 4", err, lookups)
+	}
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/metrics"
+	"github.com/streamdp/ccd/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// BatchInserter is implemented by the databases able to save several rows at once, the number of the saved rows is
+// returned
+type BatchInserter interface {
+	InsertBatch(ctx context.Context, data []*domain.Data) (int64, error)
+}
+
+// BatchPolicy of the data pipe writer, the batch is saved when it is full or the interval is over
+type BatchPolicy struct {
+	Size     int
+	Interval time.Duration
+}
+
+// Serve save the data from the data pipe to the database with batches until ctx is done or the pipe is closed, the
+// rows collected so far are saved on the stop, the batch in flight isn't aborted by ctx
+func Serve(ctx context.Context, d Database, l *log.Logger, batch BatchPolicy) {
+	var (
+		insertCtx = context.WithoutCancel(ctx)
+		rows      = make([]*domain.Data, 0, batch.Size)
+		t         = time.NewTimer(batch.Interval)
+	)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			write(insertCtx, d, l, rows)
+
+			return
+		case data, ok := <-d.DataPipe():
+			if !ok {
+				write(insertCtx, d, l, rows)
+
+				return
+			}
+
+			if rows = append(rows, data); len(rows) < batch.Size {
+				continue
+			}
+
+			write(insertCtx, d, l, rows)
+			rows = rows[:0]
+			t.Reset(batch.Interval)
+		case <-t.C:
+			write(insertCtx, d, l, rows)
+			rows = rows[:0]
+			t.Reset(batch.Interval)
+		}
+	}
+}
+
+// Flush save the data left in the data pipe to the database, it returns the error when ctx is done before the pipe
+// is drained
+func Flush(ctx context.Context, d Database, l *log.Logger, batch BatchPolicy) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%d records are lost: %w", len(d.DataPipe()), err)
+		}
+
+		rows := drain(d.DataPipe(), batch.Size)
+		if len(rows) == 0 {
+			return nil
+		}
+
+		write(ctx, d, l, rows)
+	}
+}
+
+// drain up to size items waiting in the pipe without blocking
+func drain(pipe chan *domain.Data, size int) []*domain.Data {
+	var rows []*domain.Data
+
+	for len(rows) < size {
+		select {
+		case data, ok := <-pipe:
+			if !ok {
+				return rows
+			}
+
+			rows = append(rows, data)
+		default:
+			return rows
+		}
+	}
+
+	return rows
+}
+
+// write the rows with the single batch, databases without the batch support save the rows one by one
+func write(ctx context.Context, d Database, l *log.Logger, rows []*domain.Data) {
+	if len(rows) == 0 {
+		return
+	}
+
+	b, ok := d.(BatchInserter)
+	if !ok {
+		for _, data := range rows {
+			insert(ctx, d, l, data)
+		}
+
+		return
+	}
+
+	traceParents := make([]string, len(rows))
+	for i := range rows {
+		traceParents[i] = rows[i].Trace()
+	}
+
+	ctx, span := tracing.StartLinked(ctx, "db.InsertBatch", traceParents, attribute.Int("rows", len(rows)))
+	start := time.Now()
+
+	n, err := b.InsertBatch(ctx, rows)
+	if err != nil {
+		l.Printf("failed to save %d of %d rows: %v", int64(len(rows))-n, len(rows), err)
+	}
+
+	if failed := int64(len(rows)) - n; failed > 0 {
+		metrics.DbBatchErrors.Add(float64(failed))
+	}
+
+	metrics.DbBatchSize.Observe(float64(len(rows)))
+	metrics.DbBatchDuration.Observe(time.Since(start).Seconds())
+	tracing.End(span, err)
+}
+
+func insert(ctx context.Context, d Database, l *log.Logger, data *domain.Data) {
+	ctx, span := tracing.Start(tracing.WithTraceParent(ctx, data.Trace()), "db.Insert",
+		attribute.String("provider", data.Provider),
+		attribute.String("pair", data.FromSymbol+"/"+data.ToSymbol),
+	)
+	start := time.Now()
+
+	_, err := d.Insert(ctx, data)
+	if err != nil {
+		metrics.DbInsertErrors.Inc()
+		l.Println(err)
+	}
+
+	metrics.DbInsertDuration.Observe(time.Since(start).Seconds())
+	tracing.End(span, err)
+}
@@ -0,0 +1,240 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+type mockDatabase struct {
+	dataPipe chan *domain.Data
+	inserted []*domain.Data
+	mu       sync.Mutex
+}
+
+func (m *mockDatabase) Insert(_ context.Context, data *domain.Data) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inserted = append(m.inserted, data)
+
+	return nil, nil
+}
+
+func (m *mockDatabase) GetLast(_ context.Context, _, _, _ string) (*domain.Data, error) {
+	return nil, nil
+}
+
+func (m *mockDatabase) GetRange(_ context.Context, _ *domain.RangeQuery) ([]*domain.Data, error) {
+	return nil, nil
+}
+
+func (m *mockDatabase) DataPipe() chan *domain.Data {
+	return m.dataPipe
+}
+
+func (m *mockDatabase) Close() error {
+	return nil
+}
+
+func (m *mockDatabase) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.inserted)
+}
+
+type mockBatchDatabase struct {
+	mockDatabase
+
+	batches []int
+}
+
+func (m *mockBatchDatabase) InsertBatch(_ context.Context, data []*domain.Data) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+
+	for _, row := range data {
+		if row.FromSymbol == "" {
+			continue
+		}
+
+		m.inserted = append(m.inserted, row)
+		n++
+	}
+
+	m.batches = append(m.batches, len(data))
+
+	if n != int64(len(data)) {
+		return n, errors.New("unknown symbol")
+	}
+
+	return n, nil
+}
+
+func (m *mockBatchDatabase) sizes() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]int(nil), m.batches...)
+}
+
+func TestServe(t *testing.T) {
+	tests := []struct {
+		name        string
+		batch       BatchPolicy
+		data        int
+		wantBatches []int
+	}{
+		{
+			name:        "flush full batches",
+			batch:       BatchPolicy{Size: 2, Interval: time.Hour},
+			data:        4,
+			wantBatches: []int{2, 2},
+		},
+		{
+			name:        "flush on the interval",
+			batch:       BatchPolicy{Size: 10, Interval: 10 * time.Millisecond},
+			data:        3,
+			wantBatches: []int{3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &mockBatchDatabase{mockDatabase: mockDatabase{dataPipe: make(chan *domain.Data, 10)}}
+			ctx, cancel := context.WithCancel(context.Background())
+			served := make(chan struct{})
+
+			go func() {
+				defer close(served)
+
+				Serve(ctx, d, log.New(io.Discard, "", 0), tt.batch)
+			}()
+
+			for range tt.data {
+				d.dataPipe <- &domain.Data{FromSymbol: "BTC", ToSymbol: "USD"}
+			}
+
+			for deadline := time.Now().Add(time.Second); d.count() < tt.data && time.Now().Before(deadline); {
+				time.Sleep(time.Millisecond)
+			}
+
+			cancel()
+			<-served
+
+			if got := d.sizes(); !reflect.DeepEqual(got, tt.wantBatches) {
+				t.Errorf("batches = %v, want %v", got, tt.wantBatches)
+			}
+		})
+	}
+}
+
+func TestServe_stop(t *testing.T) {
+	d := &mockDatabase{dataPipe: make(chan *domain.Data, 10)}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan struct{})
+
+	go func() {
+		defer close(served)
+
+		Serve(ctx, d, log.New(io.Discard, "", 0), BatchPolicy{Size: 10, Interval: time.Hour})
+	}()
+
+	d.dataPipe <- &domain.Data{FromSymbol: "BTC", ToSymbol: "USD"}
+	d.dataPipe <- &domain.Data{FromSymbol: "ETH", ToSymbol: "USD"}
+
+	cancel()
+	<-served
+
+	// the rows collected before the stop are saved by the Serve, the rows left in the pipe by the Flush
+	if err := Flush(context.Background(), d, log.New(io.Discard, "", 0), BatchPolicy{Size: 10}); err != nil {
+		t.Errorf("Flush() error = %v", err)
+	}
+
+	if len(d.inserted) != 2 {
+		t.Errorf("inserted = %v, want 2", len(d.inserted))
+	}
+
+	if len(d.dataPipe) != 0 {
+		t.Errorf("len(dataPipe) = %v, want 0", len(d.dataPipe))
+	}
+}
+
+func TestFlush(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		data         []*domain.Data
+		wantInserted int
+		wantBatches  []int
+		wantErr      error
+	}{
+		{
+			name: "flush the data left in the pipe with batches",
+			ctx:  context.Background(),
+			data: []*domain.Data{
+				{FromSymbol: "BTC", ToSymbol: "USD"},
+				{FromSymbol: "ETH", ToSymbol: "USD"},
+				{FromSymbol: "XRP", ToSymbol: "USD"},
+			},
+			wantInserted: 3,
+			wantBatches:  []int{2, 1},
+		},
+		{
+			name: "failed rows are skipped",
+			ctx:  context.Background(),
+			data: []*domain.Data{
+				{FromSymbol: "BTC", ToSymbol: "USD"},
+				{ToSymbol: "USD"},
+			},
+			wantInserted: 1,
+			wantBatches:  []int{2},
+		},
+		{
+			name: "empty pipe",
+			ctx:  context.Background(),
+		},
+		{
+			name: "deadline is over",
+			ctx:  canceled,
+			data: []*domain.Data{
+				{FromSymbol: "BTC", ToSymbol: "USD"},
+			},
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &mockBatchDatabase{mockDatabase: mockDatabase{dataPipe: make(chan *domain.Data, 10)}}
+			for _, data := range tt.data {
+				d.dataPipe <- data
+			}
+
+			err := Flush(tt.ctx, d, log.New(io.Discard, "", 0), BatchPolicy{Size: 2})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Flush() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(d.inserted) != tt.wantInserted {
+				t.Errorf("inserted = %v, want %v", len(d.inserted), tt.wantInserted)
+			}
+
+			if got := d.sizes(); !reflect.DeepEqual(got, tt.wantBatches) {
+				t.Errorf("batches = %v, want %v", got, tt.wantBatches)
+			}
+		})
+	}
+}
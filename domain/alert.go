@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxAlertWindow limits the window of the percent move and volume spike rules, the prices are kept in memory for it
+const maxAlertWindow = 24 * 60 * 60
+
+var (
+	ErrInvalidAlert  = errors.New("invalid alert")
+	ErrAlertNotFound = errors.New("alert not found")
+)
+
+// AlertRule is the condition the alert is fired on
+type AlertRule string
+
+const (
+	// AlertPriceAbove fires when the price is above the threshold
+	AlertPriceAbove AlertRule = "price_above"
+	// AlertPriceBelow fires when the price is below the threshold
+	AlertPriceBelow AlertRule = "price_below"
+	// AlertPercentMove fires when the price moved by the threshold percent up or down within the window
+	AlertPercentMove AlertRule = "percent_move"
+	// AlertVolumeSpike fires when the 24h volume grew by the threshold percent within the window
+	AlertVolumeSpike AlertRule = "volume_spike"
+)
+
+// Alert is the user-defined rule watching the collected data of the currencies pair, Window and CoolDown are in
+// seconds, the alert is not fired again until the cool-down is over
+type Alert struct {
+	Id         int64     `json:"id"`
+	From       string    `json:"fsym"`
+	To         string    `json:"tsym"`
+	Rule       AlertRule `json:"rule"`
+	Threshold  float64   `json:"threshold"`
+	Window     int64     `json:"window,omitempty"`
+	CoolDown   int64     `json:"cooldown"`
+	WebhookUrl string    `json:"webhook_url,omitempty"`
+}
+
+// AlertEvent is the fired alert, Value is the price for the price rules and the change in percent for the others,
+// Timestamp is in milliseconds
+type AlertEvent struct {
+	Alert     *Alert  `json:"alert"`
+	Value     float64 `json:"value"`
+	Data      *Data   `json:"data"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// Windowed report whether the rule compares the data within the window
+func (r AlertRule) Windowed() bool {
+	return r == AlertPercentMove || r == AlertVolumeSpike
+}
+
+// Validate the alert rule
+func (a *Alert) Validate() error {
+	switch a.Rule {
+	case AlertPriceAbove, AlertPriceBelow, AlertPercentMove, AlertVolumeSpike:
+	default:
+		return fmt.Errorf("%w: unknown rule %q", ErrInvalidAlert, a.Rule)
+	}
+
+	if a.From == "" || a.To == "" {
+		return fmt.Errorf("%w: pair is required", ErrInvalidAlert)
+	}
+
+	if a.Threshold <= 0 {
+		return fmt.Errorf("%w: threshold must be positive", ErrInvalidAlert)
+	}
+
+	if a.Rule.Windowed() && (a.Window <= 0 || a.Window > maxAlertWindow) {
+		return fmt.Errorf("%w: window must be from 1 to %d seconds", ErrInvalidAlert, maxAlertWindow)
+	}
+
+	if a.CoolDown < 0 {
+		return fmt.Errorf("%w: cooldown must not be negative", ErrInvalidAlert)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAlert_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		alert   *Alert
+		wantErr error
+	}{
+		{
+			name:  "price above",
+			alert: &Alert{From: "BTC", To: "USD", Rule: AlertPriceAbove, Threshold: 70000, CoolDown: 300},
+		},
+		{
+			name:  "percent move",
+			alert: &Alert{From: "BTC", To: "USD", Rule: AlertPercentMove, Threshold: 5, Window: 3600},
+		},
+		{
+			name:    "unknown rule",
+			alert:   &Alert{From: "BTC", To: "USD", Rule: "price_equal", Threshold: 70000},
+			wantErr: ErrInvalidAlert,
+		},
+		{
+			name:    "empty pair",
+			alert:   &Alert{From: "BTC", Rule: AlertPriceBelow, Threshold: 70000},
+			wantErr: ErrInvalidAlert,
+		},
+		{
+			name:    "zero threshold",
+			alert:   &Alert{From: "BTC", To: "USD", Rule: AlertPriceBelow},
+			wantErr: ErrInvalidAlert,
+		},
+		{
+			name:    "windowed rule without window",
+			alert:   &Alert{From: "BTC", To: "USD", Rule: AlertVolumeSpike, Threshold: 50},
+			wantErr: ErrInvalidAlert,
+		},
+		{
+			name:    "window is too long",
+			alert:   &Alert{From: "BTC", To: "USD", Rule: AlertVolumeSpike, Threshold: 50, Window: maxAlertWindow + 1},
+			wantErr: ErrInvalidAlert,
+		},
+		{
+			name:    "negative cooldown",
+			alert:   &Alert{From: "BTC", To: "USD", Rule: AlertPriceAbove, Threshold: 70000, CoolDown: -1},
+			wantErr: ErrInvalidAlert,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.alert.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidApiKey  = errors.New("invalid api key")
+	ErrApiKeyNotFound = errors.New("api key not found")
+	ErrUnauthorized   = errors.New("api key is missing or unknown")
+	ErrForbidden      = errors.New("api key has no scope")
+)
+
+const (
+	// ScopeRead allows to get the prices, the history, the symbols, the tasks, the alerts and the sinks
+	ScopeRead = "read"
+	// ScopeCollectWrite allows to manage the puller tasks and the data provider ws subscriptions
+	ScopeCollectWrite = "collect:write"
+	// ScopeSymbolsWrite allows to manage the symbols
+	ScopeSymbolsWrite = "symbols:write"
+	// ScopeWs allows to connect to the ws server
+	ScopeWs = "ws"
+	// ScopeAdmin allows everything, including the api keys, the alerts and the sinks management
+	ScopeAdmin = "admin"
+)
+
+var scopes = []string{ScopeRead, ScopeCollectWrite, ScopeSymbolsWrite, ScopeWs, ScopeAdmin}
+
+// ApiKey grants its scopes to the requests sent with it, only the hash of the key is kept, the key itself is shown
+// once when it is created or rotated, Prefix is the beginning of the key to tell the keys apart, Created is in seconds
+type ApiKey struct {
+	Id      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Prefix  string   `json:"prefix"`
+	Scopes  []string `json:"scopes"`
+	Created int64    `json:"created"`
+	Hash    string   `json:"-"`
+}
+
+// Validate the api key
+func (k *ApiKey) Validate() error {
+	if strings.TrimSpace(k.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidApiKey)
+	}
+
+	if len(k.Scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidApiKey)
+	}
+
+	for _, s := range k.Scopes {
+		if !slices.Contains(scopes, s) {
+			return fmt.Errorf("%w: unknown scope %q, use %s", ErrInvalidApiKey, s, strings.Join(scopes, ", "))
+		}
+	}
+
+	return nil
+}
+
+// Allows report whether the key has the scope, the admin key has all of them
+func (k *ApiKey) Allows(scope string) bool {
+	return slices.Contains(k.Scopes, scope) || slices.Contains(k.Scopes, ScopeAdmin)
+}
+
+// SplitScopes the string like "read,ws", empty scopes are skipped
+func SplitScopes(s string) []string {
+	var res []string
+
+	for scope := range strings.SplitSeq(s, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			res = append(res, scope)
+		}
+	}
+
+	return res
+}
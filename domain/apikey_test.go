@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApiKey_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     *ApiKey
+		wantErr error
+	}{
+		{
+			name: "valid",
+			key:  &ApiKey{Name: "dashboard", Scopes: []string{ScopeRead, ScopeWs}},
+		},
+		{
+			name:    "empty name",
+			key:     &ApiKey{Name: " ", Scopes: []string{ScopeRead}},
+			wantErr: ErrInvalidApiKey,
+		},
+		{
+			name:    "no scopes",
+			key:     &ApiKey{Name: "dashboard"},
+			wantErr: ErrInvalidApiKey,
+		},
+		{
+			name:    "unknown scope",
+			key:     &ApiKey{Name: "dashboard", Scopes: []string{ScopeRead, "write"}},
+			wantErr: ErrInvalidApiKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.key.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApiKey_Allows(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		scope  string
+		want   bool
+	}{
+		{
+			name:   "granted scope",
+			scopes: []string{ScopeRead, ScopeWs},
+			scope:  ScopeWs,
+			want:   true,
+		},
+		{
+			name:   "missing scope",
+			scopes: []string{ScopeRead},
+			scope:  ScopeCollectWrite,
+		},
+		{
+			name:   "admin has all scopes",
+			scopes: []string{ScopeAdmin},
+			scope:  ScopeSymbolsWrite,
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &ApiKey{Scopes: tt.scopes}
+			if got := k.Allows(tt.scope); got != tt.want {
+				t.Errorf("Allows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitScopes(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []string
+	}{
+		{
+			name: "empty",
+			s:    "",
+		},
+		{
+			name: "several scopes",
+			s:    "read, collect:write,,ws",
+			want: []string{ScopeRead, ScopeCollectWrite, ScopeWs},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitScopes(tt.s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitScopes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
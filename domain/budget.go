@@ -0,0 +1,8 @@
+package domain
+
+// Budget of the upstream calls used by the data provider within the calendar month reset at ResetAt (unix seconds)
+type Budget struct {
+	Provider string `json:"provider"`
+	Used     int64  `json:"used"`
+	ResetAt  int64  `json:"reset_at"`
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// millisThreshold separates timestamps stored in seconds (cryptocompare) from the ones stored in milliseconds
+const millisThreshold = 1_000_000_000_000
+
+var (
+	ErrUnknownResolution = errors.New("unknown resolution")
+	ErrRangeTooLarge     = errors.New("too many rows in the range to aggregate, narrow the range down")
+)
+
+// Resolution of the candle bars, e.g. "1m", "5m", "1h", "1d"
+type Resolution string
+
+var resolutions = map[Resolution]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// Candle structure for easily json serialization, Time is the bar open time in milliseconds, the traded volume of the
+// bar is not known, so Volume24Hour is the rolling 24h volume at the bar close reported by the data provider
+type Candle struct {
+	Time         int64   `json:"time"`
+	Open         float64 `json:"open"`
+	High         float64 `json:"high"`
+	Low          float64 `json:"low"`
+	Close        float64 `json:"close"`
+	Volume24Hour float64 `json:"volume_24_hour"`
+}
+
+// CandleQuery describes the selection of candles for the selected currencies pair, Start and End are in milliseconds,
+// rows of all data providers are aggregated when the Provider is empty, the legacy rows stored without the provider
+// match any Provider
+type CandleQuery struct {
+	Provider   string
+	From       string
+	To         string
+	Resolution Resolution
+	Start      int64
+	End        int64
+	Limit      int
+}
+
+// Resolutions return all supported resolutions ordered from the shortest to the longest
+func Resolutions() []Resolution {
+	return []Resolution{"1m", "5m", "1h", "1d"}
+}
+
+// ParseResolution validate the resolution name
+func ParseResolution(s string) (Resolution, error) {
+	if _, ok := resolutions[Resolution(s)]; !ok {
+		return "", ErrUnknownResolution
+	}
+
+	return Resolution(s), nil
+}
+
+// Millis return the resolution length in milliseconds
+func (r Resolution) Millis() int64 {
+	return resolutions[r].Milliseconds()
+}
+
+// Bucket return the open time of the bar the timestamp belongs to
+func (r Resolution) Bucket(ts int64) int64 {
+	step := r.Millis()
+	if step == 0 {
+		return 0
+	}
+
+	ts = MilliTimestamp(ts)
+
+	return ts - ts%step
+}
+
+// MilliTimestamp convert timestamp stored in seconds to milliseconds, timestamps in milliseconds stay untouched
+func MilliTimestamp(ts int64) int64 {
+	if ts < millisThreshold {
+		return ts * 1000
+	}
+
+	return ts
+}
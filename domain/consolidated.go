@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+)
+
+const (
+	MethodMedian = "median"
+	MethodVWAP   = "vwap"
+)
+
+var ErrNoPrices = errors.New("no prices to consolidate")
+
+// ProviderPrice is the part of the consolidated price answered by the single data provider, Deviation is the distance
+// from the median in percent
+type ProviderPrice struct {
+	Provider     string  `json:"provider"`
+	Price        float64 `json:"price"`
+	Volume24Hour float64 `json:"volume_24_hour"`
+	LastUpdate   int64   `json:"last_update"`
+	Deviation    float64 `json:"deviation"`
+	Outlier      bool    `json:"outlier"`
+	Error        string  `json:"error,omitempty"`
+}
+
+// ConsolidatedPrice structure for easily json serialization of the price built from several data providers
+type ConsolidatedPrice struct {
+	FromSymbol string           `json:"from_sym"`
+	ToSymbol   string           `json:"to_sym"`
+	Method     string           `json:"method"`
+	Price      float64          `json:"price"`
+	Median     float64          `json:"median"`
+	Threshold  float64          `json:"threshold"`
+	LastUpdate int64            `json:"last_update"`
+	Providers  []*ProviderPrice `json:"providers"`
+}
+
+// NewConsolidatedPrice flag prices deviated from the median more than threshold percent as outliers and build the
+// median or volume-weighted price from the rest of them, prices with errors are kept in the breakdown only
+func NewConsolidatedPrice(
+	from, to, method string,
+	threshold float64,
+	prices []*ProviderPrice,
+) (*ConsolidatedPrice, error) {
+	var valid []*ProviderPrice
+
+	for _, p := range prices {
+		if p.Error == "" && p.Price > 0 {
+			valid = append(valid, p)
+		}
+	}
+
+	if len(valid) == 0 {
+		return nil, ErrNoPrices
+	}
+
+	c := &ConsolidatedPrice{
+		FromSymbol: from,
+		ToSymbol:   to,
+		Method:     method,
+		Median:     median(valid),
+		Threshold:  threshold,
+		Providers:  prices,
+	}
+
+	var inliers []*ProviderPrice
+
+	for _, p := range valid {
+		p.Deviation = (p.Price - c.Median) / c.Median * 100
+		if p.Outlier = math.Abs(p.Deviation) > threshold; !p.Outlier {
+			inliers = append(inliers, p)
+		}
+
+		c.LastUpdate = max(c.LastUpdate, MilliTimestamp(p.LastUpdate))
+	}
+
+	// with two providers or the zero threshold every price may deviate from the median, none of them is an outlier then
+	if len(inliers) == 0 {
+		for _, p := range valid {
+			p.Outlier = false
+		}
+
+		inliers = valid
+	}
+
+	c.Price = median(inliers)
+	if method == MethodVWAP {
+		if vwap, ok := volumeWeighted(inliers); ok {
+			c.Price = vwap
+		}
+	}
+
+	return c, nil
+}
+
+// Data convert the consolidated price to the form stored in the database, the breakdown is kept in the DisplayDataRaw
+func (c *ConsolidatedPrice) Data(provider string) *Data {
+	d := &Data{
+		FromSymbol: c.FromSymbol,
+		ToSymbol:   c.ToSymbol,
+		Provider:   provider,
+		Price:      c.Price,
+		LastUpdate: c.LastUpdate,
+	}
+
+	for _, p := range c.Providers {
+		if p.Error == "" && !p.Outlier {
+			d.Volume24Hour += p.Volume24Hour
+		}
+	}
+
+	if b, err := json.Marshal(c); err == nil {
+		d.DisplayDataRaw = string(b)
+	}
+
+	return d
+}
+
+func median(prices []*ProviderPrice) float64 {
+	if len(prices) == 0 {
+		return 0
+	}
+
+	p := make([]float64, 0, len(prices))
+	for _, v := range prices {
+		p = append(p, v.Price)
+	}
+
+	slices.Sort(p)
+
+	if n := len(p); n%2 == 0 {
+		return (p[n/2-1] + p[n/2]) / 2
+	}
+
+	return p[len(p)/2]
+}
+
+func volumeWeighted(prices []*ProviderPrice) (float64, bool) {
+	var sum, volume float64
+
+	for _, p := range prices {
+		sum += p.Price * p.Volume24Hour
+		volume += p.Volume24Hour
+	}
+
+	if volume <= 0 {
+		return 0, false
+	}
+
+	return sum / volume, true
+}
@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewConsolidatedPrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		threshold float64
+		prices    []*ProviderPrice
+		want      *ConsolidatedPrice
+		wantErr   error
+	}{
+		{
+			name:   "no valid prices",
+			method: MethodMedian,
+			prices: []*ProviderPrice{
+				{Provider: "kraken", Error: "upstream is down"},
+			},
+			wantErr: ErrNoPrices,
+		},
+		{
+			name:      "median without outliers",
+			method:    MethodMedian,
+			threshold: 2,
+			prices: []*ProviderPrice{
+				{Provider: "cryptocompare", Price: 100, LastUpdate: 1747644163},
+				{Provider: "huobi", Price: 101, LastUpdate: 1747644164000},
+			},
+			want: &ConsolidatedPrice{
+				Method:     MethodMedian,
+				Price:      100.5,
+				Median:     100.5,
+				Threshold:  2,
+				LastUpdate: 1747644164000,
+				Providers: []*ProviderPrice{
+					{Provider: "cryptocompare", Price: 100, LastUpdate: 1747644163, Deviation: -0.4975124378109453},
+					{Provider: "huobi", Price: 101, LastUpdate: 1747644164000, Deviation: 0.4975124378109453},
+				},
+			},
+		},
+		{
+			name:      "vwap with outlier and failed provider",
+			method:    MethodVWAP,
+			threshold: 2,
+			prices: []*ProviderPrice{
+				{Provider: "cryptocompare", Price: 100, Volume24Hour: 1, LastUpdate: 1747644163000},
+				{Provider: "huobi", Price: 101, Volume24Hour: 3, LastUpdate: 1747644163000},
+				{Provider: "kraken", Price: 120, Volume24Hour: 10, LastUpdate: 1747644163000},
+				{Provider: "binance", Error: "upstream is down"},
+			},
+			want: &ConsolidatedPrice{
+				Method:     MethodVWAP,
+				Price:      100.75,
+				Median:     101,
+				Threshold:  2,
+				LastUpdate: 1747644163000,
+				Providers: []*ProviderPrice{
+					{
+						Provider:     "cryptocompare",
+						Price:        100,
+						Volume24Hour: 1,
+						LastUpdate:   1747644163000,
+						Deviation:    -0.9900990099009901,
+					},
+					{Provider: "huobi", Price: 101, Volume24Hour: 3, LastUpdate: 1747644163000},
+					{
+						Provider:     "kraken",
+						Price:        120,
+						Volume24Hour: 10,
+						LastUpdate:   1747644163000,
+						Deviation:    18.81188118811881,
+						Outlier:      true,
+					},
+					{Provider: "binance", Error: "upstream is down"},
+				},
+			},
+		},
+		{
+			name:      "all prices deviated are kept",
+			method:    MethodMedian,
+			threshold: 2,
+			prices: []*ProviderPrice{
+				{Provider: "kraken", Price: 100},
+				{Provider: "huobi", Price: 106},
+			},
+			want: &ConsolidatedPrice{
+				Method:    MethodMedian,
+				Price:     103,
+				Median:    103,
+				Threshold: 2,
+				Providers: []*ProviderPrice{
+					{Provider: "kraken", Price: 100, Deviation: -2.912621359223301},
+					{Provider: "huobi", Price: 106, Deviation: 2.912621359223301},
+				},
+			},
+		},
+		{
+			name:   "zero threshold keeps the median price only",
+			method: MethodMedian,
+			prices: []*ProviderPrice{
+				{Provider: "kraken", Price: 100},
+				{Provider: "huobi", Price: 101},
+				{Provider: "binance", Price: 103},
+			},
+			want: &ConsolidatedPrice{
+				Method: MethodMedian,
+				Price:  101,
+				Median: 101,
+				Providers: []*ProviderPrice{
+					{Provider: "kraken", Price: 100, Deviation: -0.9900990099009901, Outlier: true},
+					{Provider: "huobi", Price: 101},
+					{Provider: "binance", Price: 103, Deviation: 1.9801980198019802, Outlier: true},
+				},
+			},
+		},
+		{
+			name:   "zero threshold with even providers keeps all prices",
+			method: MethodVWAP,
+			prices: []*ProviderPrice{
+				{Provider: "kraken", Price: 100, Volume24Hour: 1},
+				{Provider: "huobi", Price: 102, Volume24Hour: 3},
+			},
+			want: &ConsolidatedPrice{
+				Method: MethodVWAP,
+				Price:  101.5,
+				Median: 101,
+				Providers: []*ProviderPrice{
+					{Provider: "kraken", Price: 100, Volume24Hour: 1, Deviation: -0.9900990099009901},
+					{Provider: "huobi", Price: 102, Volume24Hour: 3, Deviation: 0.9900990099009901},
+				},
+			},
+		},
+		{
+			name:      "vwap without volume falls back to median",
+			method:    MethodVWAP,
+			threshold: 2,
+			prices: []*ProviderPrice{
+				{Provider: "kraken", Price: 100},
+			},
+			want: &ConsolidatedPrice{
+				Method:    MethodVWAP,
+				Price:     100,
+				Median:    100,
+				Threshold: 2,
+				Providers: []*ProviderPrice{{Provider: "kraken", Price: 100}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewConsolidatedPrice("", "", tt.method, tt.threshold, tt.prices)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewConsolidatedPrice() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewConsolidatedPrice() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
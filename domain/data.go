@@ -1,13 +1,12 @@
 package domain
 
-import (
-	"encoding/json"
-)
+import "encoding/json"
 
 type Data struct {
 	Id              int64   `db:"_id"             json:"id"`
 	FromSymbol      string  `db:"fromSym"         json:"from_sym"`
 	ToSymbol        string  `db:"toSym"           json:"to_sym"`
+	Provider        string  `db:"provider"        json:"provider"`
 	Change24Hour    float64 `db:"change24hour"    json:"change_24_hour"`
 	ChangePct24Hour float64 `db:"changepct24hour" json:"change_pct_24_hour"`
 	Open24Hour      float64 `db:"open24hour"      json:"open_24_hour"`
@@ -19,6 +18,18 @@ type Data struct {
 	MktCap          float64 `db:"mktcap"          json:"mkt_cap"`
 	LastUpdate      int64   `db:"lastupdate"      json:"last_update"`
 	DisplayDataRaw  string  `db:"displaydataraw"  json:"display_data_raw"`
+
+	trace string
+}
+
+// Trace of the data collection in the W3C traceparent format, it follows the data through the data pipes
+func (d *Data) Trace() string {
+	return d.trace
+}
+
+// SetTrace of the data collection in the W3C traceparent format
+func (d *Data) SetTrace(traceParent string) {
+	d.trace = traceParent
 }
 
 // Raw structure for easily json serialization
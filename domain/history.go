@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// RangeQuery describes the selection of stored rows for the selected currencies pair, Start and End are in
+// milliseconds, rows of all data providers are selected when the Provider is empty, the legacy rows stored without
+// the provider match any Provider
+type RangeQuery struct {
+	Provider string
+	From     string
+	To       string
+	Start    int64
+	End      int64
+	Limit    int
+	Cursor   *Cursor
+}
+
+// Cursor points at the last row of the previously returned page, LastUpdate is in milliseconds
+type Cursor struct {
+	LastUpdate int64
+	Id         int64
+}
+
+// History structure for easily json serialization of a single page of stored rows
+type History struct {
+	Data       []*Data `json:"data"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// String encode the cursor to the opaque url safe form
+func (c *Cursor) String() string {
+	if c == nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d:%d", c.LastUpdate, c.Id))
+}
+
+// ParseCursor decode the cursor previously built by the Cursor.String
+func ParseCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	parts := strings.Split(string(b), ":")
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+
+	lastUpdate, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	return &Cursor{
+		LastUpdate: lastUpdate,
+		Id:         id,
+	}, nil
+}
+
+// NewHistory cut the extra row fetched to detect the next page and build the next page cursor
+func NewHistory(rows []*Data, limit int) *History {
+	h := &History{Data: rows}
+
+	if limit > 0 && len(rows) > limit {
+		h.Data = rows[:limit]
+		last := h.Data[limit-1]
+		h.NextCursor = (&Cursor{LastUpdate: MilliTimestamp(last.LastUpdate), Id: last.Id}).String()
+	}
+
+	if h.Data == nil {
+		h.Data = []*Data{}
+	}
+
+	return h
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    *Cursor
+		wantErr error
+	}{
+		{
+			name: "empty cursor",
+			s:    "",
+			want: nil,
+		},
+		{
+			name: "encoded cursor",
+			s:    (&Cursor{LastUpdate: 1747644163933, Id: 42}).String(),
+			want: &Cursor{LastUpdate: 1747644163933, Id: 42},
+		},
+		{
+			name:    "not base64",
+			s:       "!!!",
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "wrong format",
+			s:       "MTIz",
+			wantErr: ErrInvalidCursor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCursor(tt.s)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseCursor() error = %v, wantErr %v", err, tt.wantErr)
+
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCursor() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewHistory(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  []*Data
+		limit int
+		want  *History
+	}{
+		{
+			name:  "no rows",
+			rows:  nil,
+			limit: 2,
+			want:  &History{Data: []*Data{}},
+		},
+		{
+			name:  "last page",
+			rows:  []*Data{{Id: 1, LastUpdate: 1747644160000}, {Id: 2, LastUpdate: 1747644170000}},
+			limit: 2,
+			want:  &History{Data: []*Data{{Id: 1, LastUpdate: 1747644160000}, {Id: 2, LastUpdate: 1747644170000}}},
+		},
+		{
+			name: "has next page",
+			rows: []*Data{
+				{Id: 1, LastUpdate: 1747644160000},
+				{Id: 2, LastUpdate: 1747644170000},
+				{Id: 3, LastUpdate: 1747644180000},
+			},
+			limit: 2,
+			want: &History{
+				Data:       []*Data{{Id: 1, LastUpdate: 1747644160000}, {Id: 2, LastUpdate: 1747644170000}},
+				NextCursor: (&Cursor{LastUpdate: 1747644170000, Id: 2}).String(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewHistory(tt.rows, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewHistory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
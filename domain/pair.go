@@ -0,0 +1,7 @@
+package domain
+
+// Pair of currencies
+type Pair struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
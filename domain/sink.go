@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrInvalidSink  = errors.New("invalid sink")
+	ErrSinkNotFound = errors.New("sink not found")
+)
+
+// Sink is the http endpoint the collected data is posted to, the data of all pairs is posted when Pairs is empty, the
+// requests are signed with Secret when it is set
+type Sink struct {
+	Id     int64  `json:"id"`
+	Url    string `json:"url"`
+	Pairs  []Pair `json:"pairs,omitempty"`
+	Secret string `json:"-"`
+}
+
+// Validate the sink
+func (s *Sink) Validate() error {
+	u, err := url.Parse(s.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be the absolute http or https url", ErrInvalidSink)
+	}
+
+	for _, p := range s.Pairs {
+		if p.From == "" || p.To == "" || strings.ContainsAny(p.From+p.To, ":,") {
+			return fmt.Errorf("%w: wrong pair %q", ErrInvalidSink, JoinPairs([]Pair{p}))
+		}
+	}
+
+	return nil
+}
+
+// JoinPairs into the string like "BTC:USD,ETH:USDT"
+func JoinPairs(pairs []Pair) string {
+	s := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		s = append(s, p.From+":"+p.To)
+	}
+
+	return strings.Join(s, ",")
+}
+
+// SplitPairs the string like "BTC:USD,ETH:USDT", malformed pairs are skipped
+func SplitPairs(s string) []Pair {
+	var pairs []Pair
+
+	for p := range strings.SplitSeq(s, ",") {
+		if from, to, ok := strings.Cut(strings.TrimSpace(p), ":"); ok && from != "" && to != "" {
+			pairs = append(pairs, Pair{From: from, To: to})
+		}
+	}
+
+	return pairs
+}
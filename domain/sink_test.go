@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSink_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sink    *Sink
+		wantErr error
+	}{
+		{
+			name: "all pairs",
+			sink: &Sink{Url: "https://example.com/ticks"},
+		},
+		{
+			name: "selected pairs",
+			sink: &Sink{Url: "http://127.0.0.1:8081/ticks", Pairs: []Pair{{From: "BTC", To: "USD"}}},
+		},
+		{
+			name:    "relative url",
+			sink:    &Sink{Url: "/ticks"},
+			wantErr: ErrInvalidSink,
+		},
+		{
+			name:    "unsupported scheme",
+			sink:    &Sink{Url: "ftp://example.com/ticks"},
+			wantErr: ErrInvalidSink,
+		},
+		{
+			name:    "empty pair",
+			sink:    &Sink{Url: "https://example.com/ticks", Pairs: []Pair{{From: "BTC"}}},
+			wantErr: ErrInvalidSink,
+		},
+		{
+			name:    "pair with separator",
+			sink:    &Sink{Url: "https://example.com/ticks", Pairs: []Pair{{From: "BTC", To: "USD,ETH"}}},
+			wantErr: ErrInvalidSink,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.sink.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSplitPairs(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []Pair
+	}{
+		{
+			name: "empty",
+			s:    "",
+		},
+		{
+			name: "several pairs",
+			s:    "BTC:USD,ETH:USDT",
+			want: []Pair{{From: "BTC", To: "USD"}, {From: "ETH", To: "USDT"}},
+		},
+		{
+			name: "malformed pairs are skipped",
+			s:    "BTC:USD, ETH ,:USDT,LTC:",
+			want: []Pair{{From: "BTC", To: "USD"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitPairs(tt.s)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitPairs() = %v, want %v", got, tt.want)
+			}
+
+			if tt.want != nil && JoinPairs(got) != JoinPairs(tt.want) {
+				t.Errorf("JoinPairs() = %v, want %v", JoinPairs(got), JoinPairs(tt.want))
+			}
+		})
+	}
+}
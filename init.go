@@ -7,14 +7,15 @@ import (
 	"log"
 
 	"github.com/streamdp/ccd/clients"
+	"github.com/streamdp/ccd/clients/binance"
+	"github.com/streamdp/ccd/clients/coinbase"
 	"github.com/streamdp/ccd/clients/cryptocompare"
 	"github.com/streamdp/ccd/clients/huobi"
 	"github.com/streamdp/ccd/clients/kraken"
 	"github.com/streamdp/ccd/config"
-	"github.com/streamdp/ccd/db"
 	"github.com/streamdp/ccd/db/redis"
+	"github.com/streamdp/ccd/domain"
 	"github.com/streamdp/ccd/pkg/sessionrepo"
-	ws "github.com/streamdp/ccd/pkg/wsserver"
 )
 
 var (
@@ -23,19 +24,25 @@ var (
 	errInitSessionStore = errors.New("failed to init session store")
 )
 
-func initRestClient(cfg *config.App) (clients.RestClient, error) {
+func initRestClient(provider string, cfg *config.App) (clients.RestClient, error) {
 	var (
 		restClient clients.RestClient
 		err        error
 	)
 
-	switch cfg.DataProvider {
-	case "huobi":
+	switch provider {
+	case binance.Name:
+		restClient, err = binance.Init(cfg)
+	case coinbase.Name:
+		restClient, err = coinbase.Init(cfg)
+	case huobi.Name:
 		restClient, err = huobi.Init(cfg)
-	case "kraken":
+	case kraken.Name:
 		restClient, err = kraken.Init(cfg)
-	default:
+	case cryptocompare.Name:
 		restClient, err = cryptocompare.Init(cfg)
+	default:
+		err = fmt.Errorf("%w: %s", clients.ErrUnknownProvider, provider)
 	}
 
 	if err != nil {
@@ -47,24 +54,30 @@ func initRestClient(cfg *config.App) (clients.RestClient, error) {
 
 func initWsClient(
 	ctx context.Context,
-	d db.Database,
-	ws *ws.Server,
+	provider string,
 	sessionRepo clients.SessionRepo,
 	l *log.Logger,
 	cfg *config.App,
+	pipes ...chan *domain.Data,
 ) (clients.WsClient, error) {
 	var (
 		wsClient clients.WsClient
 		err      error
 	)
 
-	switch cfg.DataProvider {
-	case "huobi":
-		wsClient = huobi.InitWs(ctx, sessionRepo, l, cfg.Http, d.DataPipe(), ws.DataPipe())
-	case "kraken":
-		wsClient = kraken.InitWs(ctx, sessionRepo, l, cfg.Http, d.DataPipe(), ws.DataPipe())
+	switch provider {
+	case binance.Name:
+		wsClient = binance.InitWs(ctx, sessionRepo, l, cfg.Http, pipes...)
+	case coinbase.Name:
+		wsClient = coinbase.InitWs(ctx, sessionRepo, l, cfg.Http, pipes...)
+	case huobi.Name:
+		wsClient = huobi.InitWs(ctx, sessionRepo, l, cfg.Http, pipes...)
+	case kraken.Name:
+		wsClient = kraken.InitWs(ctx, sessionRepo, l, cfg.Http, pipes...)
+	case cryptocompare.Name:
+		wsClient, err = cryptocompare.InitWs(ctx, sessionRepo, l, cfg.Http, cfg.ApiKey, pipes...)
 	default:
-		wsClient, err = cryptocompare.InitWs(ctx, sessionRepo, l, cfg.Http, cfg.ApiKey, d.DataPipe(), ws.DataPipe())
+		err = fmt.Errorf("%w: %s", clients.ErrUnknownProvider, provider)
 	}
 
 	if err != nil {
@@ -74,6 +87,42 @@ func initWsClient(
 	return wsClient, nil
 }
 
+func initProviders(cfg *config.App, l *log.Logger, sessionRepo clients.SessionRepo) (*clients.Providers, error) {
+	names := cfg.DataProviders()
+	providers := clients.NewProviders(names[0], l, sessionRepo)
+
+	for _, name := range names {
+		restClient, err := initRestClient(name, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		providers.AddRest(name, restClient)
+	}
+
+	return providers, nil
+}
+
+func initWsClients(
+	ctx context.Context,
+	providers *clients.Providers,
+	sessionRepo clients.SessionRepo,
+	l *log.Logger,
+	cfg *config.App,
+	pipes ...chan *domain.Data,
+) error {
+	for _, name := range cfg.DataProviders() {
+		wsClient, err := initWsClient(ctx, name, sessionRepo, l, cfg, pipes...)
+		if err != nil {
+			return err
+		}
+
+		providers.AddWs(name, wsClient)
+	}
+
+	return nil
+}
+
 func newSessionRepo(s sessionrepo.SessionStore, cfg *config.App) (clients.SessionRepo, error) {
 	var (
 		sessionRepo clients.SessionRepo
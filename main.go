@@ -2,16 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
-	"net"
-	"strconv"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/streamdp/ccd/clients"
 	"github.com/streamdp/ccd/config"
 	"github.com/streamdp/ccd/db"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/alerts"
+	"github.com/streamdp/ccd/pkg/apikeys"
+	"github.com/streamdp/ccd/pkg/metrics"
+	"github.com/streamdp/ccd/pkg/mqttbridge"
+	"github.com/streamdp/ccd/pkg/natspub"
 	"github.com/streamdp/ccd/pkg/sessionrepo"
+	"github.com/streamdp/ccd/pkg/sinks"
 	"github.com/streamdp/ccd/pkg/symbolsrepo"
+	"github.com/streamdp/ccd/pkg/tracing"
 	ws "github.com/streamdp/ccd/pkg/wsserver"
 	"github.com/streamdp/ccd/server"
 )
@@ -29,12 +41,28 @@ func main() {
 	l.Printf("Run mode:\n")
 	l.Printf("\tVersion=%v\n", appCfg.Version())
 	l.Printf("\tRun mode=%v\n", appCfg.RunMode())
-	l.Printf("\tData provider=%v\n", appCfg.DataProvider)
+	l.Printf("\tData providers=%v\n", strings.Join(appCfg.DataProviders(), ","))
+	l.Printf("\tPrice failover chain=%v\n", strings.Join(appCfg.FailoverChain(), ","))
 	l.Printf("\tSession store=%v\n", appCfg.SessionStore)
 	l.Printf("\tPort=%v\n", appCfg.Http.Port())
+	l.Printf("\tAuth=%v\n", appCfg.Auth())
 
 	ctx := context.Background()
 
+	sigCtx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Init(ctx, appCfg.OtlpEndpoint, appCfg.Version())
+	if err != nil {
+		l.Fatalln(err)
+	}
+
+	defer func() {
+		if errShutdown := shutdownTracing(ctx); errShutdown != nil {
+			l.Printf("failed to shutdown tracing: %v", errShutdown)
+		}
+	}()
+
 	d, err := db.Connect(appCfg)
 	if err != nil {
 		l.Fatalln(err)
@@ -51,7 +79,65 @@ func main() {
 		}
 	}()
 
-	go db.Serve(ctx, database, l)
+	if cmd := appCfg.Command(); len(cmd) != 0 {
+		if err = runCommand(ctx, d, cmd, appCfg.Timescale); err != nil {
+			l.Fatalln(err)
+		}
+
+		return
+	}
+
+	// the databases without the schema, like the in-memory one, have nothing to migrate
+	if _, ok = d.(db.SchemaMigrator); ok && appCfg.Migrate() {
+		m, errMigrator := newMigrator(d)
+		if errMigrator != nil {
+			l.Fatalln(errMigrator)
+		}
+
+		n, errUp := m.Up(ctx)
+		if errUp != nil {
+			l.Fatalln(errUp)
+		}
+
+		l.Printf("applied %d database schema migrations", n)
+
+		// the rows stored before the columns were added are filled in batches while the service is running, the
+		// hypertable is set up after that when it is enabled
+		if b, isBackfiller := d.(db.Backfiller); isBackfiller {
+			go func() {
+				filled, errBackfill := b.Backfill(sigCtx)
+				if errBackfill != nil {
+					if !errors.Is(errBackfill, context.Canceled) {
+						l.Printf("failed to backfill the database: %v", errBackfill)
+					}
+
+					return
+				}
+
+				if filled > 0 {
+					l.Printf("backfilled %d database rows", filled)
+				}
+
+				if tsdb, isTimescaler := d.(db.Timescaler); isTimescaler && appCfg.Timescale.Enabled() {
+					setUpTimescale(sigCtx, l, tsdb, appCfg.Timescale)
+				}
+			}()
+		}
+	}
+
+	batch := db.BatchPolicy{
+		Size:     appCfg.Batch.Size(),
+		Interval: appCfg.Batch.Interval(),
+	}
+
+	serveCtx, stopServe := context.WithCancel(ctx)
+	served := make(chan struct{})
+
+	go func() {
+		defer close(served)
+
+		db.Serve(serveCtx, database, l, batch)
+	}()
 
 	sessionStore, ok := d.(sessionrepo.SessionStore)
 	if !ok {
@@ -79,37 +165,244 @@ func main() {
 		l.Fatalln(err)
 	}
 
-	restClient, err := initRestClient(appCfg)
+	providers, err := initProviders(appCfg, l, sessionRepo)
 	if err != nil {
 		l.Fatalln(err)
 	}
 
-	wsServer := ws.NewServer(ctx, l, restClient, database)
-	defer wsServer.Close()
+	budgetStore, ok := d.(clients.BudgetStore)
+	if !ok {
+		l.Fatalln("budget store type assertion error")
+	}
+
+	if err = providers.RestoreBudgets(ctx, budgetStore); err != nil {
+		l.Printf("error restoring the budgets: %v", err)
+	}
+
+	go providers.KeepBudgets(sigCtx, budgetStore, clients.BudgetSaveInterval)
+
+	defer func() {
+		if errClose := providers.Close(); errClose != nil {
+			l.Printf("failed to close data providers: %v", errClose)
+		}
+	}()
 
-	wsClient, err := initWsClient(ctx, database, wsServer, sessionRepo, l, appCfg)
+	failover, err := clients.NewFailover(
+		providers, appCfg.FailoverChain(), appCfg.Failover.Errors(), appCfg.Failover.CoolDown(),
+	)
 	if err != nil {
 		l.Fatalln(err)
 	}
 
-	if err = wsClient.RestoreLastSession(ctx); err != nil {
+	// the consolidated price is built from all data providers and collected like any other one
+	consolidated := clients.NewConsolidated(providers.RestClients())
+	providers.AddRest(clients.ConsolidatedName, consolidated)
+
+	wsServer := ws.NewServer(ctx, l, failover, database, providers.Default())
+
+	alertStore, ok := d.(alerts.Store)
+	if !ok {
+		l.Fatalln("alert store type assertion error")
+	}
+
+	// the alert webhooks are never sent unsigned, the rules with the webhook url are refused without the secret
+	notifiers := []alerts.Notifier{wsServer}
+
+	var webhook *alerts.Webhook
+	if appCfg.AlertsSecret != "" {
+		webhook, err = alerts.NewWebhook(l, clients.NewHttpClient(appCfg.Http.ClientTimeout()), appCfg.AlertsSecret)
+		if err != nil {
+			l.Fatalln(err)
+		}
+
+		notifiers = append(notifiers, webhook)
+	} else {
+		l.Println("CCDC_ALERTS_SECRET is not set, the alert rules with the webhook url are refused")
+	}
+
+	alertEngine := alerts.New(l, alertStore, notifiers...)
+	if err = alertEngine.Load(ctx); err != nil {
+		l.Fatalln(err)
+	}
+
+	sinkStore, ok := d.(sinks.Store)
+	if !ok {
+		l.Fatalln("sink store type assertion error")
+	}
+
+	dispatcher := sinks.New(l, sinkStore, clients.NewHttpClient(appCfg.Http.ClientTimeout()))
+	if err = dispatcher.Load(ctx); err != nil {
+		l.Fatalln(err)
+	}
+
+	apiKeyStore, ok := d.(apikeys.Store)
+	if !ok {
+		l.Fatalln("api key store type assertion error")
+	}
+
+	keys := apikeys.New(apiKeyStore)
+	if err = keys.Load(ctx); err != nil {
+		l.Fatalln(err)
+	}
+
+	go keys.Refresh(sigCtx, l, apikeys.RefreshInterval)
+
+	if appCfg.Auth() {
+		key, errBootstrap := keys.Bootstrap(ctx)
+		if errBootstrap != nil {
+			l.Fatalln(errBootstrap)
+		}
+
+		// the key is kept out of the log, the lost one is replaced with the key created by the "keys create" command
+		if key != "" {
+			l.Println("no api keys found, the admin key is created and written to stderr, it is not shown again")
+			_, _ = fmt.Fprintf(os.Stderr, "admin api key: %s\n", key)
+		}
+	} else {
+		l.Println("authentication is disabled, the api is open to everyone who can reach the port")
+	}
+
+	metrics.ObservePipe("db", database.DataPipe())
+	metrics.ObservePipe("ws", wsServer.DataPipe())
+	metrics.ObservePipe("alerts", alertEngine.DataPipe())
+	metrics.ObservePipe("sinks", dispatcher.DataPipe())
+	metrics.ObserveWsServer(wsServer)
+
+	pipes := []chan *domain.Data{
+		database.DataPipe(), wsServer.DataPipe(), alertEngine.DataPipe(), dispatcher.DataPipe(),
+	}
+
+	var publisher *natspub.Publisher
+	if appCfg.Nats.Enabled() {
+		if publisher, err = natspub.Connect(ctx, l, appCfg.Nats.Url, appCfg.Nats.Stream); err != nil {
+			l.Fatalln(err)
+		}
+
+		metrics.ObservePipe("nats", publisher.DataPipe())
+		pipes = append(pipes, publisher.DataPipe())
+	}
+
+	var bridge *mqttbridge.Bridge
+	if appCfg.Mqtt.Enabled() {
+		opts, errOpts := appCfg.Mqtt.Options()
+		if errOpts != nil {
+			l.Fatalln(errOpts)
+		}
+
+		if bridge, err = mqttbridge.Connect(ctx, l, opts, appCfg.Mqtt.Qos()); err != nil {
+			l.Fatalln(err)
+		}
+
+		metrics.ObservePipe("mqtt", bridge.DataPipe())
+		pipes = append(pipes, bridge.DataPipe())
+	}
+
+	if err = initWsClients(ctx, providers, sessionRepo, l, appCfg, pipes...); err != nil {
+		l.Fatalln(err)
+	}
+
+	if err = providers.RestoreLastSession(ctx); err != nil {
 		l.Printf("error restoring last ws session: %v", err)
 	}
 
-	restPuller := clients.NewPuller(restClient, l, sessionRepo, database.DataPipe(), wsServer.DataPipe())
+	retry := clients.RetryPolicy{
+		Attempts:        appCfg.Retry.Attempts(),
+		Backoff:         appCfg.Retry.Backoff(),
+		BreakerFailures: appCfg.Retry.BreakerFailures(),
+		BreakerCoolDown: appCfg.Retry.BreakerCoolDown(),
+	}
+
+	restPuller := clients.NewPuller(providers, l, sessionRepo, retry, pipes...)
 	if err = restPuller.RestoreLastSession(ctx); err != nil {
 		l.Printf("error restoring last rest session: %v", err)
 	}
 
-	srv := server.NewServer(database, symbolRepo, restClient, wsClient, restPuller, l, appCfg, wsServer)
+	if bridge != nil {
+		var auth mqttbridge.Authorizer
+		if appCfg.Auth() {
+			auth = keys
+		}
+
+		bridge.RunCommands(restPuller, providers, symbolRepo, auth)
+	}
+
+	srv := server.NewServer(
+		database, symbolRepo, providers, failover, consolidated, restPuller, alertEngine, dispatcher, keys, l,
+		appCfg, wsServer,
+	)
 	if err = srv.InitRouter(ctx); err != nil {
 		l.Fatalln(err)
 	}
 
-	srv.Run(
-		net.JoinHostPort("", strconv.Itoa(appCfg.Http.Port())),
-		appCfg.Http.ServerTimeout(),
-	)
+	go func() {
+		if errRun := srv.Run(); errRun != nil {
+			l.Println(errRun)
+			stop()
+		}
+	}()
+
+	<-sigCtx.Done()
+	stop()
+
+	l.Println("shutting down...")
+
+	// the data producers are stopped one by one before the data pipes are drained, the stores are closed by the
+	// deferred calls
+	shutdownCtx, cancel := context.WithTimeout(ctx, appCfg.Http.ShutdownTimeout())
+	defer cancel()
+
+	if err = srv.Shutdown(shutdownCtx); err != nil {
+		l.Println(err)
+	}
+
+	if bridge != nil {
+		bridge.StopCommands()
+	}
+
+	if err = restPuller.Close(); err != nil {
+		l.Printf("failed to close rest puller: %v", err)
+	}
+
+	if err = providers.SaveBudgets(shutdownCtx, budgetStore); err != nil {
+		l.Printf("failed to save budgets: %v", err)
+	}
+
+	if err = providers.CloseWs(shutdownCtx); err != nil {
+		l.Printf("failed to close ws clients: %v", err)
+	}
 
-	<-ctx.Done()
+	alertEngine.Close()
+
+	if webhook != nil {
+		if err = webhook.Close(shutdownCtx); err != nil {
+			l.Printf("failed to close alerts webhook: %v", err)
+		}
+	}
+
+	if err = dispatcher.Close(shutdownCtx); err != nil {
+		l.Printf("failed to close sinks: %v", err)
+	}
+
+	if publisher != nil {
+		if err = publisher.Close(shutdownCtx); err != nil {
+			l.Printf("failed to close nats publisher: %v", err)
+		}
+	}
+
+	if bridge != nil {
+		bridge.Close()
+	}
+
+	wsServer.Close()
+
+	stopServe()
+
+	select {
+	case <-served:
+		if err = db.Flush(shutdownCtx, database, l, batch); err != nil {
+			l.Printf("failed to flush the data pipe: %v", err)
+		}
+	case <-shutdownCtx.Done():
+		l.Printf("failed to flush the data pipe: %v", shutdownCtx.Err())
+	}
 }
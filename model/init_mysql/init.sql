@@ -1,3 +1,5 @@
+-- deprecated, the schema is set up by the migrations embedded into the binary, see the "Database schema" section
+-- of the README
 create database if not exists cryptocompare /*!40100 DEFAULT CHARACTER SET utf8 */;
 use cryptocompare;
 
@@ -17,7 +19,8 @@ create table data
     supply          double     null,
     mktcap          double     null,
     lastupdate      mediumtext not null,
-    displaydataraw  text       null
+    displaydataraw  text       null,
+    provider        varchar(32) not null default ''
 ) default charset utf8 collate = utf8_general_ci;
 
 drop table if exists symbols;
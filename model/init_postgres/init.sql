@@ -1,3 +1,5 @@
+-- deprecated, the schema is set up by the migrations embedded into the binary, see the "Database schema" section
+-- of the README
 create database cryptocompare;
 
 drop table if exists data;
@@ -16,7 +18,8 @@ create table data
     supply          double precision,
     mktcap          double precision,
     lastupdate      text   not null,
-    displaydataraw  text
+    displaydataraw  text,
+    provider        varchar(32) default '' not null
 );
 
 drop table if exists symbols;
@@ -0,0 +1,358 @@
+package alerts
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+// Store of the alert rules
+type Store interface {
+	Alerts(ctx context.Context) (alerts []*domain.Alert, err error)
+	AddAlert(ctx context.Context, a *domain.Alert) (id int64, err error)
+	UpdateAlert(ctx context.Context, a *domain.Alert) (result sql.Result, err error)
+	RemoveAlert(ctx context.Context, id int64) (result sql.Result, err error)
+}
+
+// Notifier delivers the fired alerts, it must not block
+type Notifier interface {
+	Notify(e *domain.AlertEvent)
+}
+
+// rule of the alert, the time it fired at is kept in memory only, so the cool-downs start over after the restart
+type rule struct {
+	alert   *domain.Alert
+	firedAt time.Time
+}
+
+// seriesKey of the prices kept for the windowed rules, the data providers report the pair prices apart
+type seriesKey struct {
+	provider string
+	pair     domain.Pair
+}
+
+type sample struct {
+	at     time.Time
+	price  float64
+	volume float64
+}
+
+// Engine evaluates the alert rules against the collected data received from its data pipe
+type Engine struct {
+	l *log.Logger
+
+	store     Store
+	notifiers []Notifier
+
+	// webhooks report whether the fired alerts are posted to the webhook urls of the rules
+	webhooks bool
+
+	rules  map[int64]*rule
+	series map[seriesKey][]sample
+	mu     sync.Mutex
+
+	pipe chan *domain.Data
+	done chan struct{}
+
+	now func() time.Time
+}
+
+func New(l *log.Logger, store Store, notifiers ...Notifier) *Engine {
+	e := &Engine{
+		l:         l,
+		store:     store,
+		notifiers: notifiers,
+		rules:     make(map[int64]*rule),
+		series:    make(map[seriesKey][]sample),
+		pipe:      make(chan *domain.Data, 1000),
+		done:      make(chan struct{}),
+		now:       time.Now,
+	}
+
+	for _, n := range notifiers {
+		if _, ok := n.(*Webhook); ok {
+			e.webhooks = true
+		}
+	}
+
+	go e.serve()
+
+	return e
+}
+
+func (e *Engine) DataPipe() chan *domain.Data {
+	return e.pipe
+}
+
+// Close stop evaluating the rules, the data pipe must not be used afterward
+func (e *Engine) Close() {
+	close(e.pipe)
+	<-e.done
+}
+
+// Load the alert rules from the store, the rules with the webhook url are refused without the webhook to sign them
+func (e *Engine) Load(ctx context.Context) error {
+	alerts, err := e.store.Alerts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load alerts: %w", err)
+	}
+
+	for _, a := range alerts {
+		if err = e.checkWebhook(a); err != nil {
+			return fmt.Errorf("failed to load alert %d: %w", a.Id, err)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, a := range alerts {
+		e.rules[a.Id] = &rule{alert: a}
+	}
+
+	return nil
+}
+
+// Alerts return all alert rules ordered by id
+func (e *Engine) Alerts() []*domain.Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alerts := make([]*domain.Alert, 0, len(e.rules))
+	for _, id := range slices.Sorted(maps.Keys(e.rules)) {
+		a := *e.rules[id].alert
+		alerts = append(alerts, &a)
+	}
+
+	return alerts
+}
+
+// Add the alert rule, the id is assigned by the store
+func (e *Engine) Add(ctx context.Context, a *domain.Alert) (*domain.Alert, error) {
+	a = normalize(a)
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := e.checkWebhook(a); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidAlert, err)
+	}
+
+	id, err := e.store.AddAlert(ctx, a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add alert: %w", err)
+	}
+
+	a.Id = id
+
+	e.mu.Lock()
+	e.rules[id] = &rule{alert: a}
+	e.mu.Unlock()
+
+	res := *a
+
+	return &res, nil
+}
+
+// Update the alert rule, the cool-down of the already fired alert goes on
+func (e *Engine) Update(ctx context.Context, a *domain.Alert) (*domain.Alert, error) {
+	a = normalize(a)
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := e.checkWebhook(a); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidAlert, err)
+	}
+
+	result, err := e.store.UpdateAlert(ctx, a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update alert: %w", err)
+	}
+
+	if n, errRows := result.RowsAffected(); errRows == nil && n == 0 {
+		return nil, fmt.Errorf("%w: %d", domain.ErrAlertNotFound, a.Id)
+	}
+
+	e.mu.Lock()
+	if r, found := e.rules[a.Id]; found {
+		r.alert = a
+	} else {
+		e.rules[a.Id] = &rule{alert: a}
+	}
+	e.mu.Unlock()
+
+	res := *a
+
+	return &res, nil
+}
+
+// Remove the alert rule
+func (e *Engine) Remove(ctx context.Context, id int64) error {
+	result, err := e.store.RemoveAlert(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to remove alert: %w", err)
+	}
+
+	if n, errRows := result.RowsAffected(); errRows == nil && n == 0 {
+		return fmt.Errorf("%w: %d", domain.ErrAlertNotFound, id)
+	}
+
+	e.mu.Lock()
+	delete(e.rules, id)
+	e.mu.Unlock()
+
+	return nil
+}
+
+// checkWebhook of the rule, the webhook url is refused when the alerts are not posted to the webhooks
+func (e *Engine) checkWebhook(a *domain.Alert) error {
+	if a.WebhookUrl != "" && !e.webhooks {
+		return ErrNoWebhookSecret
+	}
+
+	return nil
+}
+
+func (e *Engine) serve() {
+	defer close(e.done)
+
+	for data := range e.pipe {
+		for _, event := range e.evaluate(data) {
+			for _, n := range e.notifiers {
+				n.Notify(event)
+			}
+		}
+	}
+}
+
+// evaluate the rules of the data pair and return the fired alerts, the prices are kept for the longest window of the
+// pair rules per data provider, so the windowed rules compare the prices of the same provider
+func (e *Engine) evaluate(data *domain.Data) []*domain.AlertEvent {
+	var (
+		now    = e.now()
+		p      = domain.Pair{From: strings.ToUpper(data.FromSymbol), To: strings.ToUpper(data.ToSymbol)}
+		key    = seriesKey{provider: data.Provider, pair: p}
+		rules  []*rule
+		window int64
+	)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range e.rules {
+		if r.alert.From != p.From || r.alert.To != p.To {
+			continue
+		}
+
+		rules = append(rules, r)
+
+		if r.alert.Rule.Windowed() {
+			window = max(window, r.alert.Window)
+		}
+	}
+
+	if window == 0 {
+		delete(e.series, key)
+	} else {
+		e.series[key] = trim(append(e.series[key], sample{at: now, price: data.Price, volume: data.Volume24Hour}),
+			now.Add(-time.Duration(window)*time.Second))
+	}
+
+	slices.SortFunc(rules, func(a, b *rule) int {
+		return cmp.Compare(a.alert.Id, b.alert.Id)
+	})
+
+	var events []*domain.AlertEvent
+
+	for _, r := range rules {
+		if !r.firedAt.IsZero() && now.Sub(r.firedAt) < time.Duration(r.alert.CoolDown)*time.Second {
+			continue
+		}
+
+		value, fired := check(r.alert, data, e.series[key], now)
+		if !fired {
+			continue
+		}
+
+		r.firedAt = now
+		a := *r.alert
+
+		events = append(events, &domain.AlertEvent{
+			Alert:     &a,
+			Value:     value,
+			Data:      data,
+			Timestamp: now.UTC().UnixMilli(),
+		})
+	}
+
+	return events
+}
+
+// check the rule and return the value it compared with the threshold
+func check(a *domain.Alert, data *domain.Data, samples []sample, now time.Time) (float64, bool) {
+	switch a.Rule {
+	case domain.AlertPriceAbove:
+		return data.Price, data.Price > a.Threshold
+	case domain.AlertPriceBelow:
+		return data.Price, data.Price < a.Threshold
+	case domain.AlertPercentMove:
+		base, ok := oldest(samples, now.Add(-time.Duration(a.Window)*time.Second))
+		if !ok || base.price == 0 {
+			return 0, false
+		}
+
+		change := (data.Price - base.price) / base.price * 100
+
+		return change, change >= a.Threshold || change <= -a.Threshold
+	case domain.AlertVolumeSpike:
+		base, ok := oldest(samples, now.Add(-time.Duration(a.Window)*time.Second))
+		if !ok || base.volume == 0 {
+			return 0, false
+		}
+
+		change := (data.Volume24Hour - base.volume) / base.volume * 100
+
+		return change, change >= a.Threshold
+	default:
+		return 0, false
+	}
+}
+
+// oldest sample within the window
+func oldest(samples []sample, since time.Time) (sample, bool) {
+	for _, s := range samples {
+		if !s.at.Before(since) {
+			return s, true
+		}
+	}
+
+	return sample{}, false
+}
+
+// trim the samples older than the window
+func trim(samples []sample, since time.Time) []sample {
+	i := 0
+	for i < len(samples) && samples[i].at.Before(since) {
+		i++
+	}
+
+	return samples[i:]
+}
+
+func normalize(a *domain.Alert) *domain.Alert {
+	res := *a
+	res.From = strings.ToUpper(res.From)
+	res.To = strings.ToUpper(res.To)
+
+	return &res
+}
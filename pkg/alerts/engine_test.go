@@ -0,0 +1,278 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/streamdp/ccd/db/memory"
+	"github.com/streamdp/ccd/domain"
+)
+
+type mockNotifier struct {
+	events chan *domain.AlertEvent
+}
+
+func (m *mockNotifier) Notify(e *domain.AlertEvent) {
+	m.events <- e
+}
+
+func newTestEngine(t *testing.T, notifiers ...Notifier) *Engine {
+	t.Helper()
+
+	d, err := memory.Connect("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = d.Close()
+	})
+
+	return New(log.New(io.Discard, "", 0), d, notifiers...)
+}
+
+func TestEngine_evaluate(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type tick struct {
+		after    time.Duration
+		provider string
+		price    float64
+		volume   float64
+	}
+
+	tests := []struct {
+		name  string
+		alert *domain.Alert
+		ticks []tick
+		want  []float64
+	}{
+		{
+			name:  "price above",
+			alert: &domain.Alert{From: "BTC", To: "USD", Rule: domain.AlertPriceAbove, Threshold: 100},
+			ticks: []tick{{price: 99}, {after: time.Second, price: 101}},
+			want:  []float64{101},
+		},
+		{
+			name:  "price below",
+			alert: &domain.Alert{From: "BTC", To: "USD", Rule: domain.AlertPriceBelow, Threshold: 100},
+			ticks: []tick{{price: 99}, {after: time.Second, price: 101}},
+			want:  []float64{99},
+		},
+		{
+			name: "cooldown",
+			alert: &domain.Alert{
+				From: "BTC", To: "USD", Rule: domain.AlertPriceAbove, Threshold: 100, CoolDown: 60,
+			},
+			ticks: []tick{
+				{price: 101},
+				{after: 30 * time.Second, price: 102},
+				{after: 60 * time.Second, price: 103},
+			},
+			want: []float64{101, 103},
+		},
+		{
+			name: "percent move down",
+			alert: &domain.Alert{
+				From: "BTC", To: "USD", Rule: domain.AlertPercentMove, Threshold: 10, Window: 60, CoolDown: 3600,
+			},
+			ticks: []tick{
+				{price: 100},
+				{after: 30 * time.Second, price: 95},
+				{after: 45 * time.Second, price: 90},
+				{after: 50 * time.Second, price: 80},
+			},
+			want: []float64{-10},
+		},
+		{
+			name: "percent move out of the window",
+			alert: &domain.Alert{
+				From: "BTC", To: "USD", Rule: domain.AlertPercentMove, Threshold: 10, Window: 60,
+			},
+			ticks: []tick{
+				{price: 100},
+				{after: 50 * time.Second, price: 105},
+				{after: 100 * time.Second, price: 110},
+			},
+		},
+		{
+			name: "volume spike",
+			alert: &domain.Alert{
+				From: "BTC", To: "USD", Rule: domain.AlertVolumeSpike, Threshold: 50, Window: 3600, CoolDown: 3600,
+			},
+			ticks: []tick{
+				{volume: 1000},
+				{after: time.Minute, volume: 1400},
+				{after: 2 * time.Minute, volume: 1500},
+			},
+			want: []float64{50},
+		},
+		{
+			name: "percent move of each data provider",
+			alert: &domain.Alert{
+				From: "BTC", To: "USD", Rule: domain.AlertPercentMove, Threshold: 10, Window: 60, CoolDown: 3600,
+			},
+			ticks: []tick{
+				{provider: "kraken", price: 100},
+				{after: 10 * time.Second, provider: "binance", price: 80},
+				{after: 20 * time.Second, provider: "kraken", price: 95},
+				{after: 30 * time.Second, provider: "binance", price: 90},
+			},
+			want: []float64{12.5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			defer e.Close()
+
+			now := start
+			e.now = func() time.Time { return now }
+
+			if _, err := e.Add(context.Background(), tt.alert); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []float64
+
+			for _, tk := range tt.ticks {
+				now = start.Add(tk.after)
+				for _, event := range e.evaluate(&domain.Data{
+					FromSymbol: "BTC", ToSymbol: "USD", Provider: tk.provider, Price: tk.price, Volume24Hour: tk.volume,
+				}) {
+					got = append(got, event.Value)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_serve(t *testing.T) {
+	n := &mockNotifier{events: make(chan *domain.AlertEvent, 1)}
+
+	e := newTestEngine(t, n)
+	defer e.Close()
+
+	a, err := e.Add(context.Background(), &domain.Alert{
+		From: "btc", To: "usd", Rule: domain.AlertPriceAbove, Threshold: 100, CoolDown: 300,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := &domain.Data{FromSymbol: "BTC", ToSymbol: "USD", Price: 101}
+	e.DataPipe() <- data
+
+	select {
+	case event := <-n.events:
+		if !reflect.DeepEqual(event.Alert, a) || event.Data != data || event.Value != 101 {
+			t.Errorf("Notify() event = %+v, want alert %+v", event, a)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("alert is not fired")
+	}
+}
+
+func TestEngine_crud(t *testing.T) {
+	ctx := context.Background()
+
+	e := newTestEngine(t)
+	defer e.Close()
+
+	if _, err := e.Add(ctx, &domain.Alert{From: "BTC", To: "USD", Rule: "price_equal", Threshold: 1}); !errors.Is(
+		err, domain.ErrInvalidAlert,
+	) {
+		t.Errorf("Add() error = %v, wantErr %v", err, domain.ErrInvalidAlert)
+	}
+
+	a, err := e.Add(ctx, &domain.Alert{From: "btc", To: "usd", Rule: domain.AlertPriceAbove, Threshold: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &domain.Alert{Id: a.Id, From: "BTC", To: "USD", Rule: domain.AlertPriceAbove, Threshold: 100}
+	if !reflect.DeepEqual(a, want) {
+		t.Errorf("Add() = %+v, want %+v", a, want)
+	}
+
+	want.Threshold = 200
+	if a, err = e.Update(ctx, want); err != nil || !reflect.DeepEqual(a, want) {
+		t.Errorf("Update() = %+v, %v, want %+v", a, err, want)
+	}
+
+	if _, err = e.Update(ctx, &domain.Alert{
+		Id: 100, From: "BTC", To: "USD", Rule: domain.AlertPriceAbove, Threshold: 1,
+	}); !errors.Is(err, domain.ErrAlertNotFound) {
+		t.Errorf("Update() error = %v, wantErr %v", err, domain.ErrAlertNotFound)
+	}
+
+	// the rules are loaded from the store by the new engine
+	loaded := New(log.New(io.Discard, "", 0), e.store)
+	defer loaded.Close()
+
+	// the rule added with the other instance sharing the store is updated before it is loaded
+	if _, err = loaded.Update(ctx, want); err != nil {
+		t.Errorf("Update() of the rule not loaded yet error = %v", err)
+	}
+
+	if err = loaded.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := loaded.Alerts(); !reflect.DeepEqual(got, []*domain.Alert{want}) {
+		t.Errorf("Alerts() = %+v, want %+v", got, []*domain.Alert{want})
+	}
+
+	if err = e.Remove(ctx, a.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = e.Remove(ctx, a.Id); !errors.Is(err, domain.ErrAlertNotFound) {
+		t.Errorf("Remove() error = %v, wantErr %v", err, domain.ErrAlertNotFound)
+	}
+
+	if got := e.Alerts(); len(got) != 0 {
+		t.Errorf("Alerts() = %+v, want empty", got)
+	}
+}
+
+func TestEngine_webhookSecret(t *testing.T) {
+	ctx := context.Background()
+
+	webhook, err := NewWebhook(log.New(io.Discard, "", 0), http.DefaultClient, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := newTestEngine(t, webhook)
+	defer e.Close()
+
+	a := &domain.Alert{
+		From: "BTC", To: "USD", Rule: domain.AlertPriceAbove, Threshold: 100, WebhookUrl: "https://example.com/hook",
+	}
+	if _, err = e.Add(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+
+	// the engine without the webhook refuses the rules with the webhook url
+	unsigned := New(log.New(io.Discard, "", 0), e.store)
+	defer unsigned.Close()
+
+	if _, err = unsigned.Add(ctx, a); !errors.Is(err, domain.ErrInvalidAlert) || !errors.Is(err, ErrNoWebhookSecret) {
+		t.Errorf("Add() error = %v, wantErr %v", err, ErrNoWebhookSecret)
+	}
+
+	if err = unsigned.Load(ctx); !errors.Is(err, ErrNoWebhookSecret) {
+		t.Errorf("Load() error = %v, wantErr %v", err, ErrNoWebhookSecret)
+	}
+}
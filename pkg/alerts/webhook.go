@@ -0,0 +1,129 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+const (
+	// SignatureHeader holds the hex encoded HMAC-SHA256 of the request body signed with the alerts secret
+	SignatureHeader = "X-Ccd-Signature"
+
+	webhookQueueSize = 100
+)
+
+var (
+	ErrNoWebhookSecret = errors.New("alert webhooks need the secret to be signed with")
+	errWebhookStatus   = errors.New("unexpected webhook response status")
+)
+
+// Webhook posts the fired alerts to their webhook urls one by one in the background, the alerts are dropped when
+// the queue is full
+type Webhook struct {
+	l      *log.Logger
+	client *http.Client
+	secret []byte
+
+	queue chan *domain.AlertEvent
+	done  chan struct{}
+}
+
+// NewWebhook of the secret every posted alert is signed with, the secret is required
+func NewWebhook(l *log.Logger, client *http.Client, secret string) (*Webhook, error) {
+	if secret == "" {
+		return nil, ErrNoWebhookSecret
+	}
+
+	w := &Webhook{
+		l:      l,
+		client: client,
+		secret: []byte(secret),
+		queue:  make(chan *domain.AlertEvent, webhookQueueSize),
+		done:   make(chan struct{}),
+	}
+
+	go w.serve()
+
+	return w, nil
+}
+
+// Notify queue the alert to be posted to its webhook url, alerts without the url are skipped
+func (w *Webhook) Notify(e *domain.AlertEvent) {
+	if e.Alert.WebhookUrl == "" {
+		return
+	}
+
+	select {
+	case w.queue <- e:
+	default:
+		w.l.Printf("alerts webhook queue is full, alert %d is dropped", e.Alert.Id)
+	}
+}
+
+// Close stop accepting alerts and wait for the queued ones to be posted until ctx is done
+func (w *Webhook) Close(ctx context.Context) error {
+	close(w.queue)
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d alerts are not posted: %w", len(w.queue), ctx.Err())
+	}
+}
+
+func (w *Webhook) serve() {
+	defer close(w.done)
+
+	for e := range w.queue {
+		if err := w.post(context.Background(), e); err != nil {
+			w.l.Printf("failed to post alert %d: %v", e.Alert.Id, err)
+		}
+	}
+}
+
+func (w *Webhook) post(ctx context.Context, e *domain.AlertEvent) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Alert.WebhookUrl, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(w.secret, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s", errWebhookStatus, resp.Status)
+	}
+
+	return nil
+}
+
+// Sign the body with the secret, the signature is "sha256=" followed by the hex encoded HMAC-SHA256
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
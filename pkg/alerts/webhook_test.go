@@ -0,0 +1,106 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+func TestWebhook_Notify(t *testing.T) {
+	const secret = "secret"
+
+	requests := make(chan *domain.AlertEvent, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if got := r.Header.Get(SignatureHeader); got != Sign([]byte(secret), body) {
+			t.Errorf("%s = %q, want %q", SignatureHeader, got, Sign([]byte(secret), body))
+		}
+
+		event := &domain.AlertEvent{}
+		if err = json.Unmarshal(body, event); err != nil {
+			t.Error(err)
+		}
+
+		requests <- event
+	}))
+	defer srv.Close()
+
+	if _, err := NewWebhook(log.New(io.Discard, "", 0), srv.Client(), ""); !errors.Is(err, ErrNoWebhookSecret) {
+		t.Errorf("NewWebhook() error = %v, wantErr %v", err, ErrNoWebhookSecret)
+	}
+
+	w, err := NewWebhook(log.New(io.Discard, "", 0), srv.Client(), secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := &domain.AlertEvent{
+		Alert: &domain.Alert{
+			Id: 1, From: "BTC", To: "USD", Rule: domain.AlertPriceAbove, Threshold: 100, WebhookUrl: srv.URL,
+		},
+		Value:     101,
+		Data:      &domain.Data{FromSymbol: "BTC", ToSymbol: "USD", Price: 101},
+		Timestamp: 1767225600000,
+	}
+
+	// the alert without the webhook url is skipped
+	w.Notify(&domain.AlertEvent{Alert: &domain.Alert{Id: 2}})
+	w.Notify(event)
+
+	select {
+	case got := <-requests:
+		if !reflect.DeepEqual(got, event) {
+			t.Errorf("posted alert = %+v, want %+v", got, event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("alert is not posted")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := w.Close(ctx); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	if len(requests) != 0 {
+		t.Errorf("unexpected alert is posted")
+	}
+}
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		body   string
+		want   string
+	}{
+		{
+			name:   "rfc 4231 test case 2",
+			secret: "Jefe",
+			body:   "what do ya want for nothing?",
+			want:   "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign([]byte(tt.secret), []byte(tt.body)); got != tt.want {
+				t.Errorf("Sign() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+const (
+	keyPrefix  = "ccd_"
+	keyBytes   = 32
+	prefixSize = len(keyPrefix) + 8
+
+	bootstrapName = "admin"
+
+	// RefreshInterval the keys are reloaded from the store with
+	RefreshInterval = 30 * time.Second
+)
+
+// Store of the api keys
+type Store interface {
+	ApiKeys(ctx context.Context) (keys []*domain.ApiKey, err error)
+	AddApiKey(ctx context.Context, k *domain.ApiKey) (id int64, err error)
+	UpdateApiKey(ctx context.Context, k *domain.ApiKey) (result sql.Result, err error)
+	RemoveApiKey(ctx context.Context, id int64) (result sql.Result, err error)
+}
+
+// Keys authorizes the requests with the api keys, the keys are kept in memory by their hashes and reloaded from the
+// store by Refresh, so the key changed with the other instance sharing the store is seen within the refresh interval
+type Keys struct {
+	store Store
+	now   func() time.Time
+
+	byHash map[string]*domain.ApiKey
+	byId   map[int64]*domain.ApiKey
+	// version is changed with every key changed with this instance
+	version uint64
+	mu      sync.RWMutex
+}
+
+func New(store Store) *Keys {
+	return &Keys{
+		store:  store,
+		now:    time.Now,
+		byHash: make(map[string]*domain.ApiKey),
+		byId:   make(map[int64]*domain.ApiKey),
+	}
+}
+
+// Load the api keys from the store, they replace the loaded ones
+func (k *Keys) Load(ctx context.Context) error {
+	k.mu.RLock()
+	version := k.version
+	k.mu.RUnlock()
+
+	keys, err := k.store.ApiKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load api keys: %w", err)
+	}
+
+	byHash := make(map[string]*domain.ApiKey, len(keys))
+	byId := make(map[int64]*domain.ApiKey, len(keys))
+
+	for _, key := range keys {
+		byHash[key.Hash] = key
+		byId[key.Id] = key
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	// the keys changed meanwhile may be missed by the loaded ones, they are loaded again on the next refresh
+	if k.version != version {
+		return nil
+	}
+
+	k.byHash, k.byId = byHash, byId
+
+	return nil
+}
+
+// Refresh reload the api keys from the store every interval until the context is done, so the keys created, rotated
+// and revoked with the other instances sharing the store are seen
+func (k *Keys) Refresh(ctx context.Context, l *log.Logger, interval time.Duration) {
+	t := time.NewTimer(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			t.Reset(interval)
+
+			if err := k.Load(ctx); err != nil && ctx.Err() == nil {
+				l.Printf("failed to refresh api keys: %v", err)
+			}
+		}
+	}
+}
+
+// ApiKeys return all api keys ordered by id
+func (k *Keys) ApiKeys() []*domain.ApiKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := make([]*domain.ApiKey, 0, len(k.byId))
+	for _, id := range slices.Sorted(maps.Keys(k.byId)) {
+		keys = append(keys, copyKey(k.byId[id]))
+	}
+
+	return keys
+}
+
+// Create the api key with the name and the scopes of the given one, the key is returned once, only its hash is kept
+func (k *Keys) Create(ctx context.Context, key *domain.ApiKey) (*domain.ApiKey, string, error) {
+	if err := key.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	secret, err := generate()
+	if err != nil {
+		return nil, "", err
+	}
+
+	res := &domain.ApiKey{
+		Name:    key.Name,
+		Prefix:  secret[:prefixSize],
+		Scopes:  slices.Clone(key.Scopes),
+		Created: k.now().Unix(),
+		Hash:    hash(secret),
+	}
+
+	if res.Id, err = k.store.AddApiKey(ctx, res); err != nil {
+		return nil, "", fmt.Errorf("failed to add api key: %w", err)
+	}
+
+	k.mu.Lock()
+	k.byHash[res.Hash] = res
+	k.byId[res.Id] = res
+	k.version++
+	k.mu.Unlock()
+
+	return copyKey(res), secret, nil
+}
+
+// Rotate the api key, the new key is returned once and the previous one stops working at once
+func (k *Keys) Rotate(ctx context.Context, id int64) (*domain.ApiKey, string, error) {
+	key, err := k.lookup(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := generate()
+	if err != nil {
+		return nil, "", err
+	}
+
+	res := copyKey(key)
+	res.Prefix = secret[:prefixSize]
+	res.Hash = hash(secret)
+	res.Created = k.now().Unix()
+
+	result, err := k.store.UpdateApiKey(ctx, res)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to update api key: %w", err)
+	}
+
+	if n, errRows := result.RowsAffected(); errRows == nil && n == 0 {
+		return nil, "", fmt.Errorf("%w: %d", domain.ErrApiKeyNotFound, id)
+	}
+
+	k.mu.Lock()
+	delete(k.byHash, key.Hash)
+	k.byHash[res.Hash] = res
+	k.byId[res.Id] = res
+	k.version++
+	k.mu.Unlock()
+
+	return copyKey(res), secret, nil
+}
+
+// lookup the api key by id, the keys are loaded again when it is missing, e.g. it is created with the other instance
+// sharing the store since the last refresh
+func (k *Keys) lookup(ctx context.Context, id int64) (*domain.ApiKey, error) {
+	k.mu.RLock()
+	key, ok := k.byId[id]
+	k.mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if err := k.Load(ctx); err != nil {
+		return nil, err
+	}
+
+	k.mu.RLock()
+	key, ok = k.byId[id]
+	k.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", domain.ErrApiKeyNotFound, id)
+	}
+
+	return key, nil
+}
+
+// Revoke the api key, the requests with it are refused at once
+func (k *Keys) Revoke(ctx context.Context, id int64) error {
+	result, err := k.store.RemoveApiKey(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to remove api key: %w", err)
+	}
+
+	if n, errRows := result.RowsAffected(); errRows == nil && n == 0 {
+		return fmt.Errorf("%w: %d", domain.ErrApiKeyNotFound, id)
+	}
+
+	k.mu.Lock()
+	if key, found := k.byId[id]; found {
+		delete(k.byHash, key.Hash)
+		delete(k.byId, id)
+	}
+	k.version++
+	k.mu.Unlock()
+
+	return nil
+}
+
+// Bootstrap create the admin key when there are no keys at all, so the other keys can be created with the api, the
+// key is returned once, it is empty when the keys exist
+func (k *Keys) Bootstrap(ctx context.Context) (string, error) {
+	k.mu.RLock()
+	n := len(k.byId)
+	k.mu.RUnlock()
+
+	if n != 0 {
+		return "", nil
+	}
+
+	_, secret, err := k.Create(ctx, &domain.ApiKey{Name: bootstrapName, Scopes: []string{domain.ScopeAdmin}})
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// Authorize the request sent with the key to the endpoint requiring the scope
+func (k *Keys) Authorize(key, scope string) error {
+	if key == "" {
+		return domain.ErrUnauthorized
+	}
+
+	k.mu.RLock()
+	found, ok := k.byHash[hash(key)]
+	k.mu.RUnlock()
+
+	if !ok {
+		return domain.ErrUnauthorized
+	}
+
+	if !found.Allows(scope) {
+		return fmt.Errorf("%w %q", domain.ErrForbidden, scope)
+	}
+
+	return nil
+}
+
+// hash of the api key, the keys are random, so the plain sha256 is enough to keep them
+func hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func generate() (string, error) {
+	b := make([]byte, keyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	return keyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func copyKey(k *domain.ApiKey) *domain.ApiKey {
+	key := *k
+	key.Scopes = slices.Clone(k.Scopes)
+
+	return &key
+}
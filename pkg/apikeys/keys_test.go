@@ -0,0 +1,226 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/streamdp/ccd/db/memory"
+	"github.com/streamdp/ccd/domain"
+)
+
+func newTestKeys(t *testing.T) *Keys {
+	t.Helper()
+
+	store, err := memory.Connect("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+
+	k := New(store)
+	k.now = func() time.Time {
+		return time.Unix(1700000000, 0)
+	}
+
+	return k
+}
+
+func TestKeys_Authorize(t *testing.T) {
+	ctx := context.Background()
+	k := newTestKeys(t)
+
+	_, reader, err := k.Create(ctx, &domain.ApiKey{Name: "dashboard", Scopes: []string{domain.ScopeRead, domain.ScopeWs}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, admin, err := k.Create(ctx, &domain.ApiKey{Name: "ops", Scopes: []string{domain.ScopeAdmin}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		scope   string
+		wantErr error
+	}{
+		{
+			name:  "granted scope",
+			key:   reader,
+			scope: domain.ScopeWs,
+		},
+		{
+			name:    "missing scope",
+			key:     reader,
+			scope:   domain.ScopeCollectWrite,
+			wantErr: domain.ErrForbidden,
+		},
+		{
+			name:  "admin key",
+			key:   admin,
+			scope: domain.ScopeSymbolsWrite,
+		},
+		{
+			name:    "no key",
+			key:     "",
+			scope:   domain.ScopeRead,
+			wantErr: domain.ErrUnauthorized,
+		},
+		{
+			name:    "unknown key",
+			key:     reader + "x",
+			scope:   domain.ScopeRead,
+			wantErr: domain.ErrUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := k.Authorize(tt.key, tt.scope); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeys_crud(t *testing.T) {
+	ctx := context.Background()
+	k := newTestKeys(t)
+
+	if _, _, err := k.Create(ctx, &domain.ApiKey{Name: "dashboard"}); !errors.Is(err, domain.ErrInvalidApiKey) {
+		t.Errorf("Create() error = %v, wantErr %v", err, domain.ErrInvalidApiKey)
+	}
+
+	key, secret, err := k.Create(ctx, &domain.ApiKey{Name: "dashboard", Scopes: []string{domain.ScopeRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(secret, key.Prefix) || key.Hash == secret {
+		t.Errorf("Create() = %+v, %q, want the prefix of the key and its hash", key, secret)
+	}
+
+	rotated, rotatedSecret, err := k.Rotate(ctx, key.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = k.Authorize(secret, domain.ScopeRead); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("Authorize() with the rotated key error = %v, wantErr %v", err, domain.ErrUnauthorized)
+	}
+
+	if err = k.Authorize(rotatedSecret, domain.ScopeRead); err != nil {
+		t.Errorf("Authorize() with the new key error = %v", err)
+	}
+
+	if _, _, err = k.Rotate(ctx, 100); !errors.Is(err, domain.ErrApiKeyNotFound) {
+		t.Errorf("Rotate() error = %v, wantErr %v", err, domain.ErrApiKeyNotFound)
+	}
+
+	// the keys are loaded from the store by the new keys
+	loaded := New(k.store)
+	if err = loaded.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := loaded.ApiKeys(); !reflect.DeepEqual(got, []*domain.ApiKey{rotated}) {
+		t.Errorf("ApiKeys() = %+v, want %+v", got, []*domain.ApiKey{rotated})
+	}
+
+	if err = loaded.Authorize(rotatedSecret, domain.ScopeRead); err != nil {
+		t.Errorf("Authorize() with the loaded key error = %v", err)
+	}
+
+	if err = k.Revoke(ctx, key.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = k.Revoke(ctx, key.Id); !errors.Is(err, domain.ErrApiKeyNotFound) {
+		t.Errorf("Revoke() error = %v, wantErr %v", err, domain.ErrApiKeyNotFound)
+	}
+
+	if err = k.Authorize(rotatedSecret, domain.ScopeRead); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("Authorize() with the revoked key error = %v, wantErr %v", err, domain.ErrUnauthorized)
+	}
+
+	if got := k.ApiKeys(); len(got) != 0 {
+		t.Errorf("ApiKeys() = %+v, want empty", got)
+	}
+}
+
+func TestKeys_Bootstrap(t *testing.T) {
+	ctx := context.Background()
+	k := newTestKeys(t)
+
+	secret, err := k.Bootstrap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = k.Authorize(secret, domain.ScopeAdmin); err != nil {
+		t.Errorf("Authorize() with the bootstrap key error = %v", err)
+	}
+
+	// the admin key is created once, when there are no keys at all
+	if secret, err = k.Bootstrap(ctx); err != nil || secret != "" {
+		t.Errorf("Bootstrap() = %q, %v, want empty", secret, err)
+	}
+
+	if got := k.ApiKeys(); len(got) != 1 || got[0].Name != bootstrapName {
+		t.Errorf("ApiKeys() = %+v, want the %s key only", got, bootstrapName)
+	}
+}
+
+func TestKeys_Load(t *testing.T) {
+	ctx := context.Background()
+	k := newTestKeys(t)
+
+	// the other instance sharing the store
+	other := New(k.store)
+
+	key, secret, err := k.Create(ctx, &domain.ApiKey{Name: "dashboard", Scopes: []string{domain.ScopeRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = other.Authorize(secret, domain.ScopeRead); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("Authorize() before the load error = %v, wantErr %v", err, domain.ErrUnauthorized)
+	}
+
+	if err = other.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = other.Authorize(secret, domain.ScopeRead); err != nil {
+		t.Errorf("Authorize() with the loaded key error = %v", err)
+	}
+
+	// the key created since the last load is loaded on the rotation
+	created, _, err := k.Create(ctx, &domain.ApiKey{Name: "ci", Scopes: []string{domain.ScopeRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rotated, _, errRotate := other.Rotate(ctx, created.Id); errRotate != nil || rotated.Name != "ci" {
+		t.Errorf("Rotate() of the key not loaded yet = %+v, %v", rotated, errRotate)
+	}
+
+	if err = k.Revoke(ctx, key.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = other.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = other.Authorize(secret, domain.ScopeRead); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("Authorize() with the revoked key error = %v, wantErr %v", err, domain.ErrUnauthorized)
+	}
+}
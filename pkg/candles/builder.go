@@ -0,0 +1,118 @@
+package candles
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+// Bar is the candle of the data provider, currencies pair and resolution built from the streamed ticks
+type Bar struct {
+	Provider   string
+	From       string
+	To         string
+	Resolution domain.Resolution
+	Candle     domain.Candle
+	Closed     bool
+}
+
+// Builder keeps the current bar of every data provider, pair and resolution in memory along with the time of the last
+// closed one, so the late ticks don't open the closed bar again
+type Builder struct {
+	bars   map[string]*Bar
+	closed map[string]int64
+	mu     sync.Mutex
+}
+
+func NewBuilder() *Builder {
+	return &Builder{
+		bars:   make(map[string]*Bar),
+		closed: make(map[string]int64),
+	}
+}
+
+// Add the tick to the current bars of every resolution, it returns the bars closed by the tick followed by the
+// updated ones, the ticks of the closed bars are dropped
+func (b *Builder) Add(d *domain.Data) []Bar {
+	if d == nil {
+		return nil
+	}
+
+	var res []Bar
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, r := range domain.Resolutions() {
+		key := buildBarName(d.Provider, d.FromSymbol, d.ToSymbol, r)
+		bucket := r.Bucket(d.LastUpdate)
+
+		if closed, found := b.closed[key]; found && bucket <= closed {
+			continue
+		}
+
+		bar, ok := b.bars[key]
+		if ok && bucket < bar.Candle.Time {
+			continue
+		}
+
+		if ok && bucket > bar.Candle.Time {
+			bar.Closed = true
+			res = append(res, *bar)
+			b.closed[key] = bar.Candle.Time
+			ok = false
+		}
+
+		if !ok {
+			bar = &Bar{
+				Provider:   d.Provider,
+				From:       d.FromSymbol,
+				To:         d.ToSymbol,
+				Resolution: r,
+				Candle: domain.Candle{
+					Time: bucket,
+					Open: d.Price,
+					High: d.Price,
+					Low:  d.Price,
+				},
+			}
+			b.bars[key] = bar
+		}
+
+		bar.Candle.High = max(bar.Candle.High, d.Price)
+		bar.Candle.Low = min(bar.Candle.Low, d.Price)
+		bar.Candle.Close = d.Price
+		bar.Candle.Volume24Hour = d.Volume24Hour
+
+		res = append(res, *bar)
+	}
+
+	return res
+}
+
+// CloseExpired remove and return bars whose time is over, now is in milliseconds
+func (b *Builder) CloseExpired(now int64) []Bar {
+	var res []Bar
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for key, bar := range b.bars {
+		if now < bar.Candle.Time+bar.Resolution.Millis() {
+			continue
+		}
+
+		bar.Closed = true
+		res = append(res, *bar)
+
+		b.closed[key] = bar.Candle.Time
+		delete(b.bars, key)
+	}
+
+	return res
+}
+
+func buildBarName(provider, from, to string, r domain.Resolution) string {
+	return fmt.Sprintf("%s:%s:%s:%s", provider, from, to, r)
+}
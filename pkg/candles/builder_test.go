@@ -0,0 +1,140 @@
+package candles
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+func TestBuilder_Add(t *testing.T) {
+	tests := []struct {
+		name  string
+		ticks []*domain.Data
+		want  []Bar
+	}{
+		{
+			name:  "nil tick",
+			ticks: []*domain.Data{nil},
+			want:  nil,
+		},
+		{
+			name: "update current bar",
+			ticks: []*domain.Data{
+				{FromSymbol: "BTC", ToSymbol: "USDT", Price: 10, Volume24Hour: 100, LastUpdate: 1747641600000},
+				{FromSymbol: "BTC", ToSymbol: "USDT", Price: 12, Volume24Hour: 101, LastUpdate: 1747641610000},
+			},
+			want: []Bar{
+				{From: "BTC", To: "USDT", Resolution: "1m", Candle: domain.Candle{
+					Time: 1747641600000, Open: 10, High: 12, Low: 10, Close: 12, Volume24Hour: 101,
+				}},
+				{From: "BTC", To: "USDT", Resolution: "5m", Candle: domain.Candle{
+					Time: 1747641600000, Open: 10, High: 12, Low: 10, Close: 12, Volume24Hour: 101,
+				}},
+				{From: "BTC", To: "USDT", Resolution: "1h", Candle: domain.Candle{
+					Time: 1747641600000, Open: 10, High: 12, Low: 10, Close: 12, Volume24Hour: 101,
+				}},
+				{From: "BTC", To: "USDT", Resolution: "1d", Candle: domain.Candle{
+					Time: 1747612800000, Open: 10, High: 12, Low: 10, Close: 12, Volume24Hour: 101,
+				}},
+			},
+		},
+		{
+			name: "close minute bar",
+			ticks: []*domain.Data{
+				{FromSymbol: "BTC", ToSymbol: "USDT", Price: 10, Volume24Hour: 100, LastUpdate: 1747641600000},
+				{FromSymbol: "BTC", ToSymbol: "USDT", Price: 8, Volume24Hour: 101, LastUpdate: 1747641660000},
+			},
+			want: []Bar{
+				{From: "BTC", To: "USDT", Resolution: "1m", Closed: true, Candle: domain.Candle{
+					Time: 1747641600000, Open: 10, High: 10, Low: 10, Close: 10, Volume24Hour: 100,
+				}},
+				{From: "BTC", To: "USDT", Resolution: "1m", Candle: domain.Candle{
+					Time: 1747641660000, Open: 8, High: 8, Low: 8, Close: 8, Volume24Hour: 101,
+				}},
+				{From: "BTC", To: "USDT", Resolution: "5m", Candle: domain.Candle{
+					Time: 1747641600000, Open: 10, High: 10, Low: 8, Close: 8, Volume24Hour: 101,
+				}},
+				{From: "BTC", To: "USDT", Resolution: "1h", Candle: domain.Candle{
+					Time: 1747641600000, Open: 10, High: 10, Low: 8, Close: 8, Volume24Hour: 101,
+				}},
+				{From: "BTC", To: "USDT", Resolution: "1d", Candle: domain.Candle{
+					Time: 1747612800000, Open: 10, High: 10, Low: 8, Close: 8, Volume24Hour: 101,
+				}},
+			},
+		},
+		{
+			name: "bars of the providers are kept apart",
+			ticks: []*domain.Data{
+				{Provider: "kraken", FromSymbol: "BTC", ToSymbol: "USDT", Price: 10, LastUpdate: 1747641600000},
+				{Provider: "consolidated", FromSymbol: "BTC", ToSymbol: "USDT", Price: 20, LastUpdate: 1747641610000},
+			},
+			want: []Bar{
+				{Provider: "consolidated", From: "BTC", To: "USDT", Resolution: "1m", Candle: domain.Candle{
+					Time: 1747641600000, Open: 20, High: 20, Low: 20, Close: 20,
+				}},
+				{Provider: "consolidated", From: "BTC", To: "USDT", Resolution: "5m", Candle: domain.Candle{
+					Time: 1747641600000, Open: 20, High: 20, Low: 20, Close: 20,
+				}},
+				{Provider: "consolidated", From: "BTC", To: "USDT", Resolution: "1h", Candle: domain.Candle{
+					Time: 1747641600000, Open: 20, High: 20, Low: 20, Close: 20,
+				}},
+				{Provider: "consolidated", From: "BTC", To: "USDT", Resolution: "1d", Candle: domain.Candle{
+					Time: 1747612800000, Open: 20, High: 20, Low: 20, Close: 20,
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				b   = NewBuilder()
+				got []Bar
+			)
+
+			for i := range tt.ticks {
+				got = b.Add(tt.ticks[i])
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Add() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_CloseExpired(t *testing.T) {
+	b := NewBuilder()
+	b.Add(&domain.Data{FromSymbol: "BTC", ToSymbol: "USDT", Price: 10, LastUpdate: 1747641600000})
+
+	if got := b.CloseExpired(1747641659999); len(got) != 0 {
+		t.Errorf("CloseExpired() = %v, want no bars", got)
+	}
+
+	want := []Bar{{From: "BTC", To: "USDT", Resolution: "1m", Closed: true, Candle: domain.Candle{
+		Time: 1747641600000, Open: 10, High: 10, Low: 10, Close: 10,
+	}}}
+	if got := b.CloseExpired(1747641660000); !reflect.DeepEqual(got, want) {
+		t.Errorf("CloseExpired() = %v, want %v", got, want)
+	}
+
+	if len(b.bars) != len(domain.Resolutions())-1 {
+		t.Errorf("len(bars) = %d, want %d", len(b.bars), len(domain.Resolutions())-1)
+	}
+
+	// the late tick of the closed bar is dropped, the tick of the next bar opens it
+	for _, bar := range b.Add(&domain.Data{FromSymbol: "BTC", ToSymbol: "USDT", Price: 9, LastUpdate: 1747641630000}) {
+		if bar.Resolution == "1m" {
+			t.Errorf("Add() of the late tick = %v, want the closed 1m bar kept closed", bar)
+		}
+	}
+
+	want = []Bar{{From: "BTC", To: "USDT", Resolution: "1m", Candle: domain.Candle{
+		Time: 1747641660000, Open: 11, High: 11, Low: 11, Close: 11,
+	}}}
+	if got := b.Add(&domain.Data{
+		FromSymbol: "BTC", ToSymbol: "USDT", Price: 11, LastUpdate: 1747641661000,
+	}); !reflect.DeepEqual(got[:1], want) {
+		t.Errorf("Add() = %v, want %v first", got, want)
+	}
+}
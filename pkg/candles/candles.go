@@ -0,0 +1,37 @@
+package candles
+
+import (
+	"github.com/streamdp/ccd/domain"
+)
+
+// Aggregate rows ordered by the lastupdate into candles of the selected resolution
+func Aggregate(rows []*domain.Data, r domain.Resolution) []*domain.Candle {
+	var (
+		res []*domain.Candle
+		c   *domain.Candle
+	)
+
+	for _, d := range rows {
+		if d == nil {
+			continue
+		}
+
+		bucket := r.Bucket(d.LastUpdate)
+		if c == nil || c.Time != bucket {
+			c = &domain.Candle{
+				Time: bucket,
+				Open: d.Price,
+				High: d.Price,
+				Low:  d.Price,
+			}
+			res = append(res, c)
+		}
+
+		c.High = max(c.High, d.Price)
+		c.Low = min(c.Low, d.Price)
+		c.Close = d.Price
+		c.Volume24Hour = d.Volume24Hour
+	}
+
+	return res
+}
@@ -0,0 +1,72 @@
+package candles
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []*domain.Data
+		r    domain.Resolution
+		want []*domain.Candle
+	}{
+		{
+			name: "no rows",
+			rows: nil,
+			r:    "1m",
+			want: nil,
+		},
+		{
+			name: "single bar",
+			rows: []*domain.Data{
+				{Price: 10, Volume24Hour: 100, LastUpdate: 1747644000000},
+				{Price: 12, Volume24Hour: 101, LastUpdate: 1747644010000},
+				{Price: 9, Volume24Hour: 102, LastUpdate: 1747644020000},
+				{Price: 11, Volume24Hour: 103, LastUpdate: 1747644059999},
+			},
+			r: "1m",
+			want: []*domain.Candle{
+				{Time: 1747644000000, Open: 10, High: 12, Low: 9, Close: 11, Volume24Hour: 103},
+			},
+		},
+		{
+			name: "several bars",
+			rows: []*domain.Data{
+				{Price: 10, Volume24Hour: 100, LastUpdate: 1747644000000},
+				{Price: 12, Volume24Hour: 101, LastUpdate: 1747644030000},
+				{Price: 9, Volume24Hour: 102, LastUpdate: 1747644060000},
+				{Price: 11, Volume24Hour: 103, LastUpdate: 1747644200000},
+			},
+			r: "1m",
+			want: []*domain.Candle{
+				{Time: 1747644000000, Open: 10, High: 12, Low: 10, Close: 12, Volume24Hour: 101},
+				{Time: 1747644060000, Open: 9, High: 9, Low: 9, Close: 9, Volume24Hour: 102},
+				{Time: 1747644180000, Open: 11, High: 11, Low: 11, Close: 11, Volume24Hour: 103},
+			},
+		},
+		{
+			name: "timestamps in seconds",
+			rows: []*domain.Data{
+				{Price: 10, Volume24Hour: 100, LastUpdate: 1747641600},
+				{Price: 12, Volume24Hour: 101, LastUpdate: 1747643000},
+				{Price: 8, Volume24Hour: 102, LastUpdate: 1747645200},
+			},
+			r: "1h",
+			want: []*domain.Candle{
+				{Time: 1747641600000, Open: 10, High: 12, Low: 10, Close: 12, Volume24Hour: 101},
+				{Time: 1747645200000, Open: 8, High: 8, Low: 8, Close: 8, Volume24Hour: 102},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Aggregate(tt.rows, tt.r); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Aggregate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace of the ccd metrics
+const Namespace = "ccd"
+
+var (
+	// ProviderRequestDuration of the rest client calls by the data provider and the method
+	ProviderRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "provider_request_duration_seconds",
+		Help:      "Duration of the data provider rest client calls.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider", "method"})
+
+	// ProviderRequestErrors of the rest client calls by the data provider and the method
+	ProviderRequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "provider_request_errors_total",
+		Help:      "Number of the failed data provider rest client calls.",
+	}, []string{"provider", "method"})
+
+	// WsReconnects of the data provider ws clients by the data provider and the result
+	WsReconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "ws_reconnects_total",
+		Help:      "Number of the data provider ws client reconnect attempts.",
+	}, []string{"provider", "result"})
+
+	// DbInsertDuration of the rows saved from the data pipe
+	DbInsertDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "db_insert_duration_seconds",
+		Help:      "Duration of the database inserts of the collected data.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// DbInsertErrors of the rows saved from the data pipe
+	DbInsertErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "db_insert_errors_total",
+		Help:      "Number of the failed database inserts of the collected data.",
+	})
+
+	// DbBatchSize of the batches saved from the data pipe
+	DbBatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "db_batch_size",
+		Help:      "Number of the rows in the database batches of the collected data.",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 7),
+	})
+
+	// DbBatchDuration of the batches saved from the data pipe
+	DbBatchDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "db_batch_duration_seconds",
+		Help:      "Duration of the database batches of the collected data.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// DbBatchErrors of the rows which failed to be saved with the batch
+	DbBatchErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "db_batch_errors_total",
+		Help:      "Number of the collected data rows failed to be saved with the database batches.",
+	})
+)
+
+// WsServer is the source of the connected ws clients and their subscriptions numbers
+type WsServer interface {
+	ClientsCount() int
+	SubscriptionsCount() int
+}
+
+// Handler serve metrics in the prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObservePipe report the number of items waiting in the pipe with the selected name
+func ObservePipe[T any](name string, pipe chan T) {
+	register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   Namespace,
+		Name:        "pipe_depth",
+		Help:        "Number of the items waiting in the data pipe.",
+		ConstLabels: prometheus.Labels{"pipe": name},
+	}, func() float64 {
+		return float64(len(pipe))
+	}))
+}
+
+// ObserveWsServer report the number of connected ws clients and their subscriptions
+func ObserveWsServer(s WsServer) {
+	register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "ws_clients",
+		Help:      "Number of the connected ws clients.",
+	}, func() float64 {
+		return float64(s.ClientsCount())
+	}))
+
+	register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "ws_subscriptions",
+		Help:      "Number of the subscriptions of the connected ws clients.",
+	}, func() float64 {
+		return float64(s.SubscriptionsCount())
+	}))
+}
+
+// register the collector replacing the one registered before with the same name and labels, e.g. the gauge of the
+// recreated pipe
+func register(c prometheus.Collector) {
+	err := prometheus.Register(c)
+
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		prometheus.Unregister(are.ExistingCollector)
+		err = prometheus.Register(c)
+	}
+
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type wsServer struct {
+	clients int
+}
+
+func (s *wsServer) ClientsCount() int {
+	return s.clients
+}
+
+func (s *wsServer) SubscriptionsCount() int {
+	return 0
+}
+
+func TestObserve_again(t *testing.T) {
+	ObservePipe("test", make(chan int, 1))
+
+	pipe := make(chan int, 1)
+	pipe <- 1
+	ObservePipe("test", pipe)
+
+	ObserveWsServer(&wsServer{clients: 1})
+	ObserveWsServer(&wsServer{clients: 2})
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			switch {
+			case f.GetName() == "ccd_pipe_depth" && m.GetLabel()[0].GetValue() == "test":
+				if got := m.GetGauge().GetValue(); got != 1 {
+					t.Errorf("pipe_depth = %v, want the depth of the last observed pipe 1", got)
+				}
+			case f.GetName() == "ccd_ws_clients":
+				if got := m.GetGauge().GetValue(); got != 2 {
+					t.Errorf("ws_clients = %v, want the clients of the last observed server 2", got)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const versionTable = `create table if not exists schema_migrations
+(
+    version    bigint       not null primary key,
+    name       varchar(255) not null,
+    applied_at bigint       not null
+);`
+
+var (
+	ErrNoMigrations      = errors.New("no migrations found")
+	ErrNothingToRollBack = errors.New("no applied migrations to roll back")
+	ErrIrreversible      = errors.New("migration has no down script and can't be rolled back")
+
+	errMigrationName = errors.New("migration file name must look like 0001_name.up.sql or 0001_name.down.sql")
+	errNoUpMigration = errors.New("migration has no up script")
+)
+
+// Migration of the database schema, the version is the number prefix of the file names
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status of the migration in the database
+type Status struct {
+	Version   int64     `json:"version"`
+	Name      string    `json:"name"`
+	Applied   bool      `json:"applied"`
+	AppliedAt time.Time `json:"applied_at,omitzero"`
+}
+
+// Lock the database on the connection and return the func releasing the lock, the migrations are applied under the
+// lock, so the instances started at once apply them one by one
+type Lock func(ctx context.Context, conn *sql.Conn) (unlock func(), err error)
+
+// Migrator apply the versioned migrations to the database, the applied versions are kept in the schema_migrations
+// table
+type Migrator struct {
+	db          *sql.DB
+	migrations  []Migration
+	placeholder func(n int) string
+	lock        Lock
+}
+
+// New migrator of the migrations found in the root of fsys, the placeholder builds the n-th query parameter of the
+// database dialect, the lock is optional for the databases written by the single process
+func New(db *sql.DB, fsys fs.FS, placeholder func(n int) string, lock Lock) (*Migrator, error) {
+	migrations, err := Parse(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:          db,
+		migrations:  migrations,
+		placeholder: placeholder,
+		lock:        lock,
+	}, nil
+}
+
+// Parse migrations from the "0001_name.up.sql" and "0001_name.down.sql" files of fsys ordered by the version
+func Parse(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, f := range files {
+		version, name, direction, ok := parseFileName(path.Base(f))
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errMigrationName, f)
+		}
+
+		b, errRead := fs.ReadFile(fsys, f)
+		if errRead != nil {
+			return nil, fmt.Errorf("failed to read migration: %w", errRead)
+		}
+
+		m, found := byVersion[version]
+		if !found {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+
+		if direction == "up" {
+			m.Up = string(b)
+		} else {
+			m.Down = string(b)
+		}
+	}
+
+	if len(byVersion) == 0 {
+		return nil, ErrNoMigrations
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("%w: %04d_%s", errNoUpMigration, m.Version, m.Name)
+		}
+
+		migrations = append(migrations, *m)
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	return migrations, nil
+}
+
+// Up apply all pending migrations in the version order, the number of the applied migrations is returned
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	unlock, err := m.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; ok {
+			continue
+		}
+
+		if err = m.exec(ctx, mg.Up,
+			"insert into schema_migrations (version, name, applied_at) values ("+
+				m.placeholder(1)+", "+m.placeholder(2)+", "+m.placeholder(3)+")",
+			mg.Version, mg.Name, time.Now().Unix(),
+		); err != nil {
+			return n, fmt.Errorf("failed to apply migration %04d_%s: %w", mg.Version, mg.Name, err)
+		}
+
+		n++
+	}
+
+	return n, nil
+}
+
+// Down roll back the last applied migration, the rolled back migration is returned, the migration without the down
+// script, like the baseline one adopting the existing tables, is never rolled back
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	unlock, err := m.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mg := m.migrations[i]
+		if _, ok := applied[mg.Version]; !ok {
+			continue
+		}
+
+		if mg.Down == "" {
+			return nil, fmt.Errorf("%w: %04d_%s", ErrIrreversible, mg.Version, mg.Name)
+		}
+
+		if err = m.exec(ctx, mg.Down,
+			"delete from schema_migrations where version="+m.placeholder(1),
+			mg.Version,
+		); err != nil {
+			return nil, fmt.Errorf("failed to roll back migration %04d_%s: %w", mg.Version, mg.Name, err)
+		}
+
+		return &mg, nil
+	}
+
+	return nil, ErrNothingToRollBack
+}
+
+// Status of all known migrations in the version order
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]Status, 0, len(m.migrations))
+
+	for _, mg := range m.migrations {
+		appliedAt, ok := applied[mg.Version]
+		status = append(status, Status{
+			Version:   mg.Version,
+			Name:      mg.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return status, nil
+}
+
+// acquire the lock of the database, the returned func releases it together with the locked connection
+func (m *Migrator) acquire(ctx context.Context) (func(), error) {
+	if m.lock == nil {
+		return func() {}, nil
+	}
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	unlock, err := m.lock(ctx, conn)
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("failed to lock schema migrations: %w", err)
+	}
+
+	return func() {
+		unlock()
+
+		_ = conn.Close()
+	}, nil
+}
+
+// applied versions of the migrations with the time they were applied at
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	if _, err := m.db.ExecContext(ctx, versionTable); err != nil {
+		return nil, fmt.Errorf("failed to create schema version table: %w", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, "select version, applied_at from schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	applied := make(map[int64]time.Time)
+
+	for rows.Next() {
+		var version, appliedAt int64
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to get schema version: %w", err)
+		}
+
+		applied[version] = time.Unix(appliedAt, 0).UTC()
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed to get schema version: %w", rows.Err())
+	}
+
+	return applied, nil
+}
+
+// exec statements of the migration script and update the schema version within the single transaction, mysql commits
+// DDL implicitly, so the failed migration could be applied partly there
+func (m *Migrator) exec(ctx context.Context, script, versionQuery string, args ...any) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, statement := range Statements(script) {
+		if _, err = tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, versionQuery, args...); err != nil {
+		return fmt.Errorf("failed to update schema version: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Statements of the sql script, the statement ends with the semicolon at the end of the line, comment lines are
+// skipped
+func Statements(script string) []string {
+	var (
+		statements []string
+		b          strings.Builder
+	)
+
+	for line := range strings.Lines(script) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		b.WriteString(line)
+
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(b.String()))
+			b.Reset()
+		}
+	}
+
+	if s := strings.TrimSpace(b.String()); s != "" {
+		statements = append(statements, s)
+	}
+
+	return statements
+}
+
+// parseFileName split the migration file name "0001_name.up.sql" to the version, name and direction
+func parseFileName(name string) (int64, string, string, bool) {
+	base, ok := strings.CutSuffix(name, ".sql")
+	if !ok {
+		return 0, "", "", false
+	}
+
+	var direction string
+
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		direction = "up"
+	case strings.HasSuffix(base, ".down"):
+		direction = "down"
+	default:
+		return 0, "", "", false
+	}
+
+	base = strings.TrimSuffix(base, "."+direction)
+
+	v, n, ok := strings.Cut(base, "_")
+	if !ok || n == "" {
+		return 0, "", "", false
+	}
+
+	version, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", "", false
+	}
+
+	return version, n, direction, true
+}
@@ -0,0 +1,119 @@
+package migrate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []Migration
+		wantErr error
+	}{
+		{
+			name: "migrations ordered by the version",
+			fsys: fstest.MapFS{
+				"0002_provider.up.sql":   {Data: []byte("alter table data add column provider;")},
+				"0002_provider.down.sql": {Data: []byte("alter table data drop column provider;")},
+				"0001_init.up.sql":       {Data: []byte("create table data;")},
+				"0001_init.down.sql":     {Data: []byte("drop table data;")},
+			},
+			want: []Migration{
+				{Version: 1, Name: "init", Up: "create table data;", Down: "drop table data;"},
+				{
+					Version: 2,
+					Name:    "provider",
+					Up:      "alter table data add column provider;",
+					Down:    "alter table data drop column provider;",
+				},
+			},
+		},
+		{
+			name: "down script is optional",
+			fsys: fstest.MapFS{
+				"0001_init.up.sql": {Data: []byte("create table data;")},
+			},
+			want: []Migration{
+				{Version: 1, Name: "init", Up: "create table data;"},
+			},
+		},
+		{
+			name: "no up script",
+			fsys: fstest.MapFS{
+				"0001_init.down.sql": {Data: []byte("drop table data;")},
+			},
+			wantErr: errNoUpMigration,
+		},
+		{
+			name: "wrong file name",
+			fsys: fstest.MapFS{
+				"init.sql": {Data: []byte("create table data;")},
+			},
+			wantErr: errMigrationName,
+		},
+		{
+			name:    "no migrations",
+			fsys:    fstest.MapFS{},
+			wantErr: ErrNoMigrations,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.fsys)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name: "several statements",
+			script: `-- comment
+create table data
+(
+    _id int
+);
+
+insert into symbols(symbol)
+values ('BTC'),
+       ('USD');
+`,
+			want: []string{
+				"create table data\n(\n    _id int\n);",
+				"insert into symbols(symbol)\nvalues ('BTC'),\n       ('USD');",
+			},
+		},
+		{
+			name:   "statement without the semicolon",
+			script: "drop table data",
+			want:   []string{"drop table data"},
+		},
+		{
+			name:   "empty script",
+			script: "\n-- nothing to do\n",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Statements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Statements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
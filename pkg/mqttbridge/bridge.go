@@ -0,0 +1,355 @@
+package mqttbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/streamdp/ccd/clients"
+	"github.com/streamdp/ccd/config"
+	"github.com/streamdp/ccd/domain"
+)
+
+const (
+	// TopicPrefix of the retained topics the collected data is published to, e.g. "ccd/BTC/USD"
+	TopicPrefix = "ccd"
+	// CommandTopic the commands are received from
+	CommandTopic = TopicPrefix + "/cmd"
+
+	// actions of the commands, they are mapped to the puller tasks and the data provider ws subscriptions
+	ActionAddTask     = "add_task"
+	ActionRemoveTask  = "remove_task"
+	ActionSubscribe   = "subscribe"
+	ActionUnsubscribe = "unsubscribe"
+
+	connectTimeout       = 10 * time.Second
+	subscribeTimeout     = 10 * time.Second
+	maxReconnectInterval = 30 * time.Second
+	disconnectQuiesce    = 250
+	maxWaitingCommands   = 100
+)
+
+var (
+	errConnectTimeout   = errors.New("mqtt connect timed out")
+	errSubscribeTimeout = errors.New("mqtt subscribe timed out")
+	errUnknownAction    = errors.New("unknown command action")
+	errUnknownSymbol    = errors.New("unknown symbol")
+	errInvalidInterval  = errors.New("interval must be positive")
+)
+
+type Puller interface {
+	AddTask(ctx context.Context, provider string, from string, to string, interval int64) (*clients.Task, error)
+	RemoveTask(ctx context.Context, provider string, from string, to string)
+}
+
+// Providers are the enabled data providers with their ws clients, the empty name stands for the default provider
+type Providers interface {
+	IsPresent(provider string) bool
+	IsWsPresent(provider string) bool
+	Subscribe(ctx context.Context, provider string, from string, to string) error
+	Unsubscribe(ctx context.Context, provider string, from string, to string) error
+}
+
+type Symbols interface {
+	IsPresent(s string) bool
+}
+
+// Authorizer of the commands, they need the api key with the same scope as the collect api
+type Authorizer interface {
+	Authorize(key, scope string) error
+}
+
+// Command received from the command topic, the fields are the same as the collect query ones, the api key is required
+// when the authentication is enabled, the pulling interval is 60 seconds unless it is set
+type Command struct {
+	Action   string `json:"action"`
+	ApiKey   string `json:"api_key,omitempty"`
+	Provider string `json:"provider"`
+	From     string `json:"fsym"`
+	To       string `json:"tsym"`
+	Interval int64  `json:"interval"`
+}
+
+// Bridge publishes the collected data received from its data pipe to the retained pair topics of the MQTT broker and
+// runs the commands received from the command topic, the commands are run one by one apart from the publishing, so
+// the slow command never holds up the data pipe
+type Bridge struct {
+	ctx    context.Context
+	l      *log.Logger
+	client mqtt.Client
+	qos    byte
+
+	puller    Puller
+	providers Providers
+	symbols   Symbols
+	auth      Authorizer
+
+	pipe     chan *domain.Data
+	done     chan struct{}
+	commands chan []byte
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+// Connect to the MQTT broker and subscribe to the command topic, the connection is restored in the background and the
+// command topic is subscribed again on every reconnect, the commands are waiting until RunCommands is called
+func Connect(ctx context.Context, l *log.Logger, opts *mqtt.ClientOptions, qos byte) (*Bridge, error) {
+	b := &Bridge{
+		ctx:      ctx,
+		l:        l,
+		qos:      qos,
+		pipe:     make(chan *domain.Data, 1000),
+		done:     make(chan struct{}),
+		commands: make(chan []byte, maxWaitingCommands),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	subscribed := make(chan error, 1)
+
+	opts.SetAutoReconnect(true).
+		SetConnectTimeout(connectTimeout).
+		SetMaxReconnectInterval(maxReconnectInterval).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			l.Printf("mqtt connection lost: %v", err)
+		}).
+		SetOnConnectHandler(func(c mqtt.Client) {
+			err := b.subscribe(c)
+			select {
+			case subscribed <- err:
+			default:
+			}
+
+			if err != nil {
+				l.Printf("failed to subscribe to the mqtt command topic: %v", err)
+			}
+		})
+
+	b.client = mqtt.NewClient(opts)
+
+	if err := wait(b.client.Connect(), connectTimeout, errConnectTimeout); err != nil {
+		return nil, fmt.Errorf("failed to connect to mqtt broker: %w", err)
+	}
+
+	if err := <-subscribed; err != nil {
+		b.client.Disconnect(0)
+
+		return nil, err
+	}
+
+	go b.serve()
+
+	return b, nil
+}
+
+// RunCommands received from the command topic with the puller and the data provider ws clients, it is called once,
+// they are not known yet when the bridge data pipe is passed to them. The commands are authorized unless the authorizer is
+// nil, when the authentication is disabled
+func (b *Bridge) RunCommands(p Puller, providers Providers, s Symbols, a Authorizer) {
+	b.puller = p
+	b.providers = providers
+	b.symbols = s
+	b.auth = a
+
+	go b.serveCommands()
+}
+
+func (b *Bridge) DataPipe() chan *domain.Data {
+	return b.pipe
+}
+
+// StopCommands wait for the running command and drop the rest of them, it is called before the puller and the ws
+// clients are closed
+func (b *Bridge) StopCommands() {
+	close(b.stop)
+
+	if b.puller != nil {
+		<-b.stopped
+	}
+}
+
+// Close stop publishing and disconnect from the broker, the data pipe must not be used afterward
+func (b *Bridge) Close() {
+	close(b.pipe)
+	<-b.done
+
+	b.client.Disconnect(disconnectQuiesce)
+}
+
+func (b *Bridge) serve() {
+	defer close(b.done)
+
+	for data := range b.pipe {
+		if err := b.publish(data); err != nil {
+			publishErrors.Inc()
+			b.l.Printf("failed to publish %s/%s data to mqtt: %v", data.FromSymbol, data.ToSymbol, err)
+		}
+	}
+}
+
+// publish the data without waiting for the broker, with qos 1 and 2 the data published while the client reconnects
+// is sent on the reconnect, with qos 0 it is dropped
+func (b *Bridge) publish(data *domain.Data) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	t := b.client.Publish(Topic(data.FromSymbol, data.ToSymbol), b.qos, true, body)
+
+	select {
+	case <-t.Done():
+		return t.Error()
+	default:
+		return nil
+	}
+}
+
+// Topic of the pair data, the symbols are upper cased and the characters not allowed in the topic levels are replaced
+// with "_"
+func Topic(from, to string) string {
+	return TopicPrefix + "/" + level(from) + "/" + level(to)
+}
+
+func level(symbol string) string {
+	if symbol == "" {
+		return "_"
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '+', '#', 0:
+			return '_'
+		default:
+			return r
+		}
+	}, strings.ToUpper(symbol))
+}
+
+func (b *Bridge) subscribe(c mqtt.Client) error {
+	return wait(c.Subscribe(CommandTopic, b.qos, b.receive), subscribeTimeout, errSubscribeTimeout)
+}
+
+// receive the command without blocking the client, the command is dropped when too many of them are waiting
+func (b *Bridge) receive(_ mqtt.Client, m mqtt.Message) {
+	select {
+	case b.commands <- m.Payload():
+	default:
+		b.l.Printf("mqtt command dropped, %d commands are waiting", maxWaitingCommands)
+	}
+}
+
+func (b *Bridge) serveCommands() {
+	defer close(b.stopped)
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case payload := <-b.commands:
+			c := &Command{Interval: config.DefaultPullingInterval}
+			if err := json.Unmarshal(payload, c); err != nil {
+				receivedCommands.WithLabelValues("unknown", "error").Inc()
+				b.l.Printf("failed to decode mqtt command: %v", err)
+
+				continue
+			}
+
+			if err := b.execute(c); err != nil {
+				receivedCommands.WithLabelValues(actionLabel(c.Action), "error").Inc()
+				b.l.Printf("mqtt command %s %s/%s failed: %v", c.Action, c.From, c.To, err)
+
+				continue
+			}
+
+			receivedCommands.WithLabelValues(c.Action, "success").Inc()
+			b.l.Printf("mqtt command %s %s/%s done", c.Action, c.From, c.To)
+		}
+	}
+}
+
+// execute the command, the api key, the data provider and the symbols are checked the same way as for the collect api
+func (b *Bridge) execute(c *Command) error {
+	if b.auth != nil {
+		if err := b.auth.Authorize(c.ApiKey, domain.ScopeCollectWrite); err != nil {
+			return err
+		}
+	}
+
+	c.Provider = strings.ToLower(c.Provider)
+	c.From = strings.ToUpper(c.From)
+	c.To = strings.ToUpper(c.To)
+
+	if !isAction(c.Action) {
+		return fmt.Errorf("%w: %q", errUnknownAction, c.Action)
+	}
+
+	if !b.providers.IsPresent(c.Provider) {
+		return fmt.Errorf("%w: %q", clients.ErrUnknownProvider, c.Provider)
+	}
+
+	// the consolidated price is pulled over rest only
+	if (c.Action == ActionSubscribe || c.Action == ActionUnsubscribe) && !b.providers.IsWsPresent(c.Provider) {
+		return fmt.Errorf("%w: %q has no ws client", clients.ErrUnknownProvider, c.Provider)
+	}
+
+	for _, s := range []string{c.From, c.To} {
+		if !b.symbols.IsPresent(s) {
+			return fmt.Errorf("%w: %q", errUnknownSymbol, s)
+		}
+	}
+
+	switch c.Action {
+	case ActionAddTask:
+		if c.Interval <= 0 {
+			return fmt.Errorf("%w: %d", errInvalidInterval, c.Interval)
+		}
+
+		if _, err := b.puller.AddTask(b.ctx, c.Provider, c.From, c.To, c.Interval); err != nil {
+			return fmt.Errorf("failed to start data collection: %w", err)
+		}
+	case ActionRemoveTask:
+		b.puller.RemoveTask(b.ctx, c.Provider, c.From, c.To)
+	case ActionSubscribe:
+		if err := b.providers.Subscribe(b.ctx, c.Provider, c.From, c.To); err != nil {
+			return fmt.Errorf("subscribe error: %w", err)
+		}
+	case ActionUnsubscribe:
+		if err := b.providers.Unsubscribe(b.ctx, c.Provider, c.From, c.To); err != nil {
+			return fmt.Errorf("unsubscribe error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func isAction(action string) bool {
+	switch action {
+	case ActionAddTask, ActionRemoveTask, ActionSubscribe, ActionUnsubscribe:
+		return true
+	default:
+		return false
+	}
+}
+
+// actionLabel return the action or "unknown" one, so the metric labels are limited to the known actions
+func actionLabel(action string) string {
+	if isAction(action) {
+		return action
+	}
+
+	return "unknown"
+}
+
+func wait(t mqtt.Token, timeout time.Duration, errTimeout error) error {
+	if !t.WaitTimeout(timeout) {
+		return errTimeout
+	}
+
+	return t.Error()
+}
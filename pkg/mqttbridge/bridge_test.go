@@ -0,0 +1,385 @@
+package mqttbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/streamdp/ccd/clients"
+	"github.com/streamdp/ccd/domain"
+)
+
+var errSubscribe = errors.New("subscribe failed")
+
+// runBroker start the in-process MQTT broker on the address, "127.0.0.1:0" picks the free port, and return its url with
+// the func stopping it
+func runBroker(t *testing.T, address string) (string, func()) {
+	t.Helper()
+
+	s := mochi.New(&mochi.Options{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if err := s.AddHook(new(auth.AllowHook), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	l := listeners.NewTCP(listeners.Config{ID: "tcp", Address: address})
+	if err := s.AddListener(l); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Serve(); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := sync.OnceFunc(func() {
+		_ = s.Close()
+	})
+	t.Cleanup(stop)
+
+	return "tcp://" + l.Address(), stop
+}
+
+// fakeCollector record the puller and ws clients calls
+type fakeCollector struct {
+	calls chan string
+	err   error
+}
+
+func (f *fakeCollector) AddTask(_ context.Context, provider, from, to string, interval int64) (*clients.Task, error) {
+	f.calls <- fmt.Sprintf("%s %s %s/%s %d", ActionAddTask, provider, from, to, interval)
+
+	return &clients.Task{}, nil
+}
+
+func (f *fakeCollector) RemoveTask(_ context.Context, provider, from, to string) {
+	f.calls <- fmt.Sprintf("%s %s %s/%s", ActionRemoveTask, provider, from, to)
+}
+
+func (f *fakeCollector) Subscribe(_ context.Context, provider, from, to string) error {
+	f.calls <- fmt.Sprintf("%s %s %s/%s", ActionSubscribe, provider, from, to)
+
+	return f.err
+}
+
+func (f *fakeCollector) IsPresent(provider string) bool {
+	return provider == "" || provider == "kraken" || provider == clients.ConsolidatedName
+}
+
+func (f *fakeCollector) IsWsPresent(provider string) bool {
+	return provider == "" || provider == "kraken"
+}
+
+func (f *fakeCollector) Unsubscribe(_ context.Context, provider, from, to string) error {
+	f.calls <- fmt.Sprintf("%s %s %s/%s", ActionUnsubscribe, provider, from, to)
+
+	return f.err
+}
+
+type symbols map[string]bool
+
+func (s symbols) IsPresent(symbol string) bool {
+	return s[symbol]
+}
+
+var testSymbols = symbols{"BTC": true, "ETH": true, "USD": true}
+
+// keys with their scopes
+type keys map[string]string
+
+func (k keys) Authorize(key, scope string) error {
+	granted, ok := k[key]
+	if !ok {
+		return domain.ErrUnauthorized
+	}
+
+	if granted != scope {
+		return domain.ErrForbidden
+	}
+
+	return nil
+}
+
+func connect(t *testing.T, url string) (*Bridge, *fakeCollector) {
+	t.Helper()
+
+	f := &fakeCollector{calls: make(chan string, 10)}
+
+	b, err := Connect(context.Background(), log.New(io.Discard, "", 0),
+		mqtt.NewClientOptions().AddBroker(url).SetClientID("ccd"), 1,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.RunCommands(f, f, testSymbols, nil)
+
+	return b, f
+}
+
+func newClient(t *testing.T, url string) mqtt.Client {
+	t.Helper()
+
+	c := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(url))
+	if err := wait(c.Connect(), time.Second, errConnectTimeout); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		c.Disconnect(0)
+	})
+
+	return c
+}
+
+func TestBridge_publish(t *testing.T) {
+	url, _ := runBroker(t, "127.0.0.1:0")
+	b, _ := connect(t, url)
+
+	data := &domain.Data{FromSymbol: "btc", ToSymbol: "usd", Provider: "kraken", Price: 100001, LastUpdate: 2}
+
+	b.DataPipe() <- &domain.Data{FromSymbol: "BTC", ToSymbol: "USD", Provider: "kraken", Price: 100000, LastUpdate: 1}
+	b.DataPipe() <- data
+
+	b.StopCommands()
+	b.Close()
+
+	// the display connected after the publish gets the last price of the pair
+	msgs := make(chan mqtt.Message, 10)
+	if err := wait(newClient(t, url).Subscribe(TopicPrefix+"/+/+", 1, func(_ mqtt.Client, m mqtt.Message) {
+		msgs <- m
+	}), time.Second, errSubscribeTimeout); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case m := <-msgs:
+		if m.Topic() != "ccd/BTC/USD" || !m.Retained() {
+			t.Errorf("topic = %v, retained = %v, want %v, true", m.Topic(), m.Retained(), "ccd/BTC/USD")
+		}
+
+		got := &domain.Data{}
+		if err := json.Unmarshal(m.Payload(), got); err != nil {
+			t.Fatal(err)
+		}
+
+		if *got != *data {
+			t.Errorf("data = %v, want %v", got, data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("retained data is not received")
+	}
+
+	if len(msgs) != 0 {
+		t.Errorf("received %d more messages, want the last one only", len(msgs))
+	}
+}
+
+func TestBridge_commands(t *testing.T) {
+	url, stop := runBroker(t, "127.0.0.1:0")
+	b, f := connect(t, url)
+	defer b.Close()
+	defer b.StopCommands()
+
+	command := func(c mqtt.Client, payload string, want string) {
+		t.Helper()
+
+		if err := wait(c.Publish(CommandTopic, 1, false, payload), time.Second, errConnectTimeout); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case got := <-f.calls:
+			if got != want {
+				t.Errorf("call = %v, want %v", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("command %s is not run", payload)
+		}
+	}
+
+	command(newClient(t, url),
+		`{"action": "add_task", "provider": "kraken", "fsym": "btc", "tsym": "usd", "interval": 30}`,
+		"add_task kraken BTC/USD 30",
+	)
+
+	command(newClient(t, url),
+		`{"action": "add_task", "provider": "kraken", "fsym": "eth", "tsym": "usd"}`,
+		"add_task kraken ETH/USD 60",
+	)
+
+	// the command topic is subscribed again on the reconnect, the commands published before it are lost
+	stop()
+	runBroker(t, strings.TrimPrefix(url, "tcp://"))
+
+	c := newClient(t, url)
+	payload := `{"action": "unsubscribe", "fsym": "ETH", "tsym": "USD"}`
+
+	for deadline := time.Now().Add(10 * time.Second); len(f.calls) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("command topic is not subscribed again")
+		}
+
+		if err := wait(c.Publish(CommandTopic, 1, false, payload), time.Second, errConnectTimeout); err != nil {
+			t.Fatal(err)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if got := <-f.calls; got != "unsubscribe  ETH/USD" {
+		t.Errorf("call = %v, want %v", got, "unsubscribe  ETH/USD")
+	}
+}
+
+func TestBridge_execute(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  *Command
+		auth     Authorizer
+		wsErr    error
+		wantCall string
+		wantErr  error
+	}{
+		{
+			name:     "add task",
+			command:  &Command{Action: ActionAddTask, Provider: "Kraken", From: "btc", To: "usd", Interval: 30},
+			wantCall: "add_task kraken BTC/USD 30",
+		},
+		{
+			name:    "invalid interval",
+			command: &Command{Action: ActionAddTask, Provider: "kraken", From: "BTC", To: "USD", Interval: -1},
+			wantErr: errInvalidInterval,
+		},
+		{
+			name:    "unknown provider",
+			command: &Command{Action: ActionAddTask, Provider: "bitfinex", From: "BTC", To: "USD", Interval: 30},
+			wantErr: clients.ErrUnknownProvider,
+		},
+		{
+			name: "consolidated task",
+			command: &Command{
+				Action: ActionAddTask, Provider: clients.ConsolidatedName, From: "BTC", To: "USD", Interval: 30,
+			},
+			wantCall: "add_task consolidated BTC/USD 30",
+		},
+		{
+			name:    "consolidated subscribe",
+			command: &Command{Action: ActionSubscribe, Provider: clients.ConsolidatedName, From: "BTC", To: "USD"},
+			wantErr: clients.ErrUnknownProvider,
+		},
+		{
+			name:     "remove task",
+			command:  &Command{Action: ActionRemoveTask, Provider: "kraken", From: "BTC", To: "USD"},
+			wantCall: "remove_task kraken BTC/USD",
+		},
+		{
+			name:     "subscribe",
+			command:  &Command{Action: ActionSubscribe, Provider: "kraken", From: "ETH", To: "USD"},
+			wantCall: "subscribe kraken ETH/USD",
+		},
+		{
+			name:     "subscribe error",
+			command:  &Command{Action: ActionSubscribe, Provider: "kraken", From: "ETH", To: "USD"},
+			wsErr:    errSubscribe,
+			wantCall: "subscribe kraken ETH/USD",
+			wantErr:  errSubscribe,
+		},
+		{
+			name:    "unknown symbol",
+			command: &Command{Action: ActionSubscribe, Provider: "kraken", From: "DOGE", To: "USD"},
+			wantErr: errUnknownSymbol,
+		},
+		{
+			name:    "unknown action",
+			command: &Command{Action: "restart", From: "BTC", To: "USD"},
+			wantErr: errUnknownAction,
+		},
+		{
+			name: "authorized",
+			command: &Command{
+				Action: ActionRemoveTask, ApiKey: "writer", Provider: "kraken", From: "BTC", To: "USD",
+			},
+			auth:     keys{"writer": domain.ScopeCollectWrite},
+			wantCall: "remove_task kraken BTC/USD",
+		},
+		{
+			name:    "without api key",
+			command: &Command{Action: ActionRemoveTask, Provider: "kraken", From: "BTC", To: "USD"},
+			auth:    keys{"writer": domain.ScopeCollectWrite},
+			wantErr: domain.ErrUnauthorized,
+		},
+		{
+			name: "without scope",
+			command: &Command{
+				Action: ActionRemoveTask, ApiKey: "reader", Provider: "kraken", From: "BTC", To: "USD",
+			},
+			auth:    keys{"reader": domain.ScopeRead},
+			wantErr: domain.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeCollector{calls: make(chan string, 1), err: tt.wsErr}
+			b := &Bridge{ctx: context.Background(), puller: f, providers: f, symbols: testSymbols, auth: tt.auth}
+
+			if err := b.execute(tt.command); !errors.Is(err, tt.wantErr) {
+				t.Errorf("execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got string
+			if len(f.calls) != 0 {
+				got = <-f.calls
+			}
+
+			if got != tt.wantCall {
+				t.Errorf("execute() call = %v, want %v", got, tt.wantCall)
+			}
+		})
+	}
+}
+
+func TestTopic(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{
+			name: "upper cased",
+			from: "btc",
+			to:   "usdt",
+			want: "ccd/BTC/USDT",
+		},
+		{
+			name: "wildcards and separators",
+			from: "a/b+",
+			to:   "c#",
+			want: "ccd/A_B_/C_",
+		},
+		{
+			name: "empty symbol",
+			from: "",
+			to:   "USD",
+			want: "ccd/_/USD",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Topic(tt.from, tt.to); got != tt.want {
+				t.Errorf("Topic() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
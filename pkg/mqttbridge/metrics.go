@@ -0,0 +1,23 @@
+package mqttbridge
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/streamdp/ccd/pkg/metrics"
+)
+
+var (
+	// publishErrors of the collected data published to the MQTT broker
+	publishErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "mqtt_publish_errors_total",
+		Help:      "Number of the collected data rows failed to be published to the MQTT broker.",
+	})
+
+	// receivedCommands from the MQTT command topic by the action and the result
+	receivedCommands = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "mqtt_commands_total",
+		Help:      "Number of the commands received from the MQTT command topic.",
+	}, []string{"action", "result"})
+)
@@ -0,0 +1,14 @@
+package natspub
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/streamdp/ccd/pkg/metrics"
+)
+
+// publishErrors of the collected data published to NATS
+var publishErrors = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "nats_publish_errors_total",
+	Help:      "Number of the collected data rows failed to be published to NATS.",
+})
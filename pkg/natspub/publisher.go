@@ -0,0 +1,220 @@
+package natspub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/streamdp/ccd/domain"
+)
+
+const (
+	// SubjectPrefix of the subjects the collected data is published to, e.g. "ccd.ticks.BTC.USD"
+	SubjectPrefix = "ccd.ticks"
+
+	clientName       = "ccd"
+	reconnectWait    = 2 * time.Second
+	maxPendingAcks   = 1000
+	ackTimeout       = 10 * time.Second
+	traceParentField = "traceparent"
+)
+
+// Publisher publishes the collected data received from its data pipe to NATS, the data is stored with the JetStream
+// stream when it is set, the connection is restored in the background and the data published meanwhile is buffered
+// by the client and sent on the reconnect
+type Publisher struct {
+	l    *log.Logger
+	conn *nats.Conn
+	js   jetstream.JetStream
+
+	pipe chan *domain.Data
+	done chan struct{}
+}
+
+// Connect to the NATS server, the stream is created with the data subjects when it doesn't exist, an existing stream
+// is used as it is
+func Connect(ctx context.Context, l *log.Logger, url, stream string) (*Publisher, error) {
+	conn, err := nats.Connect(url,
+		nats.Name(clientName),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(reconnectWait),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				l.Printf("nats disconnected: %v", err)
+			}
+		}),
+		nats.ReconnectHandler(func(c *nats.Conn) {
+			l.Printf("nats reconnected to %s", c.ConnectedUrlRedacted())
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	p := &Publisher{
+		l:    l,
+		conn: conn,
+		pipe: make(chan *domain.Data, 1000),
+		done: make(chan struct{}),
+	}
+
+	if stream != "" {
+		if err = p.jetStream(ctx, stream); err != nil {
+			conn.Close()
+
+			return nil, err
+		}
+	}
+
+	go p.serve()
+
+	return p, nil
+}
+
+func (p *Publisher) DataPipe() chan *domain.Data {
+	return p.pipe
+}
+
+// Close stop publishing, the data buffered or waiting for the stream acks is waited for until ctx is done, the data
+// pipe must not be used afterward
+func (p *Publisher) Close(ctx context.Context) error {
+	close(p.pipe)
+	<-p.done
+
+	defer p.conn.Close()
+
+	if p.js != nil {
+		select {
+		case <-p.js.PublishAsyncComplete():
+		case <-ctx.Done():
+			return fmt.Errorf("%d rows are not acknowledged by the stream: %w", p.js.PublishAsyncPending(), ctx.Err())
+		}
+	}
+
+	// the flush requires the deadline, the default flush timeout is used without it
+	flush := p.conn.Flush
+	if _, ok := ctx.Deadline(); ok {
+		flush = func() error { return p.conn.FlushWithContext(ctx) }
+	}
+
+	if err := flush(); err != nil && !errors.Is(err, nats.ErrConnectionClosed) {
+		return fmt.Errorf("failed to flush nats connection: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Publisher) serve() {
+	defer close(p.done)
+
+	for data := range p.pipe {
+		if err := p.publish(data); err != nil {
+			publishErrors.Inc()
+			p.l.Printf("failed to publish %s/%s data: %v", data.FromSymbol, data.ToSymbol, err)
+		}
+	}
+}
+
+func (p *Publisher) publish(data *domain.Data) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	msg := nats.NewMsg(Subject(data.FromSymbol, data.ToSymbol))
+	msg.Data = body
+
+	if tp := data.Trace(); tp != "" {
+		msg.Header.Set(traceParentField, tp)
+	}
+
+	if p.js == nil {
+		if err = p.conn.PublishMsg(msg); err != nil {
+			return fmt.Errorf("failed to publish: %w", err)
+		}
+
+		return nil
+	}
+
+	// the same data sent again by the provider is stored once within the stream duplicates window
+	if _, err = p.js.PublishMsgAsync(msg, jetstream.WithMsgID(msgId(data))); err != nil {
+		return fmt.Errorf("failed to publish to stream: %w", err)
+	}
+
+	return nil
+}
+
+// Subject of the pair data, the symbols are upper cased and the characters not allowed in the subject tokens are
+// replaced with "_"
+func Subject(from, to string) string {
+	return SubjectPrefix + "." + token(from) + "." + token(to)
+}
+
+func token(symbol string) string {
+	if symbol == "" {
+		return "_"
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		default:
+			return r
+		}
+	}, strings.ToUpper(symbol))
+}
+
+// asyncError handle the failed stream publish, only the ack is lost on the disconnect, the message itself is sent
+// from the reconnect buffer
+func (p *Publisher) asyncError(_ jetstream.JetStream, msg *nats.Msg, err error) {
+	if errors.Is(err, nats.ErrDisconnected) {
+		return
+	}
+
+	publishErrors.Inc()
+	p.l.Printf("failed to store %s data in the stream: %v", msg.Subject, err)
+}
+
+func msgId(data *domain.Data) string {
+	return strings.Join([]string{
+		data.Provider, strings.ToUpper(data.FromSymbol), strings.ToUpper(data.ToSymbol),
+		strconv.FormatInt(data.LastUpdate, 10),
+	}, ":")
+}
+
+func (p *Publisher) jetStream(ctx context.Context, stream string) error {
+	js, err := jetstream.New(p.conn,
+		jetstream.WithPublishAsyncMaxPending(maxPendingAcks),
+		jetstream.WithPublishAsyncTimeout(ackTimeout),
+		jetstream.WithPublishAsyncErrHandler(p.asyncError),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to init jetstream: %w", err)
+	}
+
+	if _, err = js.Stream(ctx, stream); err != nil {
+		if !errors.Is(err, jetstream.ErrStreamNotFound) {
+			return fmt.Errorf("failed to get stream %q: %w", stream, err)
+		}
+
+		if _, err = js.CreateStream(ctx, jetstream.StreamConfig{
+			Name:     stream,
+			Subjects: []string{SubjectPrefix + ".>"},
+			Storage:  jetstream.FileStorage,
+		}); err != nil {
+			return fmt.Errorf("failed to create stream %q: %w", stream, err)
+		}
+	}
+
+	p.js = js
+
+	return nil
+}
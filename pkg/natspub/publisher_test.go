@@ -0,0 +1,273 @@
+package natspub
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/streamdp/ccd/domain"
+)
+
+// runServer start the embedded NATS server with JetStream enabled, the port is kept when the server is restarted
+func runServer(t *testing.T, port int, storeDir string) *server.Server {
+	t.Helper()
+
+	s, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      port,
+		JetStream: true,
+		StoreDir:  storeDir,
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go s.Start()
+
+	if !s.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server is not ready")
+	}
+
+	t.Cleanup(s.Shutdown)
+
+	return s
+}
+
+func subscribe(t *testing.T, url string) chan *nats.Msg {
+	t.Helper()
+
+	conn, err := nats.Connect(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(conn.Close)
+
+	msgs := make(chan *nats.Msg, 10)
+	if _, err = conn.ChanSubscribe(SubjectPrefix+".>", msgs); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = conn.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	return msgs
+}
+
+func receive(t *testing.T, msgs chan *nats.Msg) (string, *domain.Data) {
+	t.Helper()
+
+	select {
+	case msg := <-msgs:
+		data := &domain.Data{}
+		if err := json.Unmarshal(msg.Data, data); err != nil {
+			t.Fatal(err)
+		}
+
+		return msg.Subject, data
+	case <-time.After(5 * time.Second):
+		t.Fatal("data is not published")
+	}
+
+	return "", nil
+}
+
+func TestPublisher(t *testing.T) {
+	ctx := context.Background()
+	s := runServer(t, server.RANDOM_PORT, t.TempDir())
+	msgs := subscribe(t, s.ClientURL())
+
+	p, err := Connect(ctx, log.New(io.Discard, "", 0), s.ClientURL(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := &domain.Data{FromSymbol: "btc", ToSymbol: "usd", Provider: "kraken", Price: 100000, LastUpdate: 1}
+	p.DataPipe() <- data
+
+	if err = p.Close(ctx); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	subject, got := receive(t, msgs)
+	if subject != "ccd.ticks.BTC.USD" {
+		t.Errorf("subject = %v, want %v", subject, "ccd.ticks.BTC.USD")
+	}
+
+	if !reflect.DeepEqual(got, data) {
+		t.Errorf("data = %v, want %v", got, data)
+	}
+}
+
+func TestPublisher_jetStream(t *testing.T) {
+	ctx := context.Background()
+	s := runServer(t, server.RANDOM_PORT, t.TempDir())
+
+	p, err := Connect(ctx, log.New(io.Discard, "", 0), s.ClientURL(), "CCD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := &domain.Data{FromSymbol: "BTC", ToSymbol: "USD", Provider: "kraken", Price: 100000, LastUpdate: 1}
+
+	// the duplicate is stored once
+	p.DataPipe() <- data
+	p.DataPipe() <- data
+	p.DataPipe() <- &domain.Data{FromSymbol: "ETH", ToSymbol: "USD", Provider: "kraken", Price: 4000, LastUpdate: 1}
+
+	if err = p.Close(ctx); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	conn, err := nats.Connect(s.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := js.Stream(ctx, "CCD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := stream.CachedInfo().State.Msgs; n != 2 {
+		t.Errorf("stream messages = %v, want 2", n)
+	}
+
+	msg, err := stream.GetLastMsgForSubject(ctx, "ccd.ticks.BTC.USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := &domain.Data{}
+	if err = json.Unmarshal(msg.Data, got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, data) {
+		t.Errorf("stored data = %v, want %v", got, data)
+	}
+
+	// the existing stream is used as it is
+	p, err = Connect(ctx, log.New(io.Discard, "", 0), s.ClientURL(), "CCD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = p.Close(ctx); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestPublisher_reconnect(t *testing.T) {
+	ctx := context.Background()
+	storeDir := t.TempDir()
+	s := runServer(t, server.RANDOM_PORT, storeDir)
+	port := s.Addr().(*net.TCPAddr).Port
+
+	p, err := Connect(ctx, log.New(io.Discard, "", 0), s.ClientURL(), "CCD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Shutdown()
+	s.WaitForShutdown()
+
+	// the data written before the disconnect is noticed would be lost with the closed socket
+	for p.conn.Status() == nats.CONNECTED {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the data published while the server is down is buffered until the connection is restored
+	p.DataPipe() <- &domain.Data{FromSymbol: "BTC", ToSymbol: "USD", Provider: "kraken", Price: 100000, LastUpdate: 1}
+
+	s = runServer(t, port, storeDir)
+
+	closeCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err = p.Close(closeCtx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	conn, err := nats.Connect(s.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the stored messages are counted by the server in the background
+	var n uint64
+	for range 50 {
+		info, errInfo := js.Stream(ctx, "CCD")
+		if errInfo != nil {
+			t.Fatal(errInfo)
+		}
+
+		if n = info.CachedInfo().State.Msgs; n != 0 {
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if n != 1 {
+		t.Errorf("stream messages = %v, want 1", n)
+	}
+}
+
+func TestSubject(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{
+			name: "upper cased",
+			from: "btc",
+			to:   "usdt",
+			want: "ccd.ticks.BTC.USDT",
+		},
+		{
+			name: "wildcards and separators",
+			from: "a.b*",
+			to:   "c >",
+			want: "ccd.ticks.A_B_.C__",
+		},
+		{
+			name: "empty symbol",
+			from: "",
+			to:   "USD",
+			want: "ccd.ticks._.USD",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Subject(tt.from, tt.to); got != tt.want {
+				t.Errorf("Subject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// lowBudget is the share of the monthly budget below which the callers are asked to slow down
+	lowBudget = 0.2
+	// maxSlowDown is the largest factor the pulling interval is multiplied by when the budget is running out
+	maxSlowDown = 10
+)
+
+var ErrBudgetExhausted = errors.New("monthly request budget is exhausted")
+
+// Budget of the upstream calls for the current calendar month (UTC), the zero Limit means the unlimited budget
+type Budget struct {
+	Limit     int64     `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+	SlowDown  int64     `json:"slow_down"`
+}
+
+// Limiter is the token bucket spacing the upstream calls with the monthly budget of the calls on top of it
+type Limiter struct {
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	budget  int64
+	used    int64
+	resetAt time.Time
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+// New limiter making at most rate calls per second and budget calls per month, zero rate or budget means no limit
+func New(rate float64, budget int64) *Limiter {
+	return &Limiter{
+		rate:   rate,
+		burst:  max(1, math.Floor(rate)),
+		tokens: max(1, math.Floor(rate)),
+		budget: budget,
+		now:    time.Now,
+	}
+}
+
+// Wait until the next call is allowed by the rate limit or ctx is done, the call is taken from the monthly budget,
+// the error is returned when the budget is exhausted. The call given up when ctx is done is returned to the budget
+func (l *Limiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	delay, resetAt, err := l.reserve()
+	if err != nil {
+		return err
+	}
+
+	if delay <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.refund(resetAt)
+
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Budget return the state of the monthly budget
+func (l *Limiter) Budget() Budget {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.resetBudget(l.now())
+
+	b := Budget{
+		Limit:    l.budget,
+		Used:     l.used,
+		ResetAt:  l.resetAt,
+		SlowDown: 1,
+	}
+
+	if l.budget > 0 {
+		b.Remaining = max(0, l.budget-l.used)
+		b.SlowDown = slowDown(b.Remaining, b.Limit)
+	}
+
+	return b
+}
+
+// Restore the calls used of the monthly budget reset at resetAt, e.g. saved before the restart, the calls of the other
+// month are ignored
+func (l *Limiter) Restore(used int64, resetAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.resetBudget(l.now())
+
+	if resetAt.Equal(l.resetAt) {
+		l.used = max(l.used, used)
+	}
+}
+
+// reserve the token and return how long the caller has to wait for it and when the budget the call is taken from
+// is reset
+func (l *Limiter) reserve() (time.Duration, time.Time, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.resetBudget(now)
+
+	if l.budget > 0 && l.used >= l.budget {
+		return 0, l.resetAt, ErrBudgetExhausted
+	}
+
+	l.used++
+
+	if l.rate <= 0 {
+		return 0, l.resetAt, nil
+	}
+
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+
+	l.last = now
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0, l.resetAt, nil
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second)), l.resetAt, nil
+}
+
+// refund the reserved token and the call taken from the budget reset at resetAt, the call of the past month is not
+// returned to the new budget
+func (l *Limiter) refund(resetAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = min(l.burst, l.tokens+1)
+
+	if resetAt.Equal(l.resetAt) && l.used > 0 {
+		l.used--
+	}
+}
+
+// resetBudget when the new month starts
+func (l *Limiter) resetBudget(now time.Time) {
+	if now.Before(l.resetAt) {
+		return
+	}
+
+	now = now.UTC()
+	l.used = 0
+	l.resetAt = time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// slowDown return the factor the pulling interval should be multiplied by, it grows as the remaining share of the
+// budget falls below the lowBudget
+func slowDown(remaining, limit int64) int64 {
+	share := float64(remaining) / float64(limit)
+
+	switch {
+	case share >= lowBudget:
+		return 1
+	case remaining == 0:
+		return maxSlowDown
+	}
+
+	return min(maxSlowDown, int64(math.Ceil(lowBudget/share)))
+}
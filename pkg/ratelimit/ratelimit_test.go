@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLimiter_reserve(t *testing.T) {
+	now := time.Date(2025, 5, 19, 10, 0, 0, 0, time.UTC)
+
+	l := New(2, 0)
+	l.now = func() time.Time { return now }
+
+	var got []time.Duration
+
+	for range 3 {
+		delay, _, err := l.reserve()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got = append(got, delay)
+	}
+
+	now = now.Add(2 * time.Second)
+
+	delay, _, err := l.reserve()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got = append(got, delay)
+
+	want := []time.Duration{0, 0, 500 * time.Millisecond, 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reserve() = %v, want %v", got, want)
+	}
+}
+
+func TestLimiter_Budget(t *testing.T) {
+	now := time.Date(2025, 5, 31, 23, 59, 0, 0, time.UTC)
+
+	l := New(0, 10)
+	l.now = func() time.Time { return now }
+
+	for range 9 {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := Budget{
+		Limit:     10,
+		Used:      9,
+		Remaining: 1,
+		ResetAt:   time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		SlowDown:  2,
+	}
+	if got := l.Budget(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Budget() = %+v, want %+v", got, want)
+	}
+
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.Wait(context.Background()); !errors.Is(err, ErrBudgetExhausted) {
+		t.Errorf("Wait() error = %v, wantErr %v", err, ErrBudgetExhausted)
+	}
+
+	now = now.Add(time.Minute)
+
+	if err := l.Wait(context.Background()); err != nil {
+		t.Errorf("Wait() error = %v after the budget reset", err)
+	}
+
+	if got := l.Budget(); got.Used != 1 || got.ResetAt.Month() != time.July {
+		t.Errorf("Budget() = %+v, want the new month budget", got)
+	}
+}
+
+func TestLimiter_Restore(t *testing.T) {
+	now := time.Date(2025, 5, 20, 10, 0, 0, 0, time.UTC)
+
+	l := New(0, 10)
+	l.now = func() time.Time { return now }
+
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the calls of the last month are ignored, the calls of the current one are added up with the used ones
+	l.Restore(9, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC))
+	l.Restore(7, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	if got := l.Budget(); got.Used != 7 || got.Remaining != 3 {
+		t.Errorf("Budget() = %+v, want 7 used calls", got)
+	}
+
+	l.Restore(3, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	if got := l.Budget(); got.Used != 7 {
+		t.Errorf("Budget() = %+v, want the used calls kept", got)
+	}
+}
+
+func Test_slowDown(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining int64
+		limit     int64
+		want      int64
+	}{
+		{name: "plenty", remaining: 500, limit: 1000, want: 1},
+		{name: "threshold", remaining: 200, limit: 1000, want: 1},
+		{name: "running out", remaining: 100, limit: 1000, want: 2},
+		{name: "almost exhausted", remaining: 1, limit: 1000, want: maxSlowDown},
+		{name: "exhausted", remaining: 0, limit: 1000, want: maxSlowDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slowDown(tt.remaining, tt.limit); got != tt.want {
+				t.Errorf("slowDown() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiter_WaitCanceled(t *testing.T) {
+	l := New(1, 10)
+
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, wantErr %v", err, context.DeadlineExceeded)
+	}
+	// the call given up is not taken from the budget
+	if got := l.Budget().Used; got != 1 {
+		t.Errorf("Budget() used = %d, want 1", got)
+	}
+}
@@ -0,0 +1,287 @@
+package sinks
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+)
+
+const (
+	defaultQueueSize     = 1000
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	defaultAttempts      = 3
+	defaultBackoff       = 500 * time.Millisecond
+)
+
+// Store of the data sinks
+type Store interface {
+	Sinks(ctx context.Context) (sinks []*domain.Sink, err error)
+	AddSink(ctx context.Context, s *domain.Sink) (id int64, err error)
+	UpdateSink(ctx context.Context, s *domain.Sink) (result sql.Result, err error)
+	RemoveSink(ctx context.Context, id int64) (result sql.Result, err error)
+}
+
+// policy of the sink endpoints, the data is posted when the batch is full or the flush interval is over, the failed
+// batch is retried with the backoff doubled with every attempt
+type policy struct {
+	queueSize     int
+	batchSize     int
+	flushInterval time.Duration
+	attempts      int
+	backoff       time.Duration
+}
+
+// Dispatcher fans the collected data received from its data pipe out to the sinks, every sink has its own queue and
+// posts its batches in the background, so the slow sink does not hold up the others
+type Dispatcher struct {
+	l      *log.Logger
+	store  Store
+	client *http.Client
+	policy policy
+
+	endpoints map[int64]*endpoint
+	mu        sync.RWMutex
+
+	pipe chan *domain.Data
+	done chan struct{}
+}
+
+func New(l *log.Logger, store Store, client *http.Client) *Dispatcher {
+	d := &Dispatcher{
+		l:      l,
+		store:  store,
+		client: client,
+		policy: policy{
+			queueSize:     defaultQueueSize,
+			batchSize:     defaultBatchSize,
+			flushInterval: defaultFlushInterval,
+			attempts:      defaultAttempts,
+			backoff:       defaultBackoff,
+		},
+		endpoints: make(map[int64]*endpoint),
+		pipe:      make(chan *domain.Data, 1000),
+		done:      make(chan struct{}),
+	}
+
+	go d.serve()
+
+	return d
+}
+
+func (d *Dispatcher) DataPipe() chan *domain.Data {
+	return d.pipe
+}
+
+// Close stop receiving the data and wait for the queued data to be posted until ctx is done, the data pipe must not
+// be used afterward
+func (d *Dispatcher) Close(ctx context.Context) error {
+	close(d.pipe)
+	<-d.done
+
+	d.mu.Lock()
+	endpoints := slices.Collect(maps.Values(d.endpoints))
+	for _, e := range endpoints {
+		e.stop()
+	}
+	d.endpoints = make(map[int64]*endpoint)
+	d.mu.Unlock()
+
+	var left int
+
+	for _, e := range endpoints {
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			left += len(e.queue)
+			e.cancel()
+		}
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("%d rows are not posted to the sinks: %w", left, ctx.Err())
+	}
+
+	return nil
+}
+
+// Load the sinks from the store and start posting the data to them
+func (d *Dispatcher) Load(ctx context.Context) error {
+	sinks, err := d.store.Sinks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load sinks: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, s := range sinks {
+		d.endpoints[s.Id] = newEndpoint(d.l, d.client, d.policy, s)
+	}
+
+	return nil
+}
+
+// Sinks return all sinks ordered by id
+func (d *Dispatcher) Sinks() []*domain.Sink {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	sinks := make([]*domain.Sink, 0, len(d.endpoints))
+	for _, id := range slices.Sorted(maps.Keys(d.endpoints)) {
+		s := *d.endpoints[id].sink
+		sinks = append(sinks, &s)
+	}
+
+	return sinks
+}
+
+// Add the sink, the id is assigned by the store, the secret is required, so the requests to the sink are always signed
+func (d *Dispatcher) Add(ctx context.Context, s *domain.Sink) (*domain.Sink, error) {
+	s = normalize(s)
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	if s.Secret == "" {
+		return nil, fmt.Errorf("%w: secret is required", domain.ErrInvalidSink)
+	}
+
+	id, err := d.store.AddSink(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add sink: %w", err)
+	}
+
+	s.Id = id
+
+	d.mu.Lock()
+	d.endpoints[id] = newEndpoint(d.l, d.client, d.policy, s)
+	d.mu.Unlock()
+
+	res := *s
+
+	return &res, nil
+}
+
+// Update the sink, the data queued before is posted with the previous settings, the secret is kept when it is empty
+func (d *Dispatcher) Update(ctx context.Context, s *domain.Sink) (*domain.Sink, error) {
+	s = normalize(s)
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	if s.Secret == "" {
+		secret, err := d.secret(ctx, s.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Secret = secret
+	}
+
+	result, err := d.store.UpdateSink(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update sink: %w", err)
+	}
+
+	if n, errRows := result.RowsAffected(); errRows == nil && n == 0 {
+		return nil, fmt.Errorf("%w: %d", domain.ErrSinkNotFound, s.Id)
+	}
+
+	d.mu.Lock()
+	if e, found := d.endpoints[s.Id]; found {
+		e.stop()
+	}
+	d.endpoints[s.Id] = newEndpoint(d.l, d.client, d.policy, s)
+	d.mu.Unlock()
+
+	res := *s
+
+	return &res, nil
+}
+
+// Remove the sink, the data queued for it is dropped
+func (d *Dispatcher) Remove(ctx context.Context, id int64) error {
+	result, err := d.store.RemoveSink(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to remove sink: %w", err)
+	}
+
+	if n, errRows := result.RowsAffected(); errRows == nil && n == 0 {
+		return fmt.Errorf("%w: %d", domain.ErrSinkNotFound, id)
+	}
+
+	d.mu.Lock()
+	if e, found := d.endpoints[id]; found {
+		e.stop()
+		e.cancel()
+		delete(d.endpoints, id)
+	}
+	d.mu.Unlock()
+
+	return nil
+}
+
+// secret of the stored sink, the sinks are reloaded from the store when the sink is added by the other instance
+func (d *Dispatcher) secret(ctx context.Context, id int64) (string, error) {
+	d.mu.RLock()
+	e, found := d.endpoints[id]
+	d.mu.RUnlock()
+
+	if found {
+		return e.sink.Secret, nil
+	}
+
+	sinks, err := d.store.Sinks(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load sinks: %w", err)
+	}
+
+	for _, s := range sinks {
+		if s.Id == id {
+			return s.Secret, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %d", domain.ErrSinkNotFound, id)
+}
+
+func (d *Dispatcher) serve() {
+	defer close(d.done)
+
+	for data := range d.pipe {
+		p := domain.Pair{From: strings.ToUpper(data.FromSymbol), To: strings.ToUpper(data.ToSymbol)}
+
+		d.mu.RLock()
+		for _, e := range d.endpoints {
+			if e.match(p) {
+				e.push(data)
+			}
+		}
+		d.mu.RUnlock()
+	}
+}
+
+func normalize(s *domain.Sink) *domain.Sink {
+	res := *s
+	res.Pairs = make([]domain.Pair, 0, len(s.Pairs))
+
+	for _, p := range s.Pairs {
+		res.Pairs = append(res.Pairs, domain.Pair{From: strings.ToUpper(p.From), To: strings.ToUpper(p.To)})
+	}
+
+	if len(res.Pairs) == 0 {
+		res.Pairs = nil
+	}
+
+	return &res
+}
@@ -0,0 +1,302 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/streamdp/ccd/db/memory"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/alerts"
+)
+
+var testPolicy = policy{
+	queueSize:     10,
+	batchSize:     10,
+	flushInterval: 10 * time.Millisecond,
+	attempts:      3,
+	backoff:       time.Millisecond,
+}
+
+func newTestDispatcher(t *testing.T) *Dispatcher {
+	t.Helper()
+
+	store, err := memory.Connect("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+
+	d := New(log.New(io.Discard, "", 0), store, http.DefaultClient)
+	d.policy = testPolicy
+
+	return d
+}
+
+// newTestSink return the server sending the received rows to the channel
+func newTestSink(t *testing.T, secret string) (*httptest.Server, chan *domain.Data) {
+	t.Helper()
+
+	rows := make(chan *domain.Data, 10)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if secret != "" && r.Header.Get(alerts.SignatureHeader) != alerts.Sign([]byte(secret), body) {
+			t.Errorf("%s = %q, want %q", alerts.SignatureHeader, r.Header.Get(alerts.SignatureHeader),
+				alerts.Sign([]byte(secret), body))
+		}
+
+		var batch []*domain.Data
+		if err = json.Unmarshal(body, &batch); err != nil {
+			t.Error(err)
+		}
+
+		for _, data := range batch {
+			rows <- data
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, rows
+}
+
+func receive(t *testing.T, rows chan *domain.Data, n int) []*domain.Data {
+	t.Helper()
+
+	var got []*domain.Data
+
+	for range n {
+		select {
+		case data := <-rows:
+			got = append(got, data)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d rows, want %d", len(got), n)
+		}
+	}
+
+	return got
+}
+
+func TestDispatcher_serve(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDispatcher(t)
+
+	btc, btcRows := newTestSink(t, "secret")
+	all, allRows := newTestSink(t, "other secret")
+
+	if _, err := d.Add(ctx, &domain.Sink{
+		Url: btc.URL, Pairs: []domain.Pair{{From: "btc", To: "usd"}}, Secret: "secret",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.Add(ctx, &domain.Sink{Url: all.URL, Secret: "other secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []*domain.Data{
+		{FromSymbol: "BTC", ToSymbol: "USD", Price: 100000},
+		{FromSymbol: "ETH", ToSymbol: "USD", Price: 4000},
+	}
+	for _, row := range data {
+		d.DataPipe() <- row
+	}
+
+	if got := receive(t, allRows, 2); !reflect.DeepEqual(got, data) {
+		t.Errorf("all pairs sink got = %v, want %v", got, data)
+	}
+
+	if got := receive(t, btcRows, 1); !reflect.DeepEqual(got, data[:1]) {
+		t.Errorf("BTC/USD sink got = %v, want %v", got, data[:1])
+	}
+
+	if err := d.Close(ctx); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	if len(btcRows) != 0 {
+		t.Errorf("BTC/USD sink got the rows of the other pairs")
+	}
+}
+
+func TestDispatcher_slowSink(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDispatcher(t)
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(release) })
+
+	fast, fastRows := newTestSink(t, "secret")
+
+	d.policy.queueSize = 1
+	d.policy.batchSize = 1
+
+	slowSink, err := d.Add(ctx, &domain.Sink{Url: slow.URL, Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.policy = testPolicy
+
+	if _, err = d.Add(ctx, &domain.Sink{Url: fast.URL, Secret: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	dropped := sinkDropped.WithLabelValues("1")
+	before := testutil.ToFloat64(dropped)
+
+	for range 5 {
+		d.DataPipe() <- &domain.Data{FromSymbol: "BTC", ToSymbol: "USD"}
+	}
+
+	receive(t, fastRows, 5)
+
+	if got := testutil.ToFloat64(dropped) - before; got == 0 {
+		t.Errorf("sink %d dropped no rows", slowSink.Id)
+	}
+}
+
+func TestEndpoint_post(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantCalls int32
+		wantErr   bool
+	}{
+		{
+			name:      "success",
+			statuses:  []int{http.StatusOK},
+			wantCalls: 1,
+		},
+		{
+			name:      "retried server errors",
+			statuses:  []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusNoContent},
+			wantCalls: 3,
+		},
+		{
+			name:      "all attempts failed",
+			statuses:  []int{http.StatusServiceUnavailable},
+			wantCalls: 3,
+			wantErr:   true,
+		},
+		{
+			name:      "client error is not retried",
+			statuses:  []int{http.StatusBadRequest},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				n := int(calls.Add(1))
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses))-1])
+			}))
+			defer srv.Close()
+
+			e := newEndpoint(log.New(io.Discard, "", 0), srv.Client(), testPolicy, &domain.Sink{Url: srv.URL})
+			defer e.stop()
+
+			err := e.post([]*domain.Data{{FromSymbol: "BTC", ToSymbol: "USD"}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("post() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("post() calls = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDispatcher_crud(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDispatcher(t)
+	defer func() {
+		_ = d.Close(ctx)
+	}()
+
+	if _, err := d.Add(ctx, &domain.Sink{Url: "/ticks"}); !errors.Is(err, domain.ErrInvalidSink) {
+		t.Errorf("Add() error = %v, wantErr %v", err, domain.ErrInvalidSink)
+	}
+
+	if _, err := d.Add(ctx, &domain.Sink{Url: "https://example.com/ticks"}); !errors.Is(err, domain.ErrInvalidSink) {
+		t.Errorf("Add() without secret error = %v, wantErr %v", err, domain.ErrInvalidSink)
+	}
+
+	s, err := d.Add(ctx, &domain.Sink{Url: "https://example.com/ticks", Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &domain.Sink{
+		Id:     s.Id,
+		Url:    "https://example.com/ticks",
+		Pairs:  []domain.Pair{{From: "BTC", To: "USD"}},
+		Secret: "secret",
+	}
+	// the secret is kept when it is omitted
+	if s, err = d.Update(ctx, &domain.Sink{
+		Id: s.Id, Url: want.Url, Pairs: []domain.Pair{{From: "btc", To: "usd"}},
+	}); err != nil || !reflect.DeepEqual(s, want) {
+		t.Errorf("Update() = %+v, %v, want %+v", s, err, want)
+	}
+
+	if _, err = d.Update(ctx, &domain.Sink{Id: 100, Url: want.Url}); !errors.Is(err, domain.ErrSinkNotFound) {
+		t.Errorf("Update() error = %v, wantErr %v", err, domain.ErrSinkNotFound)
+	}
+
+	// the sinks are loaded from the store by the new dispatcher
+	loaded := New(log.New(io.Discard, "", 0), d.store, http.DefaultClient)
+	defer func() {
+		_ = loaded.Close(ctx)
+	}()
+
+	// the sink added with the other instance sharing the store is updated before it is loaded
+	if _, err = loaded.Update(ctx, &domain.Sink{Id: want.Id, Url: want.Url, Pairs: want.Pairs}); err != nil {
+		t.Errorf("Update() of the sink not loaded yet error = %v", err)
+	}
+
+	if err = loaded.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := loaded.Sinks(); !reflect.DeepEqual(got, []*domain.Sink{want}) {
+		t.Errorf("Sinks() = %+v, want %+v", got, []*domain.Sink{want})
+	}
+
+	if err = d.Remove(ctx, s.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = d.Remove(ctx, s.Id); !errors.Is(err, domain.ErrSinkNotFound) {
+		t.Errorf("Remove() error = %v, wantErr %v", err, domain.ErrSinkNotFound)
+	}
+
+	if got := d.Sinks(); len(got) != 0 {
+		t.Errorf("Sinks() = %+v, want empty", got)
+	}
+}
@@ -0,0 +1,189 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/alerts"
+)
+
+var errSinkStatus = errors.New("unexpected sink response status")
+
+// endpoint posts the data batches to the sink url, the data is dropped when its queue is full
+type endpoint struct {
+	l      *log.Logger
+	client *http.Client
+	policy policy
+
+	sink  *domain.Sink
+	pairs map[domain.Pair]struct{}
+	label string
+
+	queue  chan *domain.Data
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newEndpoint(l *log.Logger, client *http.Client, p policy, s *domain.Sink) *endpoint {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	e := &endpoint{
+		l:      l,
+		client: client,
+		policy: p,
+		sink:   s,
+		label:  strconv.FormatInt(s.Id, 10),
+		queue:  make(chan *domain.Data, p.queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	if len(s.Pairs) != 0 {
+		e.pairs = make(map[domain.Pair]struct{}, len(s.Pairs))
+		for _, pair := range s.Pairs {
+			e.pairs[pair] = struct{}{}
+		}
+	}
+
+	go e.run()
+
+	return e
+}
+
+// match report whether the data of the pair is posted to the sink
+func (e *endpoint) match(p domain.Pair) bool {
+	if e.pairs == nil {
+		return true
+	}
+
+	_, ok := e.pairs[p]
+
+	return ok
+}
+
+// push the data to the queue without blocking
+func (e *endpoint) push(data *domain.Data) {
+	select {
+	case e.queue <- data:
+	default:
+		sinkDropped.WithLabelValues(e.label).Inc()
+	}
+}
+
+// stop accepting the data, the queued data is still posted
+func (e *endpoint) stop() {
+	close(e.queue)
+}
+
+func (e *endpoint) run() {
+	defer close(e.done)
+	defer e.cancel()
+
+	t := time.NewTicker(e.policy.flushInterval)
+	defer t.Stop()
+
+	batch := make([]*domain.Data, 0, e.policy.batchSize)
+
+	for {
+		select {
+		case data, ok := <-e.queue:
+			if !ok {
+				e.flush(batch)
+
+				return
+			}
+
+			if batch = append(batch, data); len(batch) < e.policy.batchSize {
+				continue
+			}
+		case <-t.C:
+		}
+
+		batch = e.flush(batch)
+	}
+}
+
+// flush post the batch and return it emptied, the batch of the removed sink is dropped
+func (e *endpoint) flush(batch []*domain.Data) []*domain.Data {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	if e.ctx.Err() != nil {
+		clear(batch)
+
+		return batch[:0]
+	}
+
+	if err := e.post(batch); err != nil {
+		sinkErrors.WithLabelValues(e.label).Inc()
+		e.l.Printf("failed to post %d rows to sink %d: %v", len(batch), e.sink.Id, err)
+	}
+
+	clear(batch)
+
+	return batch[:0]
+}
+
+// post the batch, it is retried on the network errors, 429 and 5xx responses
+func (e *endpoint) post(batch []*domain.Data) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	for attempt := range e.policy.attempts {
+		if attempt > 0 {
+			select {
+			case <-e.ctx.Done():
+				return fmt.Errorf("%w: %w", err, e.ctx.Err())
+			case <-time.After(e.policy.backoff << (attempt - 1)):
+			}
+		}
+
+		var retry bool
+		if retry, err = e.send(body); err == nil || !retry {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (e *endpoint) send(body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(e.ctx, http.MethodPost, e.sink.Url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if e.sink.Secret != "" {
+		req.Header.Set(alerts.SignatureHeader, alerts.Sign([]byte(e.sink.Secret), body))
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return e.ctx.Err() == nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+
+		return retry, fmt.Errorf("%w: %s", errSinkStatus, resp.Status)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,23 @@
+package sinks
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/streamdp/ccd/pkg/metrics"
+)
+
+var (
+	// sinkDropped data by the sink, the data is dropped when the sink queue is full
+	sinkDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "sink_dropped_total",
+		Help:      "Number of the collected data rows dropped by the full sink queue.",
+	}, []string{"sink"})
+
+	// sinkErrors of the batches posted to the sink, the batch is failed when all its retries are failed
+	sinkErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "sink_errors_total",
+		Help:      "Number of the collected data batches failed to be posted to the sink.",
+	}, []string{"sink"})
+)
@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ServiceName = "ccd"
+
+	tracerName     = "github.com/streamdp/ccd"
+	traceParentKey = "traceparent"
+)
+
+// Init the global tracer provider exporting spans to the OTLP/HTTP endpoint, the no-op provider is kept when the
+// endpoint is empty, the returned function flushes and stops the exporter
+func Init(ctx context.Context, endpoint, version string) (func(context.Context) error, error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", ServiceName),
+			attribute.String("service.version", version),
+		)),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	return tp.Shutdown, nil
+}
+
+// Start the span named after the traced operation
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartLinked start the span linked to the traces of the W3C traceparents, so the batched work handed over through the
+// channel is traced with all its producers
+func StartLinked(
+	ctx context.Context, name string, traceParents []string, attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	links := make([]trace.Link, 0, len(traceParents))
+
+	for _, tp := range traceParents {
+		if sc := trace.SpanContextFromContext(WithTraceParent(context.Background(), tp)); sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...), trace.WithLinks(links...))
+}
+
+// End the span, the error is recorded and the span is marked as failed
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// TraceParent return the span of ctx in the W3C traceparent format, it is empty when the span is not recorded
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	return carrier.Get(traceParentKey)
+}
+
+// WithTraceParent return ctx continuing the trace from the W3C traceparent, so the work handed over through the
+// channel is traced as a part of its producer trace
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{traceParentKey: traceParent})
+}
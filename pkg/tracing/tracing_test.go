@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInit(t *testing.T) {
+	shutdown, err := Init(context.Background(), "", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, span := Start(context.Background(), "noop")
+	defer span.End()
+
+	if span.IsRecording() || TraceParent(ctx) != "" {
+		t.Error("Start() span is recorded, want no-op span without the endpoint")
+	}
+
+	if err = shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+}
+
+func TestWithTraceParent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctx, parent := Start(context.Background(), "puller.pull")
+	traceParent := TraceParent(ctx)
+	parent.End()
+
+	_, child := Start(WithTraceParent(context.Background(), traceParent), "db.Insert")
+	End(child, errors.New("insert failed"))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended spans = %d, want 2", len(spans))
+	}
+
+	if got, want := spans[1].Parent().SpanID(), spans[0].SpanContext().SpanID(); got != want {
+		t.Errorf("child parent span = %v, want %v", got, want)
+	}
+
+	if spans[1].SpanContext().TraceID() != spans[0].SpanContext().TraceID() {
+		t.Error("child span is not a part of the parent trace")
+	}
+
+	if spans[1].Status().Code != codes.Error {
+		t.Errorf("child status = %v, want error", spans[1].Status())
+	}
+}
+
+func TestStartLinked(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	var traceParents []string
+
+	for range 2 {
+		ctx, producer := Start(context.Background(), "puller.pull")
+		traceParents = append(traceParents, TraceParent(ctx))
+		producer.End()
+	}
+
+	_, batch := StartLinked(context.Background(), "db.InsertBatch", append(traceParents, "", "broken"))
+	batch.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("ended spans = %d, want 3", len(spans))
+	}
+
+	links := spans[2].Links()
+	if len(links) != 2 {
+		t.Fatalf("links = %d, want 2", len(links))
+	}
+
+	for i := range links {
+		if links[i].SpanContext.SpanID() != spans[i].SpanContext().SpanID() {
+			t.Errorf("link %d span = %v, want %v", i, links[i].SpanContext.SpanID(), spans[i].SpanContext().SpanID())
+		}
+	}
+}
@@ -7,15 +7,18 @@ import (
 	"io"
 	"log"
 	"maps"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/coder/websocket"
 	"github.com/streamdp/ccd/clients"
 	"github.com/streamdp/ccd/config"
 	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/metrics"
 )
 
 const (
@@ -26,6 +29,8 @@ const (
 type Ws struct {
 	l *log.Logger
 
+	provider string
+
 	httpClient *http.Client
 	conn       *websocket.Conn
 
@@ -45,6 +50,10 @@ type Ws struct {
 	up   chan struct{}
 	down chan struct{}
 
+	cancel  context.CancelFunc
+	done    chan struct{}
+	closing atomic.Bool
+
 	sessionRepo clients.SessionRepo
 }
 
@@ -55,10 +64,19 @@ var (
 	ErrNotSubscribed              = errors.New("not subscribed")
 )
 
-func New(ctx context.Context, wsUrl string, sessionRepo clients.SessionRepo, l *log.Logger, cfg *config.Http) *Ws {
+func New(
+	ctx context.Context,
+	provider string,
+	wsUrl string,
+	sessionRepo clients.SessionRepo,
+	l *log.Logger,
+	cfg *config.Http,
+) *Ws {
 	w := &Ws{
 		l: l,
 
+		provider: provider,
+
 		httpClient: &http.Client{Timeout: cfg.ClientTimeout()},
 		wsUrl:      wsUrl,
 
@@ -67,9 +85,13 @@ func New(ctx context.Context, wsUrl string, sessionRepo clients.SessionRepo, l *
 		up:   make(chan struct{}, 1),
 		down: make(chan struct{}, 1),
 
+		done: make(chan struct{}),
+
 		sessionRepo: sessionRepo,
 	}
 
+	ctx, w.cancel = context.WithCancel(ctx)
+
 	go w.serveWsConnection(ctx)
 
 	return w
@@ -96,7 +118,7 @@ func (w *Ws) Subscribe(ctx context.Context, from, to string) error {
 	w.subscriptions[ch] = domain.NewSubscription(from, to, id)
 	w.subMu.Unlock()
 
-	if err = w.sessionRepo.AddTask(ctx, buildWsSessionName(from, to), 0); err != nil {
+	if err = w.sessionRepo.AddTask(ctx, buildWsSessionName(w.provider, from, to), 0); err != nil {
 		w.l.Println("failed to add subscription to the session repo: " + err.Error())
 	}
 
@@ -135,7 +157,7 @@ func (w *Ws) Unsubscribe(ctx context.Context, from, to string) error {
 	delete(w.subscriptions, ch)
 	w.subMu.Unlock()
 
-	if err = w.sessionRepo.RemoveTask(ctx, buildWsSessionName(from, to)); err != nil {
+	if err = w.sessionRepo.RemoveTask(ctx, buildWsSessionName(w.provider, from, to)); err != nil {
 		w.l.Println("failed to remove subscription from the session repo: " + err.Error())
 	}
 
@@ -149,6 +171,10 @@ func (w *Ws) Unsubscribe(ctx context.Context, from, to string) error {
 }
 
 func (w *Ws) HandleWsError(ctx context.Context, err error) error {
+	if w.closing.Load() {
+		return fmt.Errorf("ws client is closed: %w", err)
+	}
+
 	if errors.As(err, &websocket.CloseError{}) &&
 		unwrapError[websocket.CloseError](err).Code == websocket.StatusNormalClosure ||
 		errors.Is(err, context.Canceled) {
@@ -163,6 +189,7 @@ func (w *Ws) HandleWsError(ctx context.Context, err error) error {
 			return ErrReconnect
 		default:
 			if err = w.reconnect(ctx); err != nil {
+				metrics.WsReconnects.WithLabelValues(w.provider, "error").Inc()
 				w.l.Println("ws reconnect error:", err)
 				time.Sleep(defaultReconnectTimeout)
 
@@ -170,11 +197,14 @@ func (w *Ws) HandleWsError(ctx context.Context, err error) error {
 			}
 
 			if err = w.resubscribe(ctx); err != nil {
+				metrics.WsReconnects.WithLabelValues(w.provider, "error").Inc()
 				w.l.Println("ws resubscribe error:", err)
 
 				continue
 			}
 
+			metrics.WsReconnects.WithLabelValues(w.provider, "success").Inc()
+
 			return nil
 		}
 	}
@@ -262,9 +292,18 @@ func (w *Ws) RestoreLastSession(ctx context.Context) error {
 	}
 
 	for session := range sessions {
-		if pair := strings.Split(session, ":"); len(pair) == 3 {
-			if err = w.Subscribe(ctx, pair[1], pair[2]); err != nil {
-				return fmt.Errorf("failed to restore last ws session: %w", err)
+		provider, from, to, ok := clients.ParseWsSessionName(session)
+		if !ok || provider != "" && provider != w.provider {
+			continue
+		}
+
+		if err = w.Subscribe(ctx, from, to); err != nil {
+			return fmt.Errorf("failed to restore last ws session: %w", err)
+		}
+
+		if provider == "" {
+			if err = w.sessionRepo.RemoveTask(ctx, session); err != nil {
+				w.l.Println("failed to remove legacy subscription from the session repo: " + err.Error())
 			}
 		}
 	}
@@ -272,7 +311,17 @@ func (w *Ws) RestoreLastSession(ctx context.Context) error {
 	return nil
 }
 
+// Provider return the name of the data provider the client is connected to
+func (w *Ws) Provider() string {
+	return w.provider
+}
+
 func (w *Ws) WsDown() error {
+	if w.closing.Load() {
+		// the connection is closed by the Close
+		return nil
+	}
+
 	select {
 	case w.down <- struct{}{}:
 		<-w.down
@@ -294,6 +343,43 @@ func (w *Ws) wsUp() error {
 	return nil
 }
 
+// Close unsubscribe from all channels and close the ws connection with the going away status, subscriptions are kept
+// in the session store to be restored on the next start
+func (w *Ws) Close(ctx context.Context) error {
+	if w.closing.Swap(true) {
+		return nil
+	}
+
+	var errs []error
+
+	w.subMu.RLock()
+
+	for ch, sub := range w.subscriptions {
+		msg, err := w.UnsubscribeMessageBuilder(ch, sub.Id())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to build unsubscribe message: %w", err))
+
+			continue
+		}
+
+		if err = w.sendMessage(ctx, msg); err != nil && !errors.Is(err, ErrWsConnectionNotInitialized) {
+			errs = append(errs, fmt.Errorf("failed to ws unsubscribe: %w", err))
+		}
+	}
+
+	w.subMu.RUnlock()
+
+	w.cancel()
+
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("failed to close ws connection: %w", ctx.Err()))
+	}
+
+	return errors.Join(errs...)
+}
+
 func (w *Ws) reconnect(ctx context.Context) error {
 	var (
 		err  error
@@ -352,6 +438,7 @@ func (w *Ws) sendMessage(ctx context.Context, message []byte) error {
 }
 
 func (w *Ws) serveWsConnection(ctx context.Context) {
+	defer close(w.done)
 	defer close(w.up)
 	defer close(w.down)
 
@@ -366,6 +453,16 @@ func (w *Ws) serveWsConnection(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
+			// the message handler is canceled after the close handshake, the canceled read drops the connection at once
+			w.closing.Store(true)
+
+			if w.conn != nil {
+				if err := w.conn.Close(websocket.StatusGoingAway, "client is shutting down"); err != nil &&
+					!errors.As(err, &websocket.CloseError{}) && !errors.Is(err, net.ErrClosed) {
+					w.l.Printf("failed to close websocket connection: %v", err)
+				}
+			}
+
 			cancel()
 
 			return
@@ -428,8 +525,8 @@ func (w *Ws) isConnectionBroken(ctx context.Context) bool {
 	return w.conn != nil && w.conn.Ping(ctx) != nil
 }
 
-func buildWsSessionName(from, to string) string {
-	return fmt.Sprintf("WS:%s:%s", from, to)
+func buildWsSessionName(provider, from, to string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", clients.WsSessionPrefix, strings.ToUpper(provider), from, to)
 }
 
 func unwrapError[T error](err error) T {
@@ -1,11 +1,18 @@
 package wsclient
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/coder/websocket"
+	"github.com/streamdp/ccd/config"
 	"github.com/streamdp/ccd/domain"
 )
 
@@ -94,8 +101,9 @@ func TestWs_ListSubscriptions(t *testing.T) {
 
 func Test_buildWsSessionName(t *testing.T) {
 	type args struct {
-		from string
-		to   string
+		provider string
+		from     string
+		to       string
 	}
 
 	tests := []struct {
@@ -106,23 +114,25 @@ func Test_buildWsSessionName(t *testing.T) {
 		{
 			name: "symbols in the upper case",
 			args: args{
-				from: "BTC",
-				to:   "USDT",
+				provider: "kraken",
+				from:     "BTC",
+				to:       "USDT",
 			},
-			want: "WS:BTC:USDT",
+			want: "WS:KRAKEN:BTC:USDT",
 		},
 		{
 			name: "symbols in the lower case",
 			args: args{
-				from: "eth",
-				to:   "usdt",
+				provider: "huobi",
+				from:     "eth",
+				to:       "usdt",
 			},
-			want: "WS:eth:usdt",
+			want: "WS:HUOBI:eth:usdt",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := buildWsSessionName(tt.args.from, tt.args.to); got != tt.want {
+			if got := buildWsSessionName(tt.args.provider, tt.args.from, tt.args.to); got != tt.want {
 				t.Errorf("buildWsSessionName() = %v, want %v", got, tt.want)
 			}
 		})
@@ -173,3 +183,92 @@ func Test_errUnwrapCloseError(t *testing.T) {
 		})
 	}
 }
+
+type mockSessionRepo struct {
+	removed []string
+}
+
+func (m *mockSessionRepo) AddTask(_ context.Context, _ string, _ int64) error {
+	return nil
+}
+
+func (m *mockSessionRepo) UpdateTask(_ context.Context, _ string, _ int64) error {
+	return nil
+}
+
+func (m *mockSessionRepo) RemoveTask(_ context.Context, n string) error {
+	m.removed = append(m.removed, n)
+
+	return nil
+}
+
+func (m *mockSessionRepo) GetSession(_ context.Context) (map[string]int64, error) {
+	return nil, nil
+}
+
+func (m *mockSessionRepo) Close() error {
+	return nil
+}
+
+func TestWs_Close(t *testing.T) {
+	var (
+		messages = make(chan string, 10)
+		closed   = make(chan websocket.StatusCode, 1)
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		for {
+			_, msg, errRead := conn.Read(r.Context())
+			if errRead != nil {
+				closed <- websocket.CloseStatus(errRead)
+
+				return
+			}
+
+			messages <- string(msg)
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	repo := &mockSessionRepo{}
+	w := New(ctx, "test", "ws"+srv.URL[len("http"):], repo, log.New(io.Discard, "", 0), &config.Http{})
+	w.UnsubscribeMessageBuilder = func(ch string, _ int64) ([]byte, error) {
+		return []byte("unsubscribe " + ch), nil
+	}
+
+	if err := w.reconnect(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	w.subscriptions["BTC/USD"] = domain.NewSubscription("BTC", "USD", 0)
+
+	if err := w.Close(ctx); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	if got := <-messages; got != "unsubscribe BTC/USD" {
+		t.Errorf("message = %v, want unsubscribe BTC/USD", got)
+	}
+
+	if got := <-closed; got != websocket.StatusGoingAway {
+		t.Errorf("close status = %v, want %v", got, websocket.StatusGoingAway)
+	}
+
+	if len(repo.removed) != 0 {
+		t.Errorf("removed sessions = %v, want none", repo.removed)
+	}
+
+	if len(w.ListSubscriptions()) != 1 {
+		t.Errorf("subscriptions = %v, want 1", len(w.ListSubscriptions()))
+	}
+}
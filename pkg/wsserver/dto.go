@@ -9,17 +9,23 @@ import (
 	"github.com/streamdp/ccd/domain"
 )
 
+const alertsSubscription = "alerts"
+
 type client struct {
 	handler *handler
 	cancel  context.CancelFunc
 }
 
 type wsMessage struct {
-	T         string       `json:"type"`
-	Pair      *pair        `json:"pair,omitempty"`
-	Data      *domain.Data `json:"data,omitempty"`
-	Message   string       `json:"message,omitempty"`
-	Timestamp int64        `json:"timestamp,omitempty"`
+	T         string             `json:"type"`
+	Pair      *pair              `json:"pair,omitempty"`
+	Interval  string             `json:"interval,omitempty"`
+	Data      *domain.Data       `json:"data,omitempty"`
+	Candle    *domain.Candle     `json:"candle,omitempty"`
+	Alert     *domain.AlertEvent `json:"alert,omitempty"`
+	Closed    bool               `json:"closed,omitempty"`
+	Message   string             `json:"message,omitempty"`
+	Timestamp int64              `json:"timestamp,omitempty"`
 }
 
 func (w *wsMessage) Bytes() []byte {
@@ -31,9 +37,11 @@ func (w *wsMessage) Bytes() []byte {
 	return b
 }
 
+// pair of currencies, the Provider selects the data provider of the candles
 type pair struct {
-	From string `json:"fsym"`
-	To   string `json:"tsym"`
+	Provider string `json:"provider,omitempty"`
+	From     string `json:"fsym"`
+	To       string `json:"tsym"`
 }
 
 func (p *pair) toUpper() {
@@ -44,3 +52,16 @@ func (p *pair) toUpper() {
 func (p *pair) buildName() string {
 	return fmt.Sprintf("%s:%s", p.From, p.To)
 }
+
+// buildAlertsName of the pair alerts subscription, all alerts are subscribed without the pair
+func buildAlertsName(p *pair) string {
+	if p == nil {
+		return alertsSubscription
+	}
+
+	return fmt.Sprintf("%s:%s:%s", alertsSubscription, p.From, p.To)
+}
+
+func (p *pair) buildCandleName(interval string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", p.Provider, p.From, p.To, interval)
+}
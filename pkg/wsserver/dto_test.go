@@ -76,3 +76,32 @@ func Test_pair_toUpper(t *testing.T) {
 		})
 	}
 }
+
+func Test_pair_buildCandleName(t *testing.T) {
+	tests := []struct {
+		name     string
+		p        *pair
+		interval string
+		want     string
+	}{
+		{
+			name:     "get btc/usdt minute candles name",
+			p:        &pair{Provider: "kraken", From: "BTC", To: "USDT"},
+			interval: "1m",
+			want:     "kraken:BTC:USDT:1m",
+		},
+		{
+			name:     "get eth/usdt daily candles name",
+			p:        &pair{Provider: "consolidated", From: "ETH", To: "USDT"},
+			interval: "1d",
+			want:     "consolidated:ETH:USDT:1d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.buildCandleName(tt.interval); got != tt.want {
+				t.Errorf("buildCandleName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package ws
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -26,6 +28,7 @@ const (
 const (
 	readWait                 = time.Minute
 	writeWait                = 10 * time.Second
+	priceWait                = 10 * time.Second
 	maxMessageSize           = 512
 	defaultHeartbeatInterval = time.Second
 
@@ -34,15 +37,24 @@ const (
 	messageTypeHeartbeat = "heartbeat"
 	messageTypePong      = "pong"
 	messageTypeData      = "data"
+	messageTypeCandle    = "candle"
+	messageTypeAlert     = "alert"
+
+	defaultCandleInterval = "1m"
 )
 
 type handler struct {
-	l           *log.Logger
-	conn        *websocket.Conn
+	l    *log.Logger
+	conn *websocket.Conn
+
+	// messagePipe is never closed, done is closed instead when the handler stops reading the client requests, so the
+	// messages sent by the server meanwhile are dropped
 	messagePipe chan []byte
+	done        chan struct{}
 
-	rc clients.RestClient
-	db db.Database
+	rc       clients.RestClient
+	db       db.Database
+	provider string
 
 	subscriptions *cache.Cache
 
@@ -50,7 +62,7 @@ type handler struct {
 }
 
 func (h *handler) handleClientRequests(ctx context.Context) {
-	defer close(h.messagePipe)
+	defer close(h.done)
 
 	h.sendMessage(messageTypeMessage, welcomeMessage)
 
@@ -100,29 +112,37 @@ func (h *handler) handleClientRequests(ctx context.Context) {
 					continue
 				}
 
-				h.messagePipe <- (&wsMessage{
+				h.send((&wsMessage{
 					T:         "data",
 					Data:      data,
 					Timestamp: time.Now().UTC().UnixMilli(),
-				}).Bytes()
+				}).Bytes())
 			case "subscribe":
 				h.subscribe(msg.Pair)
 			case "unsubscribe":
 				h.unsubscribe(msg.Pair)
+			case "subscribe_candles":
+				h.subscribeCandles(msg.Pair, msg.Interval)
+			case "unsubscribe_candles":
+				h.unsubscribeCandles(msg.Pair, msg.Interval)
+			case "subscribe_alerts":
+				h.subscribeAlerts(msg.Pair)
+			case "unsubscribe_alerts":
+				h.unsubscribeAlerts(msg.Pair)
 			case "close":
 				h.sendMessage(messageTypeMessage, closeMessage)
 				time.Sleep(3 * time.Second)
 
-				if err := h.close("closed at the client's request"); err != nil {
+				if err := h.close(websocket.StatusNormalClosure, "closed at the client's request"); err != nil {
 					h.l.Println(err)
 				}
 
 				return
 			case "ping":
-				h.messagePipe <- (&wsMessage{
+				h.send((&wsMessage{
 					T:         messageTypePong,
 					Timestamp: msg.Timestamp,
-				}).Bytes()
+				}).Bytes())
 			default:
 				h.sendMessage(messageTypeError, "unknown message type")
 			}
@@ -151,16 +171,48 @@ func (h *handler) handleHeartbeat(ctx context.Context) {
 }
 
 func (h *handler) handleMessagePipe(ctx context.Context) {
-	for message := range h.messagePipe {
-		ctx, cancel := context.WithTimeout(ctx, writeWait)
-		if err := h.conn.Write(ctx, websocket.MessageText, message); err != nil {
-			h.l.Println(err)
-			cancel()
-
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.done:
 			return
+		case message := <-h.messagePipe:
+			ctxWrite, cancel := context.WithTimeout(ctx, writeWait)
+			if err := h.conn.Write(ctxWrite, websocket.MessageText, message); err != nil {
+				h.l.Println(err)
+				cancel()
+
+				return
+			}
+
+			cancel()
 		}
+	}
+}
 
-		cancel()
+// send the message to the client, the message is dropped when the handler is done
+func (h *handler) send(message []byte) {
+	select {
+	case h.messagePipe <- message:
+	case <-h.done:
+	}
+}
+
+// trySend the message to the client without waiting, false is returned when the message pipe is full or the handler
+// is done
+func (h *handler) trySend(message []byte) bool {
+	select {
+	case <-h.done:
+		return false
+	default:
+	}
+
+	select {
+	case h.messagePipe <- message:
+		return true
+	default:
+		return false
 	}
 }
 
@@ -194,6 +246,99 @@ func (h *handler) unsubscribe(p *pair) {
 	)
 }
 
+func (h *handler) subscribeCandles(p *pair, interval string) {
+	if p == nil {
+		h.sendMessage(messageTypeError, "pair is required")
+
+		return
+	}
+
+	if interval == "" {
+		interval = defaultCandleInterval
+	}
+
+	if _, err := domain.ParseResolution(interval); err != nil {
+		h.sendMessage(messageTypeError, fmt.Sprintf("unsupported interval %q, use one of 1m, 5m, 1h, 1d", interval))
+
+		return
+	}
+
+	p.Provider = strings.ToLower(cmp.Or(p.Provider, h.provider))
+
+	subscription := p.buildCandleName(interval)
+	if h.subscriptions.IsPresent(subscription) {
+		h.sendMessage(messageTypeMessage, "Already subscribed")
+
+		return
+	}
+
+	h.subscriptions.Add(subscription)
+	h.sendMessage(
+		messageTypeMessage,
+		fmt.Sprintf("Successfully subscribed on %s %s/%s %s candles", p.Provider, p.From, p.To, interval),
+	)
+}
+
+func (h *handler) unsubscribeCandles(p *pair, interval string) {
+	if p == nil {
+		h.sendMessage(messageTypeError, "pair is required")
+
+		return
+	}
+
+	if interval == "" {
+		interval = defaultCandleInterval
+	}
+
+	p.Provider = strings.ToLower(cmp.Or(p.Provider, h.provider))
+
+	subscription := p.buildCandleName(interval)
+	if !h.subscriptions.IsPresent(subscription) {
+		h.sendMessage(messageTypeMessage, "Not subscribed")
+
+		return
+	}
+
+	h.subscriptions.Remove(subscription)
+	h.sendMessage(
+		messageTypeMessage,
+		fmt.Sprintf("Successfully unsubscribed from %s %s/%s %s candles", p.Provider, p.From, p.To, interval),
+	)
+}
+
+// subscribeAlerts of the pair or of all pairs when the pair is not given
+func (h *handler) subscribeAlerts(p *pair) {
+	subscription := buildAlertsName(p)
+	if h.subscriptions.IsPresent(subscription) {
+		h.sendMessage(messageTypeMessage, "Already subscribed")
+
+		return
+	}
+
+	h.subscriptions.Add(subscription)
+	h.sendMessage(messageTypeMessage, "Successfully subscribed on "+alertsDescription(p))
+}
+
+func (h *handler) unsubscribeAlerts(p *pair) {
+	subscription := buildAlertsName(p)
+	if !h.subscriptions.IsPresent(subscription) {
+		h.sendMessage(messageTypeMessage, "Not subscribed")
+
+		return
+	}
+
+	h.subscriptions.Remove(subscription)
+	h.sendMessage(messageTypeMessage, "Successfully unsubscribed from "+alertsDescription(p))
+}
+
+func alertsDescription(p *pair) string {
+	if p == nil {
+		return "all alerts"
+	}
+
+	return fmt.Sprintf("%s/%s pair alerts", p.From, p.To)
+}
+
 func (h *handler) sendMessage(messageType, message string) {
 	msg := &wsMessage{
 		T:         messageType,
@@ -203,7 +348,7 @@ func (h *handler) sendMessage(messageType, message string) {
 		msg.Message = message
 	}
 
-	h.messagePipe <- msg.Bytes()
+	h.send(msg.Bytes())
 }
 
 func (h *handler) getLastPrice(ctx context.Context, p *pair) (*domain.Data, error) {
@@ -211,7 +356,11 @@ func (h *handler) getLastPrice(ctx context.Context, p *pair) (*domain.Data, erro
 		return nil, errors.New("pair is required")
 	}
 
-	data, err := v1.LastPrice(ctx, h.rc, h.db, p.From, p.To)
+	ctx, cancel := context.WithTimeout(ctx, priceWait)
+	defer cancel()
+
+	// the price is got from the failover chain, so the stored data of any data provider is returned when it fails
+	data, err := v1.LastPrice(ctx, h.rc, h.db, "", p.From, p.To)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get last price: %w", err)
 	}
@@ -219,8 +368,8 @@ func (h *handler) getLastPrice(ctx context.Context, p *pair) (*domain.Data, erro
 	return data, nil
 }
 
-func (h *handler) close(reason string) error {
-	if err := h.conn.Close(websocket.StatusNormalClosure, reason); err != nil {
+func (h *handler) close(code websocket.StatusCode, reason string) error {
+	if err := h.conn.Close(code, reason); err != nil {
 		return fmt.Errorf("failed to close ws connection: %w", err)
 	}
 
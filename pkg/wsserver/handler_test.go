@@ -3,6 +3,7 @@ package ws
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"reflect"
 	"slices"
@@ -170,6 +171,77 @@ func Test_handler_unsubscribe(t *testing.T) {
 	}
 }
 
+func Test_handler_subscribeCandles(t *testing.T) {
+	tests := []struct {
+		name     string
+		p        *pair
+		interval string
+		want     []string
+		wantType string
+	}{
+		{
+			name:     "subscribe with default interval",
+			p:        &pair{From: "BTC", To: "USDT"},
+			interval: "",
+			want:     []string{"kraken:BTC:USDT:1m"},
+			wantType: messageTypeMessage,
+		},
+		{
+			name:     "subscribe hourly candles of the provider",
+			p:        &pair{Provider: "Binance", From: "BTC", To: "USDT"},
+			interval: "1h",
+			want:     []string{"binance:BTC:USDT:1h"},
+			wantType: messageTypeMessage,
+		},
+		{
+			name:     "unsupported interval",
+			p:        &pair{From: "BTC", To: "USDT"},
+			interval: "2h",
+			want:     []string{},
+			wantType: messageTypeError,
+		},
+		{
+			name:     "pair is missing",
+			p:        nil,
+			interval: "1m",
+			want:     []string{},
+			wantType: messageTypeError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &handler{
+				messagePipe:   make(chan []byte, 10),
+				subscriptions: cache.New(),
+				provider:      "kraken",
+			}
+
+			t.Cleanup(func() { close(h.messagePipe) })
+
+			h.subscribeCandles(tt.p, tt.interval)
+
+			if got := h.subscriptions.Len(); got != len(tt.want) {
+				t.Errorf("len(subscriptions) = %v, want = %v", got, len(tt.want))
+			}
+
+			for i := range tt.want {
+				if !h.subscriptions.IsPresent(tt.want[i]) {
+					t.Errorf("subscription not found: %v", tt.want[i])
+				}
+			}
+
+			msg := &wsMessage{}
+			if err := json.Unmarshal(<-h.messagePipe, msg); err != nil {
+				t.Fatalf("failed to unmarshal message: %v", err)
+			}
+
+			if msg.T != tt.wantType {
+				t.Errorf("message type = %v, want = %v", msg.T, tt.wantType)
+			}
+		})
+	}
+}
+
 func Test_handler_getLastPrice(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -236,8 +308,9 @@ func Test_handler_getLastPrice(t *testing.T) {
 			})
 
 			h := &handler{
-				rc: tt.rc,
-				db: tt.db,
+				rc:       tt.rc,
+				db:       tt.db,
+				provider: "kraken",
 			}
 
 			got, err := h.getLastPrice(context.Background(), tt.p)
@@ -259,7 +332,7 @@ type mockRestClient struct {
 	err  error
 }
 
-func (m *mockRestClient) Get(_ string, _ string) (*domain.Data, error) {
+func (m *mockRestClient) Get(_ context.Context, _ string, _ string) (*domain.Data, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -267,6 +340,14 @@ func (m *mockRestClient) Get(_ string, _ string) (*domain.Data, error) {
 	return m.data, nil
 }
 
+func (m *mockRestClient) GetMany(_ context.Context, _ []domain.Pair) ([]*domain.Data, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return []*domain.Data{m.data}, nil
+}
+
 func (m *mockRestClient) Close() error {
 	return nil
 }
@@ -277,18 +358,33 @@ type mockDatabase struct {
 	err      error
 }
 
+var errWrongProvider = errors.New("wrong provider")
+
 func (m *mockDatabase) Insert(_ context.Context, _ *domain.Data) (sql.Result, error) {
 	return nil, m.err
 }
 
-func (m *mockDatabase) GetLast(_ context.Context, _ string, _ string) (*domain.Data, error) {
+func (m *mockDatabase) GetLast(_ context.Context, provider, _, _ string) (*domain.Data, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
 
+	// the price of the failover chain falls back to the stored data of any provider
+	if provider != "" {
+		return nil, errWrongProvider
+	}
+
 	return m.data, nil
 }
 
+func (m *mockDatabase) GetRange(_ context.Context, _ *domain.RangeQuery) ([]*domain.Data, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return []*domain.Data{m.data}, nil
+}
+
 func (m *mockDatabase) DataPipe() chan *domain.Data {
 	return m.dataPipe
 }
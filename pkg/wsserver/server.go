@@ -7,6 +7,7 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 	"unsafe"
@@ -16,9 +17,13 @@ import (
 	"github.com/streamdp/ccd/db"
 	"github.com/streamdp/ccd/domain"
 	"github.com/streamdp/ccd/pkg/cache"
+	"github.com/streamdp/ccd/pkg/candles"
 )
 
-const gcInterval = 30 * time.Second
+const (
+	gcInterval          = 30 * time.Second
+	candleCloseInterval = time.Second
+)
 
 type Server struct {
 	l *log.Logger
@@ -28,13 +33,17 @@ type Server struct {
 
 	restClient clients.RestClient
 	dataBase   db.Database
+	provider   string
 
-	pipe chan *domain.Data
+	pipe    chan *domain.Data
+	candles *candles.Builder
 
 	cancel context.CancelFunc
 }
 
-func NewServer(ctx context.Context, l *log.Logger, r clients.RestClient, db db.Database) *Server {
+// NewServer of the ws clients, the candles are of the given data provider unless the client asks for another one, the
+// stored prices of any data provider back the failover chain
+func NewServer(ctx context.Context, l *log.Logger, r clients.RestClient, db db.Database, provider string) *Server {
 	ctx, cancel := context.WithCancel(ctx)
 
 	server := &Server{
@@ -43,13 +52,16 @@ func NewServer(ctx context.Context, l *log.Logger, r clients.RestClient, db db.D
 		clientsMu:  new(sync.RWMutex),
 		restClient: r,
 		dataBase:   db,
+		provider:   provider,
 
-		pipe: make(chan *domain.Data, 1000),
+		pipe:    make(chan *domain.Data, 1000),
+		candles: candles.NewBuilder(),
 
 		cancel: cancel,
 	}
 
 	go server.gc(ctx)
+	go server.closeCandles(ctx)
 	go server.processSubscriptions()
 
 	return server
@@ -65,8 +77,10 @@ func (s *Server) AddClient(ctx context.Context, w http.ResponseWriter, r *http.R
 		l:             s.l,
 		conn:          conn,
 		messagePipe:   make(chan []byte, 256),
+		done:          make(chan struct{}),
 		rc:            s.restClient,
 		db:            s.dataBase,
+		provider:      s.provider,
 		subscriptions: cache.New(),
 	}
 	h.conn.SetReadLimit(maxMessageSize)
@@ -95,25 +109,77 @@ func (s *Server) DataPipe() chan *domain.Data {
 	return s.pipe
 }
 
+// Close the connections of all clients with the going away status and stop serving, the data pipe must not be used
+// afterward
 func (s *Server) Close() {
 	defer close(s.pipe)
 	defer s.cancel()
 
-	s.clientsMu.RLock()
+	var wg sync.WaitGroup
+
+	s.clientsMu.Lock()
 
 	for c := range s.clients {
-		c.cancel()
+		wg.Go(func() {
+			// the handlers are canceled after the close handshake, the canceled read drops the connection at once
+			defer c.cancel()
+
+			if err := c.handler.close(websocket.StatusGoingAway, "server is shutting down"); err != nil &&
+				!errors.As(err, &websocket.CloseError{}) &&
+				!errors.Is(err, net.ErrClosed) {
+				s.l.Println("failed to close ws client: " + err.Error())
+			}
+		})
+
+		delete(s.clients, c)
 	}
 
-	s.clientsMu.RUnlock()
+	s.clientsMu.Unlock()
+
+	wg.Wait()
+}
+
+// Notify the clients subscribed on the alerts of the pair or on all alerts about the fired alert, the webhook url of
+// the alert is not shown to them
+func (s *Server) Notify(e *domain.AlertEvent) {
+	if s.ClientsCount() == 0 {
+		return
+	}
+
+	subscribers := s.getSubscribers(alertsSubscription)
+	for _, c := range s.getSubscribers(buildAlertsName(&pair{From: e.Alert.From, To: e.Alert.To})) {
+		if !slices.Contains(subscribers, c) {
+			subscribers = append(subscribers, c)
+		}
+	}
+
+	if len(subscribers) == 0 {
+		return
+	}
+
+	a := *e.Alert
+	a.WebhookUrl = ""
+
+	event := *e
+	event.Alert = &a
+
+	s.send(subscribers, (&wsMessage{
+		T:         messageTypeAlert,
+		Alert:     &event,
+		Timestamp: time.Now().UTC().UnixMilli(),
+	}).Bytes())
 }
 
 func (s *Server) processSubscriptions() {
 	for data := range s.pipe {
-		if len(s.clients) == 0 {
+		bars := s.candles.Add(data)
+
+		if s.ClientsCount() == 0 {
 			continue
 		}
 
+		s.sendCandles(bars)
+
 		subscribers := s.getSubscribers((&pair{
 			From: data.FromSymbol,
 			To:   data.ToSymbol,
@@ -129,14 +195,57 @@ func (s *Server) processSubscriptions() {
 			Timestamp: time.Now().UTC().UnixMilli(),
 		}).Bytes()
 
-		for _, c := range subscribers {
-			select {
-			case c.handler.messagePipe <- bytes:
-			default:
-				s.l.Printf("ws client message pipe full, dropping message")
+		s.send(subscribers, bytes)
+	}
+}
 
-				time.Sleep(time.Second)
+func (s *Server) closeCandles(ctx context.Context) {
+	t := time.NewTimer(candleCloseInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			t.Reset(candleCloseInterval)
+
+			bars := s.candles.CloseExpired(time.Now().UTC().UnixMilli())
+			if s.ClientsCount() == 0 {
+				continue
 			}
+
+			s.sendCandles(bars)
+		}
+	}
+}
+
+func (s *Server) sendCandles(bars []candles.Bar) {
+	for i := range bars {
+		p := &pair{Provider: bars[i].Provider, From: bars[i].From, To: bars[i].To}
+
+		subscribers := s.getSubscribers(p.buildCandleName(string(bars[i].Resolution)))
+		if len(subscribers) == 0 {
+			continue
+		}
+
+		s.send(subscribers, (&wsMessage{
+			T:         messageTypeCandle,
+			Pair:      p,
+			Interval:  string(bars[i].Resolution),
+			Candle:    &bars[i].Candle,
+			Closed:    bars[i].Closed,
+			Timestamp: time.Now().UTC().UnixMilli(),
+		}).Bytes())
+	}
+}
+
+// send the message to the subscribers without waiting, the message is dropped for the clients with the full message
+// pipe, so the slow client does not hold up the others, and for the clients gone meanwhile
+func (s *Server) send(subscribers []*client, bytes []byte) {
+	for _, c := range subscribers {
+		if !c.handler.trySend(bytes) {
+			s.l.Printf("ws client message pipe full or closed, dropping message")
 		}
 	}
 }
@@ -168,14 +277,14 @@ func (s *Server) gc(ctx context.Context) {
 		case <-t.C:
 			t.Reset(gcInterval)
 
-			if len(s.clients) == 0 {
+			if s.ClientsCount() == 0 {
 				continue
 			}
 
 			for _, c := range s.getInactiveClients() {
 				c.cancel()
 
-				if err := c.handler.close("inactive client"); err != nil &&
+				if err := c.handler.close(websocket.StatusNormalClosure, "inactive client"); err != nil &&
 					!errors.As(err, &websocket.CloseError{}) &&
 					!errors.Is(err, net.ErrClosed) {
 					s.l.Println("failed to close inactive client: " + err.Error())
@@ -191,6 +300,29 @@ func (s *Server) gc(ctx context.Context) {
 	}
 }
 
+// ClientsCount return the number of connected ws clients
+func (s *Server) ClientsCount() int {
+	s.clientsMu.RLock()
+	defer s.clientsMu.RUnlock()
+
+	return len(s.clients)
+}
+
+// SubscriptionsCount return the number of subscriptions of all connected ws clients
+func (s *Server) SubscriptionsCount() int {
+	var n int
+
+	s.clientsMu.RLock()
+
+	for c := range s.clients {
+		n += c.handler.subscriptions.Len()
+	}
+
+	s.clientsMu.RUnlock()
+
+	return n
+}
+
 func (s *Server) getInactiveClients() []*client {
 	var res []*client
 
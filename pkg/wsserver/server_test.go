@@ -3,14 +3,20 @@ package ws
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/coder/websocket"
 	"github.com/streamdp/ccd/domain"
 	"github.com/streamdp/ccd/pkg/cache"
+	"github.com/streamdp/ccd/pkg/candles"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -248,6 +254,7 @@ func TestServer_processSubscriptions(t *testing.T) {
 				clients:   tt.clients,
 				clientsMu: new(sync.RWMutex),
 				pipe:      make(chan *domain.Data, 10),
+				candles:   candles.NewBuilder(),
 			}
 
 			t.Cleanup(func() { close(s.pipe) })
@@ -307,3 +314,123 @@ func atomicTrue() *atomic.Bool {
 
 	return &v
 }
+
+func TestServer_Close(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer(ctx, log.New(io.Discard, "", 0), &mockRestClient{}, &mockDatabase{}, "kraken")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := s.AddClient(ctx, w, r); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+srv.URL[len("http"):], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+
+	// welcome message
+	if _, _, err = conn.Read(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	go s.Close()
+
+	for err == nil {
+		_, _, err = conn.Read(ctx)
+	}
+
+	assert.Equal(t, websocket.StatusGoingAway, websocket.CloseStatus(err))
+	assert.Equal(t, 0, s.ClientsCount())
+}
+
+func TestServer_Notify(t *testing.T) {
+	newClient := func(subscriptions ...string) *client {
+		c := cache.New()
+		for _, s := range subscriptions {
+			c.Add(s)
+		}
+
+		return &client{
+			handler: &handler{
+				subscriptions: c,
+				messagePipe:   make(chan []byte, 10),
+				isActive:      *atomicTrue(),
+			},
+		}
+	}
+
+	var (
+		all      = newClient(alertsSubscription)
+		single   = newClient(buildAlertsName(&pair{From: "BTC", To: "USD"}))
+		both     = newClient(alertsSubscription, buildAlertsName(&pair{From: "BTC", To: "USD"}))
+		other    = newClient(buildAlertsName(&pair{From: "ETH", To: "USD"}))
+		prices   = newClient((&pair{From: "BTC", To: "USD"}).buildName())
+		inactive = &client{handler: &handler{subscriptions: cache.New(), messagePipe: make(chan []byte, 10)}}
+		full     = newClient(alertsSubscription)
+		gone     = newClient(alertsSubscription)
+	)
+
+	// the client with the full message pipe must not hold up the others
+	full.handler.messagePipe = make(chan []byte)
+
+	// the handler of the client gone meanwhile is done before it is found inactive
+	gone.handler.done = make(chan struct{})
+	close(gone.handler.done)
+
+	s := &Server{
+		l: log.New(io.Discard, "", 0),
+		clients: map[*client]struct{}{
+			all: {}, single: {}, both: {}, other: {}, prices: {}, inactive: {}, full: {}, gone: {},
+		},
+		clientsMu: new(sync.RWMutex),
+	}
+
+	event := &domain.AlertEvent{
+		Alert: &domain.Alert{
+			Id: 1, From: "BTC", To: "USD", Rule: domain.AlertPriceAbove, Threshold: 100,
+			WebhookUrl: "https://example.com/hook",
+		},
+		Value: 101,
+	}
+
+	start := time.Now()
+	s.Notify(event)
+
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Notify() blocked for %v", elapsed)
+	}
+
+	if event.Alert.WebhookUrl == "" {
+		t.Errorf("Notify() changed the alert of the event")
+	}
+
+	want := &domain.AlertEvent{
+		Alert: &domain.Alert{Id: 1, From: "BTC", To: "USD", Rule: domain.AlertPriceAbove, Threshold: 100},
+		Value: 101,
+	}
+
+	for c, n := range map[*client]int{all: 1, single: 1, both: 1, other: 0, prices: 0, inactive: 0, gone: 0} {
+		if got := len(c.handler.messagePipe); got != n {
+			t.Errorf("Notify() sent %d messages, want %d", got, n)
+
+			continue
+		}
+
+		if n == 0 {
+			continue
+		}
+
+		wsMsg := wsMessage{}
+		if err := json.Unmarshal(<-c.handler.messagePipe, &wsMsg); err != nil {
+			t.Errorf("failed to unmarshal message: %v", err)
+		}
+
+		if wsMsg.T != messageTypeAlert || !reflect.DeepEqual(wsMsg.Alert, want) {
+			t.Errorf("wrong alert message: got = %+v, want = %+v", wsMsg.Alert, want)
+		}
+	}
+}
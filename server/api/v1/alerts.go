@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/server/handlers"
+)
+
+// defaultAlertCoolDown is used when the cool-down of the alert is not given, in seconds
+const defaultAlertCoolDown = 300
+
+type AlertsRepo interface {
+	Alerts() []*domain.Alert
+	Add(ctx context.Context, a *domain.Alert) (*domain.Alert, error)
+	Update(ctx context.Context, a *domain.Alert) (*domain.Alert, error)
+	Remove(ctx context.Context, id int64) error
+}
+
+// AlertQuery structure for easily json serialization/validation/binding GET and POST query data, Window and CoolDown
+// are in seconds
+type AlertQuery struct {
+	Id         int64   `binding:"min=0"                                                   form:"id"          json:"id"`
+	From       string  `binding:"required,symbols"                                        form:"fsym"        json:"fsym"`
+	To         string  `binding:"required,symbols"                                        form:"tsym"        json:"tsym"`
+	Rule       string  `binding:"oneof=price_above price_below percent_move volume_spike" form:"rule"        json:"rule"`
+	Threshold  float64 `binding:"gt=0"                                                    form:"threshold"   json:"threshold"`
+	Window     int64   `binding:"min=0"                                                   form:"window"      json:"window"`
+	CoolDown   *int64  `binding:"omitempty,min=0"                                         form:"cooldown"    json:"cooldown"`
+	WebhookUrl string  `binding:"omitempty,url"                                           form:"webhook_url" json:"webhook_url"`
+}
+
+// AlertIdQuery structure for easily json serialization/validation/binding GET and POST query data
+type AlertIdQuery struct {
+	Id int64 `binding:"required,min=1" form:"id" json:"id"`
+}
+
+func (q *AlertQuery) alert() *domain.Alert {
+	coolDown := int64(defaultAlertCoolDown)
+	if q.CoolDown != nil {
+		coolDown = *q.CoolDown
+	}
+
+	return &domain.Alert{
+		Id:         q.Id,
+		From:       strings.ToUpper(q.From),
+		To:         strings.ToUpper(q.To),
+		Rule:       domain.AlertRule(q.Rule),
+		Threshold:  q.Threshold,
+		Window:     q.Window,
+		CoolDown:   coolDown,
+		WebhookUrl: q.WebhookUrl,
+	}
+}
+
+// AllAlerts return all alert rules
+func AllAlerts(a AlertsRepo) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		return domain.NewResult(http.StatusOK, "list of all alerts", a.Alerts()), nil
+	}
+}
+
+// AddAlert rule watching the collected data of the currencies pair
+func AddAlert(a AlertsRepo) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		q := AlertQuery{}
+		if err := c.Bind(&q); err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+		}
+
+		alert, err := a.Add(c.Request.Context(), q.alert())
+		if err != nil {
+			return &domain.Result{}, fmt.Errorf("failed to add alert: %w", err)
+		}
+
+		return domain.NewResult(http.StatusCreated, "alert successfully added", alert), nil
+	}
+}
+
+// UpdateAlert rule with the given id
+func UpdateAlert(a AlertsRepo) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		q := AlertQuery{}
+		if err := c.Bind(&q); err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+		}
+
+		if q.Id == 0 {
+			return &domain.Result{}, fmt.Errorf("%w: id is required", handlers.ErrBindQuery)
+		}
+
+		alert, err := a.Update(c.Request.Context(), q.alert())
+		if err != nil {
+			return &domain.Result{}, fmt.Errorf("failed to update alert: %w", err)
+		}
+
+		return domain.NewResult(http.StatusOK, "alert successfully updated", alert), nil
+	}
+}
+
+// RemoveAlert rule with the given id
+func RemoveAlert(a AlertsRepo) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		q := AlertIdQuery{}
+		if err := c.Bind(&q); err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+		}
+
+		if err := a.Remove(c.Request.Context(), q.Id); err != nil {
+			return &domain.Result{}, fmt.Errorf("failed to remove alert: %w", err)
+		}
+
+		return domain.NewResult(http.StatusOK, fmt.Sprintf("alert %d successfully removed", q.Id), nil), nil
+	}
+}
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/server/handlers"
+)
+
+type ApiKeysRepo interface {
+	ApiKeys() []*domain.ApiKey
+	Create(ctx context.Context, k *domain.ApiKey) (*domain.ApiKey, string, error)
+	Rotate(ctx context.Context, id int64) (*domain.ApiKey, string, error)
+	Revoke(ctx context.Context, id int64) error
+}
+
+// ApiKeyQuery structure for easily json serialization/validation/binding POST query data
+type ApiKeyQuery struct {
+	Name   string   `binding:"required" form:"name"   json:"name"`
+	Scopes []string `binding:"required" form:"scopes" json:"scopes"`
+}
+
+// ApiKeyIdQuery structure for easily json serialization/validation/binding POST and DELETE query data
+type ApiKeyIdQuery struct {
+	Id int64 `binding:"required,min=1" form:"id" json:"id"`
+}
+
+// issuedApiKey is the api key together with the key itself, it is shown once
+type issuedApiKey struct {
+	*domain.ApiKey
+	Key string `json:"key"`
+}
+
+// AllApiKeys return all api keys, the keys themselves are not shown
+func AllApiKeys(k ApiKeysRepo) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		return domain.NewResult(http.StatusOK, "list of all api keys", k.ApiKeys()), nil
+	}
+}
+
+// AddApiKey with the given name and scopes, the key is shown once in the response
+func AddApiKey(k ApiKeysRepo) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		q := ApiKeyQuery{}
+		if err := c.Bind(&q); err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+		}
+
+		key, secret, err := k.Create(c.Request.Context(), &domain.ApiKey{Name: q.Name, Scopes: q.Scopes})
+		if err != nil {
+			return &domain.Result{}, fmt.Errorf("failed to add api key: %w", err)
+		}
+
+		return domain.NewResult(http.StatusCreated, "api key successfully added, it is not shown again",
+			&issuedApiKey{ApiKey: key, Key: secret},
+		), nil
+	}
+}
+
+// RotateApiKey with the given id, the new key is shown once in the response
+func RotateApiKey(k ApiKeysRepo) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		q := ApiKeyIdQuery{}
+		if err := c.Bind(&q); err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+		}
+
+		key, secret, err := k.Rotate(c.Request.Context(), q.Id)
+		if err != nil {
+			return &domain.Result{}, fmt.Errorf("failed to rotate api key: %w", err)
+		}
+
+		return domain.NewResult(http.StatusOK, "api key successfully rotated, it is not shown again",
+			&issuedApiKey{ApiKey: key, Key: secret},
+		), nil
+	}
+}
+
+// RevokeApiKey with the given id
+func RevokeApiKey(k ApiKeysRepo) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		q := ApiKeyIdQuery{}
+		if err := c.Bind(&q); err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+		}
+
+		if err := k.Revoke(c.Request.Context(), q.Id); err != nil {
+			return &domain.Result{}, fmt.Errorf("failed to revoke api key: %w", err)
+		}
+
+		return domain.NewResult(http.StatusOK, fmt.Sprintf("api key %d successfully revoked", q.Id), nil), nil
+	}
+}
@@ -0,0 +1,140 @@
+package v1
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamdp/ccd/db"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/candles"
+	"github.com/streamdp/ccd/server/handlers"
+)
+
+// maxFallbackRows limits the number of rows loaded to aggregate candles when the database can't do it itself
+const maxFallbackRows = 100_000
+
+// CandlesStore is implemented by databases able to aggregate candles on their side
+type CandlesStore interface {
+	Candles(ctx context.Context, q *domain.CandleQuery) (result []*domain.Candle, err error)
+}
+
+// CandlesQuery structure for easily json serialization/validation/binding GET query data
+type CandlesQuery struct {
+	FromSymbol string `binding:"required,symbols"  form:"fsym"                  json:"fsym"`
+	ToSymbol   string `binding:"required,symbols"  form:"tsym"                  json:"tsym"`
+	Provider   string `form:"provider"             json:"provider"`
+	Resolution string `binding:"oneof=1m 5m 1h 1d" form:"resolution,default=1m" json:"resolution"`
+	From       int64  `binding:"min=0"             form:"from"                  json:"from"`
+	To         int64  `binding:"min=0"             form:"to"                    json:"to"`
+	Limit      int    `binding:"min=1,max=1000"    form:"limit,default=500"     json:"limit"`
+}
+
+func (q *CandlesQuery) toUpper() {
+	q.FromSymbol = strings.ToUpper(q.FromSymbol)
+	q.ToSymbol = strings.ToUpper(q.ToSymbol)
+}
+
+// Candles return open/high/low/close/volume bars of the data provider for the selected currencies pair, resolution
+// and time range, the bars of the default data provider are returned when the provider is not set
+func Candles(d db.Database, defaultProvider string) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		q := CandlesQuery{}
+		if err := c.Bind(&q); err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+		}
+
+		q.toUpper()
+
+		r, err := domain.ParseResolution(q.Resolution)
+		if err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+		}
+
+		res, err := GetCandles(c.Request.Context(), d, &domain.CandleQuery{
+			Provider:   strings.ToLower(cmp.Or(q.Provider, defaultProvider)),
+			From:       q.FromSymbol,
+			To:         q.ToSymbol,
+			Resolution: r,
+			Start:      q.From,
+			End:        q.To,
+			Limit:      q.Limit,
+		})
+		if err != nil {
+			return &domain.Result{}, fmt.Errorf("failed to get candles: %w", err)
+		}
+
+		return domain.NewResult(
+			http.StatusOK,
+			fmt.Sprintf("%s candles of the %s/%s pair", r, q.FromSymbol, q.ToSymbol),
+			res,
+		), nil
+	}
+}
+
+// GetCandles aggregate candles on the database side when it is supported, otherwise load stored rows page by page and
+// aggregate them in memory, the range holding more than maxFallbackRows rows is refused rather than cut
+func GetCandles(ctx context.Context, d db.Database, q *domain.CandleQuery) ([]*domain.Candle, error) {
+	if cs, ok := d.(CandlesStore); ok {
+		res, err := cs.Candles(ctx, q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate candles: %w", err)
+		}
+
+		return res, nil
+	}
+
+	var (
+		rows    []*domain.Data
+		cursor  *domain.Cursor
+		buckets int
+		bucket  int64 = -1
+	)
+
+	for {
+		page, err := d.GetRange(ctx, &domain.RangeQuery{
+			Provider: q.Provider,
+			From:     q.From,
+			To:       q.To,
+			Start:    q.Start,
+			End:      q.End,
+			Limit:    maxHistoryPage,
+			Cursor:   cursor,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get range: %w", err)
+		}
+
+		h := domain.NewHistory(page, maxHistoryPage)
+		rows = append(rows, h.Data...)
+
+		for _, row := range h.Data {
+			if b := q.Resolution.Bucket(row.LastUpdate); b != bucket {
+				buckets, bucket = buckets+1, b
+			}
+		}
+
+		// the rows are ordered, so the candles within the limit are complete once the next bar has started
+		if h.NextCursor == "" || buckets > q.Limit {
+			break
+		}
+
+		if len(rows) >= maxFallbackRows {
+			return nil, fmt.Errorf("%w: more than %d rows", domain.ErrRangeTooLarge, maxFallbackRows)
+		}
+
+		if cursor, err = domain.ParseCursor(h.NextCursor); err != nil {
+			return nil, fmt.Errorf("failed to parse cursor: %w", err)
+		}
+	}
+
+	res := candles.Aggregate(rows, q.Resolution)
+	if len(res) > q.Limit {
+		res = res[:q.Limit]
+	}
+
+	return res, nil
+}
@@ -7,32 +7,55 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/streamdp/ccd/clients"
 	"github.com/streamdp/ccd/domain"
 	"github.com/streamdp/ccd/server/handlers"
 )
 
 type Puller interface {
-	Task(from string, to string) *clients.Task
-	AddTask(ctx context.Context, from string, to string, interval int64) *clients.Task
-	RemoveTask(ctx context.Context, from string, to string)
+	Task(provider string, from string, to string) *clients.Task
+	AddTask(ctx context.Context, provider string, from string, to string, interval int64) (*clients.Task, error)
+	RemoveTask(ctx context.Context, provider string, from string, to string)
 	ListTasks() clients.Tasks
 	UpdateTask(ctx context.Context, t *clients.Task, interval int64) *clients.Task
 	RestoreLastSession(ctx context.Context) error
 }
 
+type WsClients interface {
+	Subscribe(ctx context.Context, provider string, from string, to string) error
+	Unsubscribe(ctx context.Context, provider string, from string, to string) error
+	ListSubscriptions() map[string]domain.Subscriptions
+}
+
 // CollectQuery structure for easily json serialization/validation/binding GET and POST query data
 type CollectQuery struct {
+	Provider string `binding:"provider"          form:"provider" json:"provider"`
 	From     string `binding:"required,symbols" form:"fsym"     json:"fsym"`
 	To       string `binding:"required,symbols" form:"tsym"     json:"tsym"`
 	Interval int64  `form:"interval,default=60" json:"interval"`
 }
 
 func (c *CollectQuery) toUpper() {
+	c.Provider = strings.ToLower(c.Provider)
 	c.From = strings.ToUpper(c.From)
 	c.To = strings.ToUpper(c.To)
 }
 
+// WsQuery structure for easily json serialization/validation/binding GET and POST query data of the ws subscriptions,
+// only the data providers streaming over ws are accepted
+type WsQuery struct {
+	Provider string `binding:"wsprovider"        form:"provider" json:"provider"`
+	From     string `binding:"required,symbols" form:"fsym"     json:"fsym"`
+	To       string `binding:"required,symbols" form:"tsym"     json:"tsym"`
+}
+
+func (w *WsQuery) toUpper() {
+	w.Provider = strings.ToLower(w.Provider)
+	w.From = strings.ToUpper(w.From)
+	w.To = strings.ToUpper(w.To)
+}
+
 // AddWorker that will collect data for the selected currency pair to the management service
 func AddWorker(ctx context.Context, p Puller) handlers.HandlerFuncResError {
 	return func(c *gin.Context) (*domain.Result, error) {
@@ -43,17 +66,18 @@ func AddWorker(ctx context.Context, p Puller) handlers.HandlerFuncResError {
 
 		q.toUpper()
 
-		if t := p.Task(q.From, q.To); t != nil {
+		if t := p.Task(q.Provider, q.From, q.To); t != nil {
 			return domain.NewResult(
 				http.StatusOK, "Data for this pair is already being collected", t,
 			), nil
 		}
 
-		return domain.NewResult(
-			http.StatusCreated,
-			"Data collection started",
-			p.AddTask(ctx, q.From, q.To, q.Interval),
-		), nil
+		t, err := p.AddTask(ctx, q.Provider, q.From, q.To, q.Interval)
+		if err != nil {
+			return &domain.Result{}, fmt.Errorf("failed to start data collection: %w", err)
+		}
+
+		return domain.NewResult(http.StatusCreated, "Data collection started", t), nil
 	}
 }
 
@@ -67,24 +91,25 @@ func RemoveWorker(ctx context.Context, p Puller) handlers.HandlerFuncResError {
 
 		q.toUpper()
 
-		if p.Task(q.From, q.To) == nil {
+		if p.Task(q.Provider, q.From, q.To) == nil {
 			return domain.NewResult(
 				http.StatusOK, "No data is collected for this pair", nil,
 			), nil
 		}
 
-		p.RemoveTask(ctx, q.From, q.To)
+		p.RemoveTask(ctx, q.Provider, q.From, q.To)
 
 		return domain.NewResult(http.StatusOK, "Task stopped successfully", nil), nil
 	}
 }
 
-// PullingStatus return information about running pull tasks
-func PullingStatus(p Puller, w clients.WsClient) handlers.HandlerFuncResError {
+// PullingStatus return information about running pull tasks grouped by the data provider, the pull tasks of the data
+// provider limited with the monthly budget report the remaining budget
+func PullingStatus(p Puller, w WsClients) handlers.HandlerFuncResError {
 	return func(c *gin.Context) (*domain.Result, error) {
 		var (
 			tasks         clients.Tasks
-			subscriptions domain.Subscriptions
+			subscriptions map[string]domain.Subscriptions
 		)
 		if p != nil {
 			tasks = p.ListTasks()
@@ -112,7 +137,7 @@ func UpdateWorker(ctx context.Context, p Puller) handlers.HandlerFuncResError {
 
 		var t *clients.Task
 
-		if t = p.Task(q.From, q.To); t == nil {
+		if t = p.Task(q.Provider, q.From, q.To); t == nil {
 			return domain.NewResult(http.StatusOK, "No data is collected for this pair", t), nil
 		}
 
@@ -122,16 +147,16 @@ func UpdateWorker(ctx context.Context, p Puller) handlers.HandlerFuncResError {
 	}
 }
 
-func Subscribe(ctx context.Context, w clients.WsClient) handlers.HandlerFuncResError {
+func Subscribe(ctx context.Context, w WsClients) handlers.HandlerFuncResError {
 	return func(c *gin.Context) (*domain.Result, error) {
-		q := CollectQuery{}
+		q := WsQuery{}
 		if err := c.Bind(&q); err != nil {
 			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
 		}
 
 		q.toUpper()
 
-		if err := w.Subscribe(ctx, q.From, q.To); err != nil {
+		if err := w.Subscribe(ctx, q.Provider, q.From, q.To); err != nil {
 			return &domain.Result{}, fmt.Errorf("subscribe error: %w", err)
 		}
 
@@ -143,16 +168,16 @@ func Subscribe(ctx context.Context, w clients.WsClient) handlers.HandlerFuncResE
 	}
 }
 
-func Unsubscribe(ctx context.Context, w clients.WsClient) handlers.HandlerFuncResError {
+func Unsubscribe(ctx context.Context, w WsClients) handlers.HandlerFuncResError {
 	return func(c *gin.Context) (*domain.Result, error) {
-		q := CollectQuery{}
+		q := WsQuery{}
 		if err := c.Bind(&q); err != nil {
 			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
 		}
 
 		q.toUpper()
 
-		if err := w.Unsubscribe(ctx, q.From, q.To); err != nil {
+		if err := w.Unsubscribe(ctx, q.Provider, q.From, q.To); err != nil {
 			return &domain.Result{}, fmt.Errorf("unsubscribe error: %w", err)
 		}
 
@@ -164,38 +189,48 @@ func Unsubscribe(ctx context.Context, w clients.WsClient) handlers.HandlerFuncRe
 	}
 }
 
-func mergeTasks(tasks clients.Tasks, subscriptions domain.Subscriptions) any {
-	if len(tasks) == 0 && len(subscriptions) == 0 {
-		return nil
-	}
+func mergeTasks(tasks clients.Tasks, subscriptions map[string]domain.Subscriptions) any {
+	list := map[string]map[string]map[string]any{}
+
+	add := func(provider, from, to string, v any) {
+		if list[provider] == nil {
+			list[provider] = make(map[string]map[string]any)
+		}
 
-	list := map[string]map[string]any{}
+		if list[provider][from] == nil {
+			list[provider][from] = make(map[string]any)
+		}
 
-	if len(tasks) != 0 {
-		for _, v := range tasks {
-			if list[v.From] != nil {
-				list[v.From][v.To] = v
+		list[provider][from][to] = v
+	}
 
-				continue
-			}
+	for _, v := range tasks {
+		add(v.Provider, v.From, v.To, v)
+	}
 
-			list[v.From] = make(map[string]any)
-			list[v.From][v.To] = v
+	for provider, s := range subscriptions {
+		for _, v := range s {
+			add(provider, v.From, v.To, v)
 		}
 	}
 
-	if len(subscriptions) != 0 {
-		for _, v := range subscriptions {
-			if list[v.From] != nil {
-				list[v.From][v.To] = v
+	if len(list) == 0 {
+		return nil
+	}
 
-				continue
-			}
+	return list
+}
 
-			list[v.From] = make(map[string]any)
-			list[v.From][v.To] = v
-		}
+// ValidateProvider - validate the field so that the value is one of the enabled data providers
+func ValidateProvider(p *clients.Providers) func(fl validator.FieldLevel) bool {
+	return func(fl validator.FieldLevel) bool {
+		return p.IsPresent(fl.Field().String())
 	}
+}
 
-	return list
+// ValidateWsProvider - validate the field so that the value is one of the enabled data providers streaming over ws
+func ValidateWsProvider(p *clients.Providers) func(fl validator.FieldLevel) bool {
+	return func(fl validator.FieldLevel) bool {
+		return p.IsWsPresent(fl.Field().String())
+	}
 }
@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamdp/ccd/db"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/server/handlers"
+)
+
+// maxHistoryPage is the largest page of stored rows returned at once, the larger limits are clamped to it
+const maxHistoryPage = 1000
+
+// HistoryQuery structure for easily json serialization/validation/binding GET query data
+type HistoryQuery struct {
+	FromSymbol string `binding:"required,symbols" form:"fsym"              json:"fsym"`
+	ToSymbol   string `binding:"required,symbols" form:"tsym"              json:"tsym"`
+	Provider   string `form:"provider"            json:"provider"`
+	From       int64  `binding:"min=0"            form:"from"              json:"from"`
+	To         int64  `binding:"min=0"            form:"to"                json:"to"`
+	Limit      int    `binding:"min=1"            form:"limit,default=100" json:"limit"`
+	Cursor     string `form:"cursor"              json:"cursor"`
+}
+
+func (h *HistoryQuery) toUpper() {
+	h.FromSymbol = strings.ToUpper(h.FromSymbol)
+	h.ToSymbol = strings.ToUpper(h.ToSymbol)
+}
+
+func (h *HistoryQuery) rangeQuery(defaultProvider string) (*domain.RangeQuery, error) {
+	cursor, err := domain.ParseCursor(h.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+	}
+
+	return &domain.RangeQuery{
+		Provider: strings.ToLower(cmp.Or(h.Provider, defaultProvider)),
+		From:     h.FromSymbol,
+		To:       h.ToSymbol,
+		Start:    h.From,
+		End:      h.To,
+		Limit:    min(h.Limit, maxHistoryPage),
+		Cursor:   cursor,
+	}, nil
+}
+
+// History return stored data of the data provider for the selected currencies pair and time range page by page, the
+// rows of the default data provider are returned when the provider is not set
+func History(d db.Database, defaultProvider string) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		q := HistoryQuery{}
+		if err := c.Bind(&q); err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+		}
+
+		q.toUpper()
+
+		rq, err := q.rangeQuery(defaultProvider)
+		if err != nil {
+			return &domain.Result{}, err
+		}
+
+		rows, err := d.GetRange(c.Request.Context(), rq)
+		if err != nil {
+			return &domain.Result{}, fmt.Errorf("failed to get history: %w", err)
+		}
+
+		return domain.NewResult(
+			http.StatusOK,
+			fmt.Sprintf("History of the %s/%s pair", q.FromSymbol, q.ToSymbol),
+			domain.NewHistory(rows, rq.Limit),
+		), nil
+	}
+}
@@ -11,7 +11,9 @@ import (
 	"github.com/streamdp/ccd/clients"
 	"github.com/streamdp/ccd/db"
 	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/tracing"
 	"github.com/streamdp/ccd/server/handlers"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // PriceQuery structure for easily json serialization/validation/binding GET and POST query data
@@ -20,6 +22,13 @@ type PriceQuery struct {
 	To   string `binding:"required,symbols" form:"tsym" json:"tsym"`
 }
 
+// ConsolidatedPriceQuery structure for easily json serialization/validation/binding GET query data
+type ConsolidatedPriceQuery struct {
+	PriceQuery
+	Method    string  `binding:"oneof=median vwap" form:"method,default=median" json:"method"`
+	Threshold float64 `binding:"min=0"             form:"threshold,default=2"   json:"threshold"`
+}
+
 func (p *PriceQuery) ToUpper() *PriceQuery {
 	p.From = strings.ToUpper(p.From)
 	p.To = strings.ToUpper(p.To)
@@ -29,23 +38,45 @@ func (p *PriceQuery) ToUpper() *PriceQuery {
 
 var ErrGetPrice = errors.New("failed to get price")
 
-// LastPrice return up-to-date data for the selected currencies pair
-func LastPrice(ctx context.Context, r clients.RestClient, db db.Database, from, to string) (*domain.Data, error) {
-	data, err := r.Get(from, to)
-	if err != nil {
-		if data, err = db.GetLast(ctx, from, to); err != nil {
+type ProvidersHealth interface {
+	Health() []clients.ProviderHealth
+}
+
+type PriceConsolidator interface {
+	Price(ctx context.Context, from, to, method string, threshold float64) (*domain.ConsolidatedPrice, error)
+}
+
+// LastPrice return up-to-date data for the selected currencies pair, the most recent stored data of the data provider
+// is returned when the rest client fails
+func LastPrice(
+	ctx context.Context,
+	r clients.RestClient,
+	db db.Database,
+	provider, from, to string,
+) (data *domain.Data, err error) {
+	ctx, span := tracing.Start(ctx, "v1.LastPrice", attribute.String("pair", from+"/"+to))
+	defer func() { tracing.End(span, err) }()
+
+	if data, err = r.Get(ctx, from, to); err != nil {
+		dbCtx, dbSpan := tracing.Start(ctx, "db.GetLast")
+		data, err = db.GetLast(dbCtx, provider, from, to)
+		tracing.End(dbSpan, err)
+
+		if err != nil {
 			return nil, ErrGetPrice
 		}
 
 		return data, nil
 	}
 
+	data.SetTrace(tracing.TraceParent(ctx))
 	db.DataPipe() <- data
 
 	return data, nil
 }
 
-// Price return up-to-date or most recent data for the selected currencies pair
+// Price return up-to-date data of the failover chain or the most recent stored data of any data provider for the
+// selected currencies pair, the chain answers with the data of any provider as well
 func Price(rc clients.RestClient, db db.Database) handlers.HandlerFuncResError {
 	return func(c *gin.Context) (*domain.Result, error) {
 		q := PriceQuery{}
@@ -56,15 +87,47 @@ func Price(rc clients.RestClient, db db.Database) handlers.HandlerFuncResError {
 
 		q.ToUpper()
 
-		p, err := LastPrice(c, rc, db, q.From, q.To)
+		p, err := LastPrice(c.Request.Context(), rc, db, "", q.From, q.To)
 		if err != nil {
 			return &domain.Result{}, fmt.Errorf("failed to get price: %w", err)
 		}
 
 		return domain.NewResult(
 			http.StatusOK,
-			fmt.Sprintf("Most recent price, updated at %d", p.LastUpdate),
+			fmt.Sprintf("Most recent price from %s, updated at %d", p.Provider, p.LastUpdate),
+			p,
+		), nil
+	}
+}
+
+// ConsolidatedPrice return the median or volume-weighted price built from all data providers with the per-provider
+// breakdown, answers deviated from the median more than threshold percent are flagged as outliers
+func ConsolidatedPrice(pc PriceConsolidator) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		q := ConsolidatedPriceQuery{}
+
+		if err := c.Bind(&q); err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+		}
+
+		q.ToUpper()
+
+		p, err := pc.Price(c.Request.Context(), q.From, q.To, q.Method, q.Threshold)
+		if err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", ErrGetPrice, err)
+		}
+
+		return domain.NewResult(
+			http.StatusOK,
+			fmt.Sprintf("Consolidated %s price, updated at %d", p.Method, p.LastUpdate),
 			p,
 		), nil
 	}
 }
+
+// PriceProviders return the health of the rest providers used to get the actual price in the failover order
+func PriceProviders(h ProvidersHealth) handlers.HandlerFuncResError {
+	return func(_ *gin.Context) (*domain.Result, error) {
+		return domain.NewResult(http.StatusOK, "Rest providers health", h.Health()), nil
+	}
+}
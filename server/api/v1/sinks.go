@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/server/handlers"
+)
+
+type SinksRepo interface {
+	Sinks() []*domain.Sink
+	Add(ctx context.Context, s *domain.Sink) (*domain.Sink, error)
+	Update(ctx context.Context, s *domain.Sink) (*domain.Sink, error)
+	Remove(ctx context.Context, id int64) error
+}
+
+// SinkQuery structure for easily json serialization/validation/binding POST and PUT query data, the data of all pairs
+// is posted when Pairs is empty
+type SinkQuery struct {
+	Id     int64         `binding:"min=0"        form:"id"     json:"id"`
+	Url    string        `binding:"required,url" form:"url"    json:"url"`
+	Pairs  []domain.Pair `binding:"-"            form:"-"      json:"pairs"`
+	Secret string        `binding:"-"            form:"secret" json:"secret"`
+}
+
+// SinkIdQuery structure for easily json serialization/validation/binding GET and POST query data
+type SinkIdQuery struct {
+	Id int64 `binding:"required,min=1" form:"id" json:"id"`
+}
+
+func (q *SinkQuery) sink() *domain.Sink {
+	return &domain.Sink{
+		Id:     q.Id,
+		Url:    q.Url,
+		Pairs:  q.Pairs,
+		Secret: q.Secret,
+	}
+}
+
+// AllSinks return all data sinks, the secrets are not shown
+func AllSinks(s SinksRepo) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		return domain.NewResult(http.StatusOK, "list of all sinks", s.Sinks()), nil
+	}
+}
+
+// AddSink the collected data is posted to
+func AddSink(s SinksRepo) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		q := SinkQuery{}
+		if err := c.Bind(&q); err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+		}
+
+		sink, err := s.Add(c.Request.Context(), q.sink())
+		if err != nil {
+			return &domain.Result{}, fmt.Errorf("failed to add sink: %w", err)
+		}
+
+		return domain.NewResult(http.StatusCreated, "sink successfully added", sink), nil
+	}
+}
+
+// UpdateSink with the given id
+func UpdateSink(s SinksRepo) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		q := SinkQuery{}
+		if err := c.Bind(&q); err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+		}
+
+		if q.Id == 0 {
+			return &domain.Result{}, fmt.Errorf("%w: id is required", handlers.ErrBindQuery)
+		}
+
+		sink, err := s.Update(c.Request.Context(), q.sink())
+		if err != nil {
+			return &domain.Result{}, fmt.Errorf("failed to update sink: %w", err)
+		}
+
+		return domain.NewResult(http.StatusOK, "sink successfully updated", sink), nil
+	}
+}
+
+// RemoveSink with the given id
+func RemoveSink(s SinksRepo) handlers.HandlerFuncResError {
+	return func(c *gin.Context) (*domain.Result, error) {
+		q := SinkIdQuery{}
+		if err := c.Bind(&q); err != nil {
+			return &domain.Result{}, fmt.Errorf("%w: %w", handlers.ErrBindQuery, err)
+		}
+
+		if err := s.Remove(c.Request.Context(), q.Id); err != nil {
+			return &domain.Result{}, fmt.Errorf("failed to remove sink: %w", err)
+		}
+
+		return domain.NewResult(http.StatusOK, fmt.Sprintf("sink %d successfully removed", q.Id), nil), nil
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamdp/ccd/domain"
+)
+
+// ApiKeyHeader the api key is sent with, the bearer token of the Authorization header is accepted as well
+const ApiKeyHeader = "X-Api-Key"
+
+type Authorizer interface {
+	Authorize(key, scope string) error
+}
+
+// RequireScope abort the request unless its api key has the scope
+func RequireScope(a Authorizer, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := a.Authorize(apiKey(c.Request), scope); err != nil {
+			code := getHttpStatus(err)
+			c.AbortWithStatusJSON(code, &domain.Result{Code: code, Message: err.Error()})
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func apiKey(r *http.Request) string {
+	if key := r.Header.Get(ApiKeyHeader); key != "" {
+		return key
+	}
+
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+
+	return ""
+}
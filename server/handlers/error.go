@@ -7,6 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/streamdp/ccd/domain"
 	"github.com/streamdp/ccd/pkg/wsclient"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HandlerFuncResError to make router handler what return Result and error
@@ -28,6 +30,10 @@ func GinHandler(h HandlerFuncResError) gin.HandlerFunc {
 		}
 
 		if err != nil {
+			span := trace.SpanFromContext(c.Request.Context())
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
 			res.Message = err.Error()
 			c.AbortWithStatusJSON(res.Code, res)
 
@@ -44,9 +50,27 @@ func getHttpStatus(err error) int {
 	}
 
 	if errors.Is(err, ErrBindQuery) ||
-		errors.Is(err, wsclient.ErrNotSubscribed) {
+		errors.Is(err, wsclient.ErrNotSubscribed) ||
+		errors.Is(err, domain.ErrInvalidAlert) ||
+		errors.Is(err, domain.ErrInvalidSink) ||
+		errors.Is(err, domain.ErrInvalidApiKey) ||
+		errors.Is(err, domain.ErrRangeTooLarge) {
 		return http.StatusBadRequest
 	}
 
+	if errors.Is(err, domain.ErrUnauthorized) {
+		return http.StatusUnauthorized
+	}
+
+	if errors.Is(err, domain.ErrForbidden) {
+		return http.StatusForbidden
+	}
+
+	if errors.Is(err, domain.ErrAlertNotFound) ||
+		errors.Is(err, domain.ErrSinkNotFound) ||
+		errors.Is(err, domain.ErrApiKeyNotFound) {
+		return http.StatusNotFound
+	}
+
 	return http.StatusInternalServerError
 }
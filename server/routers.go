@@ -3,19 +3,33 @@ package server
 import (
 	"context"
 	"fmt"
+	"net/http"
 
+	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/streamdp/ccd/domain"
+	"github.com/streamdp/ccd/pkg/metrics"
+	"github.com/streamdp/ccd/pkg/tracing"
 	v1 "github.com/streamdp/ccd/server/api/v1"
 	"github.com/streamdp/ccd/server/handlers"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 // InitRouter basic work on setting up the application, declare endpoints, register our custom validation functions
 func (s *server) InitRouter(ctx context.Context) error {
+	// trace api requests, health checks and metrics scrapes are skipped
+	s.Use(otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/healthz" && r.URL.Path != "/metrics"
+	})))
+
 	// health checks
 	s.GET("/healthz", SendOK)
 	s.HEAD("/healthz", SendOK)
 
+	// prometheus metrics
+	s.GET("/metrics", gin.WrapH(metrics.Handler()))
+
 	// serve web page
 	s.LoadHTMLFiles("site/index.tmpl")
 	s.Static("/css", "site/css")
@@ -23,35 +37,44 @@ func (s *server) InitRouter(ctx context.Context) error {
 	s.GET("/", SendHTML(s.cfg.Version()))
 	s.HEAD("/", SendOK)
 
+	// the api endpoints require the api key with the scope, unless the authentication is disabled
+	var (
+		read         = s.scope(domain.ScopeRead)
+		collectWrite = s.scope(domain.ScopeCollectWrite)
+		symbolsWrite = s.scope(domain.ScopeSymbolsWrite)
+		wsConnect    = s.scope(domain.ScopeWs)
+		admin        = s.scope(domain.ScopeAdmin)
+	)
+
 	// DEPRECATED: use v2 api instead
 	apiV1 := s.Group("/v1")
 	{
-		apiV1.GET("/collect/status", handlers.GinHandler(v1.PullingStatus(s.p, s.wc)))
-		apiV1.GET("/collect/add", handlers.GinHandler(v1.AddWorker(ctx, s.p)))
-		apiV1.POST("/collect", handlers.GinHandler(v1.AddWorker(ctx, s.p)))
-		apiV1.GET("/collect/remove", handlers.GinHandler(v1.RemoveWorker(ctx, s.p)))
-		apiV1.DELETE("/collect", handlers.GinHandler(v1.RemoveWorker(ctx, s.p)))
-		apiV1.GET("/collect/update", handlers.GinHandler(v1.UpdateWorker(ctx, s.p)))
-		apiV1.PUT("/collect", handlers.GinHandler(v1.UpdateWorker(ctx, s.p)))
-
-		apiV1.GET("/symbols", handlers.GinHandler(v1.AllSymbols(s.sr)))
-		apiV1.GET("/symbols/add", handlers.GinHandler(v1.AddSymbol(s.sr)))
-		apiV1.POST("/symbols", handlers.GinHandler(v1.AddSymbol(s.sr)))
-		apiV1.GET("/symbols/update", handlers.GinHandler(v1.UpdateSymbol(s.sr)))
-		apiV1.PUT("/symbols", handlers.GinHandler(v1.UpdateSymbol(s.sr)))
-		apiV1.GET("/symbols/remove", handlers.GinHandler(v1.RemoveSymbol(s.sr)))
-		apiV1.DELETE("/symbols", handlers.GinHandler(v1.RemoveSymbol(s.sr)))
-
-		apiV1.GET("/price", handlers.GinHandler(v1.Price(s.rc, s.d)))
-		apiV1.POST("/price", handlers.GinHandler(v1.Price(s.rc, s.d)))
-
-		apiV1.GET("/ws", v1.HandleWs(ctx, s.ws))
-
-		if s.wc != nil {
-			apiV1.GET("/ws/subscribe", handlers.GinHandler(v1.Subscribe(ctx, s.wc)))
-			apiV1.POST("/ws/subscribe", handlers.GinHandler(v1.Subscribe(ctx, s.wc)))
-			apiV1.GET("/ws/unsubscribe", handlers.GinHandler(v1.Unsubscribe(ctx, s.wc)))
-			apiV1.POST("/ws/unsubscribe", handlers.GinHandler(v1.Unsubscribe(ctx, s.wc)))
+		apiV1.GET("/collect/status", read, handlers.GinHandler(v1.PullingStatus(s.p, s.providers)))
+		apiV1.GET("/collect/add", collectWrite, handlers.GinHandler(v1.AddWorker(ctx, s.p)))
+		apiV1.POST("/collect", collectWrite, handlers.GinHandler(v1.AddWorker(ctx, s.p)))
+		apiV1.GET("/collect/remove", collectWrite, handlers.GinHandler(v1.RemoveWorker(ctx, s.p)))
+		apiV1.DELETE("/collect", collectWrite, handlers.GinHandler(v1.RemoveWorker(ctx, s.p)))
+		apiV1.GET("/collect/update", collectWrite, handlers.GinHandler(v1.UpdateWorker(ctx, s.p)))
+		apiV1.PUT("/collect", collectWrite, handlers.GinHandler(v1.UpdateWorker(ctx, s.p)))
+
+		apiV1.GET("/symbols", read, handlers.GinHandler(v1.AllSymbols(s.sr)))
+		apiV1.GET("/symbols/add", symbolsWrite, handlers.GinHandler(v1.AddSymbol(s.sr)))
+		apiV1.POST("/symbols", symbolsWrite, handlers.GinHandler(v1.AddSymbol(s.sr)))
+		apiV1.GET("/symbols/update", symbolsWrite, handlers.GinHandler(v1.UpdateSymbol(s.sr)))
+		apiV1.PUT("/symbols", symbolsWrite, handlers.GinHandler(v1.UpdateSymbol(s.sr)))
+		apiV1.GET("/symbols/remove", symbolsWrite, handlers.GinHandler(v1.RemoveSymbol(s.sr)))
+		apiV1.DELETE("/symbols", symbolsWrite, handlers.GinHandler(v1.RemoveSymbol(s.sr)))
+
+		apiV1.GET("/price", read, handlers.GinHandler(v1.Price(s.failover, s.d)))
+		apiV1.POST("/price", read, handlers.GinHandler(v1.Price(s.failover, s.d)))
+
+		apiV1.GET("/ws", wsConnect, v1.HandleWs(ctx, s.ws))
+
+		{
+			apiV1.GET("/ws/subscribe", collectWrite, handlers.GinHandler(v1.Subscribe(ctx, s.providers)))
+			apiV1.POST("/ws/subscribe", collectWrite, handlers.GinHandler(v1.Subscribe(ctx, s.providers)))
+			apiV1.GET("/ws/unsubscribe", collectWrite, handlers.GinHandler(v1.Unsubscribe(ctx, s.providers)))
+			apiV1.POST("/ws/unsubscribe", collectWrite, handlers.GinHandler(v1.Unsubscribe(ctx, s.providers)))
 		}
 	}
 
@@ -59,23 +82,43 @@ func (s *server) InitRouter(ctx context.Context) error {
 	apiV2 := s.Group("/v2")
 	{
 		// collect
-		apiV2.GET("/collect", handlers.GinHandler(v1.PullingStatus(s.p, s.wc)))
-		apiV2.POST("/collect", handlers.GinHandler(v1.AddWorker(ctx, s.p)))
-		apiV2.PUT("/collect", handlers.GinHandler(v1.UpdateWorker(ctx, s.p)))
-		apiV2.DELETE("/collect", handlers.GinHandler(v1.RemoveWorker(ctx, s.p)))
+		apiV2.GET("/collect", read, handlers.GinHandler(v1.PullingStatus(s.p, s.providers)))
+		apiV2.POST("/collect", collectWrite, handlers.GinHandler(v1.AddWorker(ctx, s.p)))
+		apiV2.PUT("/collect", collectWrite, handlers.GinHandler(v1.UpdateWorker(ctx, s.p)))
+		apiV2.DELETE("/collect", collectWrite, handlers.GinHandler(v1.RemoveWorker(ctx, s.p)))
 		// symbols
-		apiV2.GET("/symbols", handlers.GinHandler(v1.AllSymbols(s.sr)))
-		apiV2.POST("/symbols", handlers.GinHandler(v1.AddSymbol(s.sr)))
-		apiV2.PUT("/symbols", handlers.GinHandler(v1.UpdateSymbol(s.sr)))
-		apiV2.DELETE("/symbols", handlers.GinHandler(v1.RemoveSymbol(s.sr)))
+		apiV2.GET("/symbols", read, handlers.GinHandler(v1.AllSymbols(s.sr)))
+		apiV2.POST("/symbols", symbolsWrite, handlers.GinHandler(v1.AddSymbol(s.sr)))
+		apiV2.PUT("/symbols", symbolsWrite, handlers.GinHandler(v1.UpdateSymbol(s.sr)))
+		apiV2.DELETE("/symbols", symbolsWrite, handlers.GinHandler(v1.RemoveSymbol(s.sr)))
 		// price
-		apiV2.GET("/price", handlers.GinHandler(v1.Price(s.rc, s.d)))
+		apiV2.GET("/price", read, handlers.GinHandler(v1.Price(s.failover, s.d)))
+		apiV2.GET("/price/consolidated", read, handlers.GinHandler(v1.ConsolidatedPrice(s.consolidated)))
+		apiV2.GET("/price/providers", read, handlers.GinHandler(v1.PriceProviders(s.failover)))
+		// history
+		apiV2.GET("/history", read, handlers.GinHandler(v1.History(s.d, s.providers.Default())))
+		apiV2.GET("/candles", read, handlers.GinHandler(v1.Candles(s.d, s.providers.Default())))
+		// alerts
+		apiV2.GET("/alerts", read, handlers.GinHandler(v1.AllAlerts(s.alerts)))
+		apiV2.POST("/alerts", admin, handlers.GinHandler(v1.AddAlert(s.alerts)))
+		apiV2.PUT("/alerts", admin, handlers.GinHandler(v1.UpdateAlert(s.alerts)))
+		apiV2.DELETE("/alerts", admin, handlers.GinHandler(v1.RemoveAlert(s.alerts)))
+		// sinks
+		apiV2.GET("/sinks", read, handlers.GinHandler(v1.AllSinks(s.sinks)))
+		apiV2.POST("/sinks", admin, handlers.GinHandler(v1.AddSink(s.sinks)))
+		apiV2.PUT("/sinks", admin, handlers.GinHandler(v1.UpdateSink(s.sinks)))
+		apiV2.DELETE("/sinks", admin, handlers.GinHandler(v1.RemoveSink(s.sinks)))
+		// api keys
+		apiV2.GET("/keys", admin, handlers.GinHandler(v1.AllApiKeys(s.keys)))
+		apiV2.POST("/keys", admin, handlers.GinHandler(v1.AddApiKey(s.keys)))
+		apiV2.POST("/keys/rotate", admin, handlers.GinHandler(v1.RotateApiKey(s.keys)))
+		apiV2.DELETE("/keys", admin, handlers.GinHandler(v1.RevokeApiKey(s.keys)))
 		// websockets
-		apiV2.GET("/ws", v1.HandleWs(ctx, s.ws))
+		apiV2.GET("/ws", wsConnect, v1.HandleWs(ctx, s.ws))
 
-		if s.wc != nil {
-			apiV2.GET("/ws/subscribe", handlers.GinHandler(v1.Subscribe(ctx, s.wc)))
-			apiV2.GET("/ws/unsubscribe", handlers.GinHandler(v1.Unsubscribe(ctx, s.wc)))
+		{
+			apiV2.GET("/ws/subscribe", collectWrite, handlers.GinHandler(v1.Subscribe(ctx, s.providers)))
+			apiV2.GET("/ws/unsubscribe", collectWrite, handlers.GinHandler(v1.Unsubscribe(ctx, s.providers)))
 		}
 	}
 
@@ -83,7 +126,27 @@ func (s *server) InitRouter(ctx context.Context) error {
 		if err := v.RegisterValidation("symbols", v1.ValidateSymbols(s.sr)); err != nil {
 			return fmt.Errorf("failed to register validator: %w", err)
 		}
+
+		if err := v.RegisterValidation("provider", v1.ValidateProvider(s.providers)); err != nil {
+			return fmt.Errorf("failed to register validator: %w", err)
+		}
+
+		if err := v.RegisterValidation("wsprovider", v1.ValidateWsProvider(s.providers)); err != nil {
+			return fmt.Errorf("failed to register validator: %w", err)
+		}
 	}
 
 	return nil
 }
+
+// scope return the middleware passing the requests sent with the api key having the scope, every request is passed
+// when the authentication is disabled
+func (s *server) scope(scope string) gin.HandlerFunc {
+	if !s.cfg.Auth() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return handlers.RequireScope(s.keys, scope)
+}
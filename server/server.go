@@ -1,9 +1,13 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/streamdp/ccd/clients"
@@ -11,16 +15,29 @@ import (
 	"github.com/streamdp/ccd/db"
 	ws "github.com/streamdp/ccd/pkg/wsserver"
 	v1 "github.com/streamdp/ccd/server/api/v1"
+	"github.com/streamdp/ccd/server/handlers"
 )
 
+// ApiKeys are managed with the api and authorize the requests
+type ApiKeys interface {
+	v1.ApiKeysRepo
+	handlers.Authorizer
+}
+
 type server struct {
 	*gin.Engine
 
-	d  db.Database
-	sr v1.SymbolsRepo
-	rc clients.RestClient
-	wc clients.WsClient
-	p  v1.Puller
+	http *http.Server
+
+	d            db.Database
+	sr           v1.SymbolsRepo
+	failover     *clients.Failover
+	consolidated *clients.Consolidated
+	providers    *clients.Providers
+	p            v1.Puller
+	alerts       v1.AlertsRepo
+	sinks        v1.SinksRepo
+	keys         ApiKeys
 
 	l   *log.Logger
 	cfg *config.App
@@ -31,38 +48,63 @@ type server struct {
 func NewServer(
 	d db.Database,
 	sr v1.SymbolsRepo,
-	rc clients.RestClient,
-	wc clients.WsClient,
+	providers *clients.Providers,
+	failover *clients.Failover,
+	consolidated *clients.Consolidated,
 	p v1.Puller,
+	alerts v1.AlertsRepo,
+	sinks v1.SinksRepo,
+	keys ApiKeys,
 	l *log.Logger,
 	cfg *config.App,
 	ws *ws.Server,
 ) *server {
-	return &server{
+	s := &server{
 		Engine: gin.Default(),
 
-		d:  d,
-		sr: sr,
-		rc: rc,
-		wc: wc,
-		p:  p,
+		d:            d,
+		sr:           sr,
+		providers:    providers,
+		failover:     failover,
+		consolidated: consolidated,
+		p:            p,
+		alerts:       alerts,
+		sinks:        sinks,
+		keys:         keys,
 
 		l:   l,
 		cfg: cfg,
 
 		ws: ws,
 	}
-}
 
-func (s *server) Run(addr string, serverTimeout time.Duration) {
-	srv := &http.Server{
-		Addr:              addr,
+	s.http = &http.Server{
+		Addr:              net.JoinHostPort("", strconv.Itoa(cfg.Http.Port())),
 		Handler:           s,
-		IdleTimeout:       serverTimeout,
-		ReadTimeout:       serverTimeout,
-		ReadHeaderTimeout: serverTimeout,
-		WriteTimeout:      serverTimeout,
+		IdleTimeout:       cfg.Http.ServerTimeout(),
+		ReadTimeout:       cfg.Http.ServerTimeout(),
+		ReadHeaderTimeout: cfg.Http.ServerTimeout(),
+		WriteTimeout:      cfg.Http.ServerTimeout(),
+	}
+
+	return s
+}
+
+// Run the http server, it blocks until the server is shut down
+func (s *server) Run() error {
+	if err := s.http.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve http: %w", err)
+	}
+
+	return nil
+}
+
+// Shutdown the http server gracefully, new connections are refused and the active requests are waited for until ctx
+// is done
+func (s *server) Shutdown(ctx context.Context) error {
+	if err := s.http.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shutdown http server: %w", err)
 	}
 
-	log.Fatalln(srv.ListenAndServe())
+	return nil
 }
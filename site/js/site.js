@@ -26,16 +26,18 @@ function get_list_with_active_nodes() {
                     return
                 }
                 let result = "<strong>Data is currently being collected for:</strong><ul>";
-                for (let fsym in data){
-                    let litag = "<li>" + fsym + " to: "
-                    for (let tsym in data[fsym]){
-                        let interval = data[fsym][tsym]["interval"]
-                        if (interval === undefined) {
-                            interval = "interval not set (wss)"
+                for (let provider in data){
+                    for (let fsym in data[provider]){
+                        let litag = "<li>" + provider + ": " + fsym + " to: "
+                        for (let tsym in data[provider][fsym]){
+                            let interval = data[provider][fsym][tsym]["interval"]
+                            if (interval === undefined) {
+                                interval = "interval not set (wss)"
+                            }
+                            litag += tsym+ ":" + interval + ", ";
                         }
-                        litag += tsym+ ":" + interval + ", ";
+                        result += litag.slice(0, -2) + "</li>";
                     }
-                    result += litag.slice(0, -2) + "</li>";
                 }
                 result += "</ul>"
                 document.getElementById("running-nodes").innerHTML = result